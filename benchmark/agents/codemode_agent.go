@@ -10,6 +10,7 @@ import (
 	"github.com/imran31415/godemode/benchmark/llm"
 	"github.com/imran31415/godemode/benchmark/scenarios"
 	"github.com/imran31415/godemode/benchmark/tools"
+	"github.com/imran31415/godemode/pkg/codegen"
 	"github.com/imran31415/godemode/pkg/compiler"
 	"github.com/imran31415/godemode/pkg/executor"
 	"github.com/imran31415/godemode/pkg/validator"
@@ -175,42 +176,13 @@ func (a *CodeModeAgent) buildSystemPrompt(task scenarios.Task, env *scenarios.Te
 	sb.WriteString(fmt.Sprintf("COMPLEXITY: %s\n", task.Complexity))
 	sb.WriteString(fmt.Sprintf("EXPECTED OPERATIONS: %d\n\n", task.ExpectedOps))
 
-	sb.WriteString("You have access to the following systems:\n\n")
-
-	// Email System API
-	sb.WriteString("1. EMAIL SYSTEM API:\n")
-	sb.WriteString("   - ReadEmail(emailID string) (*Email, error)\n")
-	sb.WriteString("   - WriteEmail(to, subject, body string) (string, error)\n")
-	sb.WriteString("   - ListEmails(folderPath string) ([]string, error)\n")
-	sb.WriteString("   - Email.ExtractErrorCode() string\n\n")
-
-	// Database API
-	sb.WriteString("2. SQLITE DATABASE API:\n")
-	sb.WriteString("   - CreateTicket(ticket *Ticket) error\n")
-	sb.WriteString("   - UpdateTicket(id string, updates map[string]interface{}) error\n")
-	sb.WriteString("   - GetTicket(id string) (*Ticket, error)\n")
-	sb.WriteString("   - QueryTickets(filters map[string]interface{}) ([]*Ticket, error)\n")
-	sb.WriteString("   - DeleteTicket(id string) error\n\n")
-
-	// Graph API
-	sb.WriteString("3. KNOWLEDGE GRAPH API:\n")
-	sb.WriteString("   - AddNode(node *Node) error\n")
-	sb.WriteString("   - AddEdge(from, to, edgeType string) error\n")
-	sb.WriteString("   - GetNode(id string) (*Node, error)\n")
-	sb.WriteString("   - FindSimilar(description string, nodeType string, topK int) ([]*Node, error)\n")
-	sb.WriteString("   - GetNeighbors(nodeID string, edgeType string) ([]*Node, error)\n\n")
-
-	// Log System API
-	sb.WriteString("4. LOG SYSTEM API:\n")
-	sb.WriteString("   - SearchLogs(pattern string, timeWindow time.Duration) ([]*LogEntry, error)\n")
-	sb.WriteString("   - ExtractErrorContext(errorCode string, contextLines int) (string, error)\n")
-	sb.WriteString("   - WriteLog(filename string, level string, message string) error\n\n")
-
-	// Config System API
-	sb.WriteString("5. CONFIG SYSTEM API:\n")
-	sb.WriteString("   - ReadConfig(filename string) (map[string]interface{}, error)\n")
-	sb.WriteString("   - WriteConfig(filename string, data map[string]interface{}) error\n")
-	sb.WriteString("   - CheckFeatureFlag(flagName string) (bool, error)\n\n")
+	// The APIs are those of the registry the generated code is executed
+	// against, so the prompt cannot drift from the tools that exist
+	sb.WriteString("You have access to the following functions, which call the email, ticket database, knowledge graph, log and config systems:\n\n")
+	sb.WriteString(codegen.RenderAPIDocs(tools.ToolDefinitions(a.registry.ListTools()), codegen.DocOptions{
+		Verbosity: codegen.VerbosityCompact,
+	}))
+	sb.WriteString("\n")
 
 	sb.WriteString("Generate a complete Go program that:\n")
 	sb.WriteString("1. Imports necessary packages\n")
@@ -289,6 +261,10 @@ func (a *CodeModeAgent) executeFromGeneratedCode(ctx context.Context, code strin
 		"WriteConfig":        "writeConfig",
 		"CheckFeatureFlag":   "checkFeatureFlag",
 	}
+	// The prompt lists the registry's tools, which may be called by name
+	for _, tool := range a.registry.ListTools() {
+		toolMap[tool.Name] = tool.Name
+	}
 
 	// Parse the code for function calls
 	// This is a simple parser - looks for function calls in main()
@@ -11,6 +11,7 @@ import (
 	"github.com/imran31415/godemode/benchmark/llm"
 	"github.com/imran31415/godemode/benchmark/scenarios"
 	"github.com/imran31415/godemode/benchmark/tools"
+	"github.com/imran31415/godemode/pkg/codegen"
 )
 
 // FunctionCallingAgent solves tasks using traditional tool calling
@@ -634,28 +635,15 @@ Which tool should be called for this step? Return JSON only.`, task.Name, task.D
 }
 
 // formatToolsList formats the tools for the LLM prompt
-func formatToolsList(tools []*tools.ToolInfo) string {
-	var sb strings.Builder
-	for _, tool := range tools {
-		sb.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name, tool.Description))
-		sb.WriteString("  Parameters: ")
-		params := []string{}
-		for _, param := range tool.Parameters {
-			required := ""
-			if param.Required {
-				required = " (required)"
-			}
-			params = append(params, fmt.Sprintf("%s: %s%s", param.Name, param.Type, required))
-		}
-		sb.WriteString(strings.Join(params, ", "))
-		sb.WriteString("\n")
-	}
-	return sb.String()
+func formatToolsList(toolsList []*tools.ToolInfo) string {
+	return codegen.RenderAPIDocs(tools.ToolDefinitions(toolsList), codegen.DocOptions{
+		Verbosity: codegen.VerbosityCompact,
+	})
 }
 
 // executeToolCall executes a tool call through the registry
 func (a *FunctionCallingAgent) executeToolCall(toolCall *ToolCall) (interface{}, error) {
-	tool, exists := a.registry.GetTool(toolCall.ToolName)
+	_, exists := a.registry.GetTool(toolCall.ToolName)
 	if !exists {
 		return nil, fmt.Errorf("tool not found: %s", toolCall.ToolName)
 	}
@@ -672,7 +660,7 @@ func (a *FunctionCallingAgent) executeToolCall(toolCall *ToolCall) (interface{},
 	}
 
 	// Execute the tool
-	result, err := tool.Function(params)
+	result, err := a.registry.Call(toolCall.ToolName, params)
 	if err != nil {
 		return nil, err
 	}
@@ -262,12 +262,11 @@ func (a *NativeToolCallingAgent) convertToolsToClaudeFormat() []llm.Tool {
 
 // executeToolByName executes a tool by name with parameters
 func (a *NativeToolCallingAgent) executeToolByName(toolName string, params map[string]interface{}) (interface{}, error) {
-	tool, exists := a.registry.GetTool(toolName)
-	if !exists {
+	if _, exists := a.registry.GetTool(toolName); !exists {
 		return nil, fmt.Errorf("tool not found: %s", toolName)
 	}
 
-	return tool.Function(params)
+	return a.registry.Call(toolName, params)
 }
 
 // continueWithToolResults continues the conversation with tool results
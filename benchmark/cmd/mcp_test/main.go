@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"time"
@@ -75,7 +76,9 @@ func main() {
 	fmt.Println("\n--- Starting MCP Agent ---\n")
 	fmt.Println("Launching MCP server...")
 
-	mcpClient, err := client.NewMCPClient("./godemode-mcp-server")
+	mcpClient, err := client.NewMCPClientWithOptions(client.Options{
+		Logger: log.New(os.Stdout, "", 0),
+	}, "./godemode-mcp-server")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create MCP client: %v\n", err)
 		os.Exit(1)
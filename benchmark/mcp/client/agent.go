@@ -194,7 +194,7 @@ func (a *MCPAgent) convertToolsToClaudeFormat() []llm.Tool {
 			properties[name] = llm.Property{
 				Type:        prop.Type,
 				Description: prop.Description,
-				Enum:        prop.Enum,
+				Enum:        enumStrings(prop.Enum),
 			}
 		}
 
@@ -212,6 +212,19 @@ func (a *MCPAgent) convertToolsToClaudeFormat() []llm.Tool {
 	return claudeTools
 }
 
+// enumStrings converts the allowed values of a schema to the strings Claude's
+// tool format takes
+func enumStrings(values []interface{}) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = fmt.Sprint(v)
+	}
+	return strs
+}
+
 // GetFunctionCalls returns all logged function calls
 func (a *MCPAgent) GetFunctionCalls() []agents.FunctionCall {
 	return a.logger.Calls
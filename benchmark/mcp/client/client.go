@@ -2,32 +2,161 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os/exec"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/imran31415/godemode/benchmark/mcp/protocol"
 )
 
-// MCPClient handles communication with an MCP server
+// ProtocolVersion is the MCP revision the clients ask for on initialize.
+// Servers may answer with an older one.
+const ProtocolVersion = "2025-06-18"
+
+// ErrClosed is returned by calls on a client that has been closed
+var ErrClosed = errors.New("MCP client is closed")
+
+// ErrServerExited is returned by calls whose server process exited before
+// answering
+var ErrServerExited = errors.New("MCP server exited")
+
+// Options configures a client
+type Options struct {
+	// Logger receives debug output and, unless Stderr is set, the server's
+	// stderr. nil discards them.
+	Logger *log.Logger
+
+	// Stderr receives the server's stderr when set
+	Stderr io.Writer
+
+	// CallTimeout bounds calls whose context has no deadline (default 2
+	// minutes). Negative disables it.
+	CallTimeout time.Duration
+
+	// AutoRestart relaunches a server process that exits before Close,
+	// re-sending initialize if the client had initialized. Calls pending when
+	// it exited fail with ErrServerExited; calls made while it restarts wait
+	// for it.
+	AutoRestart bool
+
+	// MaxRestarts bounds restarts over the client's lifetime (0 means no
+	// limit)
+	MaxRestarts int
+
+	// RestartBackoff is the delay before the first restart (default 500ms).
+	// It doubles after each failed restart, up to 30 seconds.
+	RestartBackoff time.Duration
+
+	// PingInterval, when set, pings the server this often. A server that
+	// does not answer within the interval is killed, and restarted if
+	// AutoRestart is set.
+	PingInterval time.Duration
+
+	// OnProgress receives the notifications/progress of tool calls. When it
+	// is set, each tool call asks the server for progress with its own
+	// progressToken.
+	OnProgress func(protocol.ProgressNotification)
+
+	// OnLog receives the log messages tools send with notifications/message,
+	// at the level set with SetLogLevel and above
+	OnLog func(protocol.LoggingMessageNotification)
+
+	// Callbacks run on the goroutine reading the server's messages, so they
+	// must return promptly and must not call the client.
+}
+
+// maxRestartBackoff caps the delay between restart attempts
+const maxRestartBackoff = 30 * time.Second
+
+// closeGracePeriod is how long Close waits for the server to exit after its
+// stdin is closed before killing it
+const closeGracePeriod = 5 * time.Second
+
+// process is one launch of the server
+type process struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+	started time.Time
+	done    chan struct{} // closed once the process has exited and been reaped
+	err     error         // exit status, set before done is closed
+}
+
+// MCPClient handles communication with an MCP server it launches over stdio
 type MCPClient struct {
-	cmd         *exec.Cmd
-	stdin       io.WriteCloser
-	stdout      io.ReadCloser
-	stderr      io.ReadCloser
-	reader      *bufio.Reader
-	requestID   int64
-	pendingReqs map[int64]chan *protocol.JSONRPCMessage
+	command string
+	args    []string
+	opts    Options
+	logger  *log.Logger
+
+	requestID  int64
+	progressID int64
+
 	mu          sync.Mutex
+	onNotify    NotificationHandler
+	proc        *process      // nil while the server restarts
+	changed     chan struct{} // closed and replaced when proc changes
+	pendingReqs map[int64]chan *protocol.JSONRPCMessage
 	closed      bool
+	closing     chan struct{}
+	restarts    int
+	initialized bool
+	initRequest protocol.InitializeRequest
+	initResult  protocol.InitializeResult
 }
 
 // NewMCPClient creates a new MCP client that launches a server process
 func NewMCPClient(serverCommand string, args ...string) (*MCPClient, error) {
-	cmd := exec.Command(serverCommand, args...)
+	return NewMCPClientWithOptions(Options{}, serverCommand, args...)
+}
+
+// NewMCPClientWithOptions creates a new MCP client that launches a server
+// process and supervises it as configured by opts
+func NewMCPClientWithOptions(opts Options, serverCommand string, args ...string) (*MCPClient, error) {
+	if opts.Logger == nil {
+		opts.Logger = log.New(io.Discard, "", 0)
+	}
+	if opts.CallTimeout == 0 {
+		opts.CallTimeout = 2 * time.Minute
+	}
+	if opts.RestartBackoff <= 0 {
+		opts.RestartBackoff = 500 * time.Millisecond
+	}
+
+	client := &MCPClient{
+		command:     serverCommand,
+		args:        args,
+		opts:        opts,
+		logger:      opts.Logger,
+		changed:     make(chan struct{}),
+		pendingReqs: make(map[int64]chan *protocol.JSONRPCMessage),
+		closing:     make(chan struct{}),
+	}
+
+	proc, err := client.start()
+	if err != nil {
+		return nil, err
+	}
+	client.proc = proc
+	go client.supervise(proc, opts.RestartBackoff)
+
+	if opts.PingInterval > 0 {
+		go client.healthCheck()
+	}
+
+	return client, nil
+}
+
+// start launches the server process and reads its output in the background
+func (c *MCPClient) start() (*process, error) {
+	cmd := exec.Command(c.command, c.args...)
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -44,38 +173,184 @@ func NewMCPClient(serverCommand string, args ...string) (*MCPClient, error) {
 		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	client := &MCPClient{
-		cmd:         cmd,
-		stdin:       stdin,
-		stdout:      stdout,
-		stderr:      stderr,
-		reader:      bufio.NewReader(stdout),
-		pendingReqs: make(map[int64]chan *protocol.JSONRPCMessage),
-	}
-
 	// Start server process
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start server: %w", err)
 	}
-
-	// Start reading responses in background
-	go client.readLoop()
+	proc := &process{cmd: cmd, stdin: stdin, started: time.Now(), done: make(chan struct{})}
 
 	// Forward stderr for debugging
+	stderrDone := make(chan struct{})
 	go func() {
+		defer close(stderrDone)
+		if c.opts.Stderr != nil {
+			io.Copy(c.opts.Stderr, stderr)
+			return
+		}
 		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
-			fmt.Printf("[MCP Server] %s\n", scanner.Text())
+			c.logger.Printf("[MCP Server] %s", scanner.Text())
 		}
 	}()
 
-	return client, nil
+	// Read responses until the server closes stdout, then reap it
+	go func() {
+		c.readLoop(stdout)
+		<-stderrDone
+		proc.err = cmd.Wait()
+
+		// Calls made from now on wait for the restart rather than fail
+		c.mu.Lock()
+		if c.opts.AutoRestart && !c.closed && c.proc == proc {
+			c.setProc(nil)
+		}
+		c.mu.Unlock()
+		close(proc.done)
+	}()
+
+	return proc, nil
+}
+
+// supervise waits for a server process to exit and, if the client is still
+// open and AutoRestart is set, launches and initializes a replacement
+func (c *MCPClient) supervise(proc *process, backoff time.Duration) {
+	<-proc.done
+	if proc.err != nil {
+		c.logger.Printf("[MCP Client] Server exited: %v", proc.err)
+	} else {
+		c.logger.Printf("[MCP Client] Server exited")
+	}
+
+	if !c.opts.AutoRestart {
+		return
+	}
+
+	// A server that ran for a while crashed rather than failing to start
+	if time.Since(proc.started) > maxRestartBackoff {
+		backoff = c.opts.RestartBackoff
+	}
+
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return
+		}
+		if c.opts.MaxRestarts > 0 && c.restarts >= c.opts.MaxRestarts {
+			// Leave the dead process in place so calls fail fast
+			c.setProc(proc)
+			c.mu.Unlock()
+			c.logger.Printf("[MCP Client] Giving up after %d restarts", c.opts.MaxRestarts)
+			return
+		}
+		c.restarts++
+		c.mu.Unlock()
+
+		select {
+		case <-time.After(backoff):
+		case <-c.closing:
+			return
+		}
+		backoff = min(backoff*2, maxRestartBackoff)
+
+		next, err := c.start()
+		if err == nil {
+			if err = c.reinitialize(next); err != nil {
+				next.cmd.Process.Kill()
+				<-next.done
+			}
+		}
+		if err != nil {
+			c.logger.Printf("[MCP Client] Restart failed: %v", err)
+			continue
+		}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			next.stdin.Close()
+			return
+		}
+		c.setProc(next)
+		c.mu.Unlock()
+
+		c.logger.Printf("[MCP Client] Server restarted")
+		go c.supervise(next, backoff)
+		return
+	}
+}
+
+// reinitialize repeats the client's initialize on a restarted server
+func (c *MCPClient) reinitialize(proc *process) error {
+	c.mu.Lock()
+	initialized, req := c.initialized, c.initRequest
+	c.mu.Unlock()
+	if !initialized {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), maxRestartBackoff)
+	defer cancel()
+	var result protocol.InitializeResult
+	if err := c.callOn(ctx, proc, "initialize", req, &result); err != nil {
+		return fmt.Errorf("initialize failed: %w", err)
+	}
+	if err := c.notifyOn(proc, "notifications/initialized", nil); err != nil {
+		return fmt.Errorf("initialize failed: %w", err)
+	}
+
+	c.mu.Lock()
+	c.initResult = result
+	c.mu.Unlock()
+	return nil
+}
+
+// setProc replaces the current process and wakes calls waiting for one.
+// c.mu must be held.
+func (c *MCPClient) setProc(proc *process) {
+	c.proc = proc
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// healthCheck pings the server every PingInterval, killing it when a ping
+// goes unanswered
+func (c *MCPClient) healthCheck() {
+	ticker := time.NewTicker(c.opts.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.closing:
+			return
+		}
+
+		c.mu.Lock()
+		proc := c.proc
+		c.mu.Unlock()
+		if proc == nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.opts.PingInterval)
+		err := c.callOn(ctx, proc, "ping", nil, nil)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.logger.Printf("[MCP Client] Server did not answer ping, killing it")
+			proc.cmd.Process.Kill()
+		}
+	}
 }
 
 // Initialize sends the initialize request
 func (c *MCPClient) Initialize() error {
+	return c.InitializeContext(context.Background())
+}
+
+// InitializeContext sends the initialize request, giving up when ctx is done
+func (c *MCPClient) InitializeContext(ctx context.Context) error {
 	req := protocol.InitializeRequest{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: ProtocolVersion,
 		Capabilities: protocol.ClientCapabilities{
 			Tools: &protocol.ToolsCapability{
 				Supported: true,
@@ -88,36 +363,144 @@ func (c *MCPClient) Initialize() error {
 	}
 
 	var result protocol.InitializeResult
-	if err := c.call("initialize", req, &result); err != nil {
+	if err := c.callContext(ctx, "initialize", req, &result); err != nil {
 		return fmt.Errorf("initialize failed: %w", err)
 	}
 
-	fmt.Printf("[MCP Client] Initialized with server: %s v%s\n", result.ServerInfo.Name, result.ServerInfo.Version)
+	c.mu.Lock()
+	c.initialized = true
+	c.initRequest = req
+	c.initResult = result
+	proc := c.proc
+	c.mu.Unlock()
+
+	if proc != nil {
+		if err := c.notifyOn(proc, "notifications/initialized", nil); err != nil {
+			return fmt.Errorf("initialize failed: %w", err)
+		}
+	}
+
+	c.logger.Printf("[MCP Client] Initialized with server: %s v%s", result.ServerInfo.Name, result.ServerInfo.Version)
 	return nil
 }
 
-// ListTools retrieves the list of available tools
-func (c *MCPClient) ListTools() ([]protocol.Tool, error) {
-	req := protocol.ListToolsRequest{}
+// SetNotificationHandler sets the handler for notifications the server sends,
+// in addition to the OnProgress and OnLog callbacks
+func (c *MCPClient) SetNotificationHandler(handler NotificationHandler) {
+	c.mu.Lock()
+	c.onNotify = handler
+	c.mu.Unlock()
+}
+
+// ServerInfo returns the server identity reported during initialize
+func (c *MCPClient) ServerInfo() protocol.ServerInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.initResult.ServerInfo
+}
+
+// ServerCapabilities returns the capabilities reported during initialize
+func (c *MCPClient) ServerCapabilities() protocol.ServerCapabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.initResult.Capabilities
+}
+
+// Ping checks that the server is responsive
+func (c *MCPClient) Ping(ctx context.Context) error {
+	if err := c.callContext(ctx, "ping", nil, nil); err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	return nil
+}
+
+// SetLogLevel asks the server to send log messages at level and above
+func (c *MCPClient) SetLogLevel(ctx context.Context, level string) error {
+	if err := c.callContext(ctx, "logging/setLevel", protocol.SetLevelRequest{Level: level}, nil); err != nil {
+		return fmt.Errorf("set log level failed: %w", err)
+	}
+	return nil
+}
 
-	var result protocol.ListToolsResult
-	if err := c.call("tools/list", req, &result); err != nil {
+// ListTools retrieves the list of available tools, following pagination cursors
+func (c *MCPClient) ListTools() ([]protocol.Tool, error) {
+	tools, err := listAllTools(c.call)
+	if err != nil {
 		return nil, fmt.Errorf("list tools failed: %w", err)
 	}
 
-	fmt.Printf("[MCP Client] Retrieved %d tools\n", len(result.Tools))
-	return result.Tools, nil
+	c.logger.Printf("[MCP Client] Retrieved %d tools", len(tools))
+	return tools, nil
+}
+
+// ListResources retrieves the list of available resources, following pagination cursors
+func (c *MCPClient) ListResources() ([]protocol.Resource, error) {
+	resources, err := listAllResources(c.call)
+	if err != nil {
+		return nil, fmt.Errorf("list resources failed: %w", err)
+	}
+	return resources, nil
+}
+
+// ListPrompts retrieves the list of available prompts, following pagination cursors
+func (c *MCPClient) ListPrompts() ([]protocol.Prompt, error) {
+	prompts, err := listAllPrompts(c.call)
+	if err != nil {
+		return nil, fmt.Errorf("list prompts failed: %w", err)
+	}
+	return prompts, nil
+}
+
+// ListResourceTemplates retrieves the list of resource templates, following pagination cursors
+func (c *MCPClient) ListResourceTemplates() ([]protocol.ResourceTemplate, error) {
+	templates, err := listAllResourceTemplates(c.call)
+	if err != nil {
+		return nil, fmt.Errorf("list resource templates failed: %w", err)
+	}
+	return templates, nil
+}
+
+// ReadResource reads the contents of a resource by URI
+func (c *MCPClient) ReadResource(uri string) (*protocol.ReadResourceResult, error) {
+	var result protocol.ReadResourceResult
+	if err := c.call("resources/read", protocol.ReadResourceRequest{URI: uri}, &result); err != nil {
+		return nil, fmt.Errorf("read resource failed: %w", err)
+	}
+	return &result, nil
+}
+
+// GetPrompt renders a prompt template with the given arguments
+func (c *MCPClient) GetPrompt(name string, arguments map[string]string) (*protocol.GetPromptResult, error) {
+	req := protocol.GetPromptRequest{
+		Name:      name,
+		Arguments: arguments,
+	}
+
+	var result protocol.GetPromptResult
+	if err := c.call("prompts/get", req, &result); err != nil {
+		return nil, fmt.Errorf("get prompt failed: %w", err)
+	}
+	return &result, nil
 }
 
 // CallTool executes a tool on the server
 func (c *MCPClient) CallTool(name string, arguments map[string]interface{}) (*protocol.CallToolResult, error) {
+	return c.CallToolContext(context.Background(), name, arguments)
+}
+
+// CallToolContext executes a tool on the server. When ctx is done first the
+// server is told to cancel the call.
+func (c *MCPClient) CallToolContext(ctx context.Context, name string, arguments map[string]interface{}) (*protocol.CallToolResult, error) {
 	req := protocol.CallToolRequest{
 		Name:      name,
 		Arguments: arguments,
 	}
+	if c.opts.OnProgress != nil {
+		req.Meta = &protocol.RequestMeta{ProgressToken: atomic.AddInt64(&c.progressID, 1)}
+	}
 
 	var result protocol.CallToolResult
-	if err := c.call("tools/call", req, &result); err != nil {
+	if err := c.callContext(ctx, "tools/call", req, &result); err != nil {
 		return nil, fmt.Errorf("call tool failed: %w", err)
 	}
 
@@ -126,13 +509,49 @@ func (c *MCPClient) CallTool(name string, arguments map[string]interface{}) (*pr
 
 // call sends a JSON-RPC request and waits for the response
 func (c *MCPClient) call(method string, params interface{}, result interface{}) error {
-	if c.closed {
-		return fmt.Errorf("client is closed")
+	return c.callContext(context.Background(), method, params, result)
+}
+
+// callContext sends a request to the current server process, waiting for
+// one while the server restarts. Calls without a deadline get CallTimeout.
+func (c *MCPClient) callContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if _, ok := ctx.Deadline(); !ok && c.opts.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.CallTimeout)
+		defer cancel()
+	}
+
+	for {
+		c.mu.Lock()
+		closed, proc, changed := c.closed, c.proc, c.changed
+		c.mu.Unlock()
+		if closed {
+			return ErrClosed
+		}
+		if proc != nil {
+			return c.callOn(ctx, proc, method, params, result)
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// callOn sends a JSON-RPC request to a server process and waits for the
+// response, the process to exit or ctx to be done
+func (c *MCPClient) callOn(ctx context.Context, proc *process, method string, params interface{}, result interface{}) error {
+	select {
+	case <-proc.done:
+		return fmt.Errorf("%w: %v", ErrServerExited, proc.err)
+	default:
 	}
 
 	// Generate request ID
 	id := atomic.AddInt64(&c.requestID, 1)
-	fmt.Printf("[MCP Client] Calling method: %s (ID: %d)\n", method, id)
+	c.logger.Printf("[MCP Client] Calling method: %s (ID: %d)", method, id)
 
 	// Create response channel
 	respChan := make(chan *protocol.JSONRPCMessage, 1)
@@ -154,27 +573,33 @@ func (c *MCPClient) call(method string, params interface{}, result interface{})
 		Method:  method,
 		Params:  params,
 	}
-
-	data, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	c.mu.Lock()
-	_, err = fmt.Fprintf(c.stdin, "%s\n", data)
-	c.mu.Unlock()
-	if err != nil {
+	if err := proc.send(req); err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 
-	fmt.Printf("[MCP Client] Request sent, waiting for response...\n")
 	// Wait for response
-	resp := <-respChan
-	fmt.Printf("[MCP Client] Response received for ID: %d\n", id)
+	var resp *protocol.JSONRPCMessage
+	select {
+	case resp = <-respChan:
+	case <-proc.done:
+		// A response read just before the exit still counts
+		select {
+		case resp = <-respChan:
+		default:
+			return fmt.Errorf("%w: %v", ErrServerExited, proc.err)
+		}
+	case <-ctx.Done():
+		c.notifyOn(proc, "notifications/cancelled", map[string]interface{}{
+			"requestId": id,
+			"reason":    ctx.Err().Error(),
+		})
+		return ctx.Err()
+	}
+	c.logger.Printf("[MCP Client] Response received for ID: %d", id)
 
 	// Check for error
 	if resp.Error != nil {
-		return fmt.Errorf("RPC error %d: %s", resp.Error.Code, resp.Error.Message)
+		return resp.Error
 	}
 
 	// Unmarshal result
@@ -192,73 +617,105 @@ func (c *MCPClient) call(method string, params interface{}, result interface{})
 	return nil
 }
 
-// readLoop continuously reads responses from the server
-func (c *MCPClient) readLoop() {
-	fmt.Printf("[MCP Client] ReadLoop started\n")
+// notifyOn sends a notification to a server process
+func (c *MCPClient) notifyOn(proc *process, method string, params interface{}) error {
+	return proc.send(protocol.JSONRPCMessage{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// send writes one message to the process's stdin
+func (p *process) send(msg protocol.JSONRPCMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	_, err = fmt.Fprintf(p.stdin, "%s\n", data)
+	return err
+}
+
+// readLoop reads responses from a server process until its stdout closes
+func (c *MCPClient) readLoop(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
 	for {
-		fmt.Printf("[MCP Client] ReadLoop waiting for data...\n")
-		line, err := c.reader.ReadBytes('\n')
+		line, err := reader.ReadBytes('\n')
 		if err != nil {
 			if err != io.EOF {
-				fmt.Printf("[MCP Client] Read error: %v\n", err)
+				c.logger.Printf("[MCP Client] Read error: %v", err)
 			}
-			fmt.Printf("[MCP Client] ReadLoop exiting\n")
 			return
 		}
 
-		fmt.Printf("[MCP Client] Received response: %s\n", string(line))
-
 		var msg protocol.JSONRPCMessage
 		if err := json.Unmarshal(line, &msg); err != nil {
-			fmt.Printf("[MCP Client] Failed to parse response: %v\n", err)
+			c.logger.Printf("[MCP Client] Failed to parse response: %v", err)
 			continue
 		}
 
-		// Find pending request
-		if msg.ID != nil {
-			var id int64
-			switch v := msg.ID.(type) {
-			case float64:
-				id = int64(v)
-			case int64:
-				id = v
-			case int:
-				id = int64(v)
-			default:
-				fmt.Printf("[MCP Client] Unknown ID type: %T\n", msg.ID)
-				continue
+		if msg.Method != "" {
+			if msg.ID == nil {
+				c.mu.Lock()
+				handler := c.onNotify
+				c.mu.Unlock()
+				dispatchNotification(&msg, c.opts, handler)
 			}
+			continue
+		}
 
-			fmt.Printf("[MCP Client] Looking for pending request ID: %d\n", id)
-			c.mu.Lock()
-			respChan, exists := c.pendingReqs[id]
-			fmt.Printf("[MCP Client] Pending request exists: %v\n", exists)
-			c.mu.Unlock()
+		// Find pending request
+		if msg.ID == nil {
+			continue
+		}
+		var id int64
+		switch v := msg.ID.(type) {
+		case float64:
+			id = int64(v)
+		default:
+			c.logger.Printf("[MCP Client] Unknown ID type: %T", msg.ID)
+			continue
+		}
+
+		c.mu.Lock()
+		respChan, exists := c.pendingReqs[id]
+		c.mu.Unlock()
 
-			if exists {
-				fmt.Printf("[MCP Client] Sending response to channel for ID: %d\n", id)
-				respChan <- &msg
+		if exists {
+			// Drop duplicates rather than block on a full channel
+			select {
+			case respChan <- &msg:
+			default:
 			}
-		} else {
-			fmt.Printf("[MCP Client] Response has no ID\n")
 		}
 	}
 }
 
-// Close shuts down the MCP client and server
+// Close shuts down the MCP client and server. The server is asked to exit by
+// closing its stdin and killed if it has not within a few seconds.
 func (c *MCPClient) Close() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	if c.closed {
+		c.mu.Unlock()
 		return nil
 	}
-
 	c.closed = true
+	close(c.closing)
+	proc := c.proc
+	c.setProc(nil)
+	c.mu.Unlock()
+
+	if proc == nil {
+		return nil
+	}
 
 	// Close stdin to signal server to shutdown
-	c.stdin.Close()
+	proc.stdin.Close()
 
-	// Wait for server to exit
-	return c.cmd.Wait()
+	select {
+	case <-proc.done:
+	case <-time.After(closeGracePeriod):
+		proc.cmd.Process.Kill()
+		<-proc.done
+	}
+	return proc.err
 }
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// ToolCaller is the tool-calling surface shared by MCPClient and HTTPMCPClient
+type ToolCaller interface {
+	Initialize() error
+	ListTools() ([]protocol.Tool, error)
+	CallTool(name string, arguments map[string]interface{}) (*protocol.CallToolResult, error)
+	Close() error
+}
+
+// ContextProvider reads resources and renders prompts from a server
+type ContextProvider interface {
+	ReadResource(uri string) (*protocol.ReadResourceResult, error)
+	GetPrompt(name string, arguments map[string]string) (*protocol.GetPromptResult, error)
+}
+
+// Introspector is a ToolCaller that can also describe the server's full surface
+type Introspector interface {
+	ToolCaller
+	ContextProvider
+	ServerInfo() protocol.ServerInfo
+	ServerCapabilities() protocol.ServerCapabilities
+	ListResources() ([]protocol.Resource, error)
+	ListResourceTemplates() ([]protocol.ResourceTemplate, error)
+	ListPrompts() ([]protocol.Prompt, error)
+}
+
+// Connect creates and initializes an MCP client for either a stdio server
+// command line (e.g. "npx some-server --flag") or an HTTP endpoint URL.
+// Exactly one of command and url must be set.
+func Connect(command, url string) (Introspector, error) {
+	return ConnectWithOptions(Options{}, command, url)
+}
+
+// ConnectWithOptions is Connect with client options. The returned client is
+// an *MCPClient or an *HTTPMCPClient.
+func ConnectWithOptions(opts Options, command, url string) (Introspector, error) {
+	var c Introspector
+
+	switch {
+	case command != "" && url != "":
+		return nil, fmt.Errorf("only one of command or url may be set")
+	case command != "":
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("MCP server command is empty")
+		}
+		stdioClient, err := NewMCPClientWithOptions(opts, fields[0], fields[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		c = stdioClient
+	case url != "":
+		c = NewHTTPMCPClientWithOptions(opts, url)
+	default:
+		return nil, fmt.Errorf("an MCP server command or url is required")
+	}
+
+	if err := c.Initialize(); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,11 @@
+package client
+
+import "testing"
+
+func TestConnectRejectsEmptyCommand(t *testing.T) {
+	for _, command := range []string{" ", "\t\n"} {
+		if _, err := Connect(command, ""); err == nil {
+			t.Errorf("Expected an error for the command %q", command)
+		}
+	}
+}
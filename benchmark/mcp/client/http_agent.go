@@ -194,7 +194,7 @@ func (a *HTTPMCPAgent) convertToolsToClaudeFormat() []llm.Tool {
 			properties[name] = llm.Property{
 				Type:        prop.Type,
 				Description: prop.Description,
-				Enum:        prop.Enum,
+				Enum:        enumStrings(prop.Enum),
 			}
 		}
 
@@ -2,36 +2,117 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/imran31415/godemode/benchmark/mcp/protocol"
 )
 
-// HTTPMCPClient communicates with an MCP server over HTTP
+// sessionHeader carries the session id of the Streamable HTTP transport, and
+// protocolHeader the revision negotiated for it
+const (
+	sessionHeader  = "Mcp-Session-Id"
+	protocolHeader = "Mcp-Protocol-Version"
+)
+
+// maxResumeAttempts bounds how often a broken event stream is resumed
+// before a call fails
+const maxResumeAttempts = 3
+
+// errSessionExpired is returned when the server no longer knows the session
+var errSessionExpired = errors.New("MCP session expired")
+
+// HTTPMCPClient communicates with an MCP server over the Streamable HTTP
+// transport. It keeps the session the server assigns on initialize, reads
+// responses sent as JSON or as server-sent events, resumes broken event
+// streams with Last-Event-ID and starts a new session when the server
+// expires the old one. Servers without sessions work too.
 type HTTPMCPClient struct {
 	baseURL    string
 	httpClient *http.Client
+	opts       Options
+	logger     *log.Logger
 	requestID  int64
+	progressID int64
+	initResult protocol.InitializeResult
+
+	mu           sync.Mutex
+	sessionID    string
+	version      string // negotiated on initialize
+	onNotify     NotificationHandler
+	stopListener context.CancelFunc
 }
 
 // NewHTTPMCPClient creates a new HTTP-based MCP client
 func NewHTTPMCPClient(baseURL string) *HTTPMCPClient {
+	return NewHTTPMCPClientWithOptions(Options{}, baseURL)
+}
+
+// NewHTTPMCPClientWithOptions creates a new HTTP-based MCP client. The
+// options supervising a server process do not apply.
+func NewHTTPMCPClientWithOptions(opts Options, baseURL string) *HTTPMCPClient {
+	if opts.Logger == nil {
+		opts.Logger = log.New(io.Discard, "", 0)
+	}
+	if opts.CallTimeout == 0 {
+		opts.CallTimeout = 2 * time.Minute
+	}
+
+	// Bound the wait for a response to start, but not its length, so
+	// long-running calls can stream their result
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+
 	return &HTTPMCPClient{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    baseURL,
+		httpClient: &http.Client{Transport: transport},
+		opts:       opts,
+		logger:     opts.Logger,
 	}
 }
 
-// Initialize sends the initialize request
+// SetNotificationHandler sets the handler for server-initiated messages,
+// whether they arrive while a call streams its response or on the stream
+// opened by Listen
+func (c *HTTPMCPClient) SetNotificationHandler(handler NotificationHandler) {
+	c.mu.Lock()
+	c.onNotify = handler
+	c.mu.Unlock()
+}
+
+// SessionID returns the session assigned by the server, or "" when the
+// server does not use sessions
+func (c *HTTPMCPClient) SessionID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionID
+}
+
+// Initialize sends the initialize request, starting a new session
 func (c *HTTPMCPClient) Initialize() error {
+	return c.InitializeContext(context.Background())
+}
+
+// InitializeContext sends the initialize request, starting a new session,
+// and gives up when ctx is done
+func (c *HTTPMCPClient) InitializeContext(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok && c.opts.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.CallTimeout)
+		defer cancel()
+	}
+
 	req := protocol.InitializeRequest{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: ProtocolVersion,
 		Capabilities: protocol.ClientCapabilities{
 			Tools: &protocol.ToolsCapability{
 				Supported: true,
@@ -44,44 +125,209 @@ func (c *HTTPMCPClient) Initialize() error {
 	}
 
 	var result protocol.InitializeResult
-	if err := c.call("initialize", req, &result); err != nil {
+	if err := c.roundTrip(ctx, "initialize", req, &result); err != nil {
 		return fmt.Errorf("initialize failed: %w", err)
 	}
+	c.initResult = result
+	c.mu.Lock()
+	c.version = result.ProtocolVersion
+	c.mu.Unlock()
 
-	fmt.Printf("[MCP HTTP Client] Initialized with server: %s v%s\n", result.ServerInfo.Name, result.ServerInfo.Version)
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		return fmt.Errorf("initialize failed: %w", err)
+	}
+
+	c.logger.Printf("[MCP HTTP Client] Initialized with server: %s v%s", result.ServerInfo.Name, result.ServerInfo.Version)
 	return nil
 }
 
-// ListTools retrieves the list of available tools
-func (c *HTTPMCPClient) ListTools() ([]protocol.Tool, error) {
-	req := protocol.ListToolsRequest{}
+// ServerInfo returns the server identity reported during initialize
+func (c *HTTPMCPClient) ServerInfo() protocol.ServerInfo {
+	return c.initResult.ServerInfo
+}
+
+// ServerCapabilities returns the capabilities reported during initialize
+func (c *HTTPMCPClient) ServerCapabilities() protocol.ServerCapabilities {
+	return c.initResult.Capabilities
+}
 
-	var result protocol.ListToolsResult
-	if err := c.call("tools/list", req, &result); err != nil {
+// ListTools retrieves the list of available tools, following pagination cursors
+func (c *HTTPMCPClient) ListTools() ([]protocol.Tool, error) {
+	tools, err := listAllTools(c.call)
+	if err != nil {
 		return nil, fmt.Errorf("list tools failed: %w", err)
 	}
 
-	fmt.Printf("[MCP HTTP Client] Retrieved %d tools\n", len(result.Tools))
-	return result.Tools, nil
+	c.logger.Printf("[MCP HTTP Client] Retrieved %d tools", len(tools))
+	return tools, nil
+}
+
+// ListResources retrieves the list of available resources, following pagination cursors
+func (c *HTTPMCPClient) ListResources() ([]protocol.Resource, error) {
+	resources, err := listAllResources(c.call)
+	if err != nil {
+		return nil, fmt.Errorf("list resources failed: %w", err)
+	}
+	return resources, nil
+}
+
+// ListPrompts retrieves the list of available prompts, following pagination cursors
+func (c *HTTPMCPClient) ListPrompts() ([]protocol.Prompt, error) {
+	prompts, err := listAllPrompts(c.call)
+	if err != nil {
+		return nil, fmt.Errorf("list prompts failed: %w", err)
+	}
+	return prompts, nil
+}
+
+// ListResourceTemplates retrieves the list of resource templates, following pagination cursors
+func (c *HTTPMCPClient) ListResourceTemplates() ([]protocol.ResourceTemplate, error) {
+	templates, err := listAllResourceTemplates(c.call)
+	if err != nil {
+		return nil, fmt.Errorf("list resource templates failed: %w", err)
+	}
+	return templates, nil
+}
+
+// ReadResource reads the contents of a resource by URI
+func (c *HTTPMCPClient) ReadResource(uri string) (*protocol.ReadResourceResult, error) {
+	var result protocol.ReadResourceResult
+	if err := c.call("resources/read", protocol.ReadResourceRequest{URI: uri}, &result); err != nil {
+		return nil, fmt.Errorf("read resource failed: %w", err)
+	}
+	return &result, nil
+}
+
+// GetPrompt renders a prompt template with the given arguments
+func (c *HTTPMCPClient) GetPrompt(name string, arguments map[string]string) (*protocol.GetPromptResult, error) {
+	req := protocol.GetPromptRequest{
+		Name:      name,
+		Arguments: arguments,
+	}
+
+	var result protocol.GetPromptResult
+	if err := c.call("prompts/get", req, &result); err != nil {
+		return nil, fmt.Errorf("get prompt failed: %w", err)
+	}
+	return &result, nil
+}
+
+// Ping checks that the server is responsive
+func (c *HTTPMCPClient) Ping(ctx context.Context) error {
+	if err := c.callContext(ctx, "ping", nil, nil); err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	return nil
+}
+
+// SetLogLevel asks the server to send log messages at level and above
+func (c *HTTPMCPClient) SetLogLevel(ctx context.Context, level string) error {
+	if err := c.callContext(ctx, "logging/setLevel", protocol.SetLevelRequest{Level: level}, nil); err != nil {
+		return fmt.Errorf("set log level failed: %w", err)
+	}
+	return nil
 }
 
 // CallTool executes a tool on the server
 func (c *HTTPMCPClient) CallTool(name string, arguments map[string]interface{}) (*protocol.CallToolResult, error) {
+	return c.CallToolContext(context.Background(), name, arguments)
+}
+
+// CallToolContext executes a tool on the server. When ctx is done first the
+// server is told to cancel the call.
+func (c *HTTPMCPClient) CallToolContext(ctx context.Context, name string, arguments map[string]interface{}) (*protocol.CallToolResult, error) {
 	req := protocol.CallToolRequest{
 		Name:      name,
 		Arguments: arguments,
 	}
+	if c.opts.OnProgress != nil {
+		req.Meta = &protocol.RequestMeta{ProgressToken: atomic.AddInt64(&c.progressID, 1)}
+	}
 
 	var result protocol.CallToolResult
-	if err := c.call("tools/call", req, &result); err != nil {
+	if err := c.callContext(ctx, "tools/call", req, &result); err != nil {
 		return nil, fmt.Errorf("call tool failed: %w", err)
 	}
 
 	return &result, nil
 }
 
-// call sends a JSON-RPC request over HTTP and waits for the response
+// Listen opens the server's stream of server-initiated messages and passes
+// them to the notification handler until Close, reconnecting after the last
+// event received when the stream breaks. It fails when the server offers no
+// such stream.
+func (c *HTTPMCPClient) Listen() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := c.openStream(ctx, "")
+	if err != nil {
+		cancel()
+		return fmt.Errorf("listen failed: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.stopListener != nil {
+		c.stopListener()
+	}
+	c.stopListener = cancel
+	c.mu.Unlock()
+
+	go func() {
+		failures := 0
+		for {
+			lastEventID, _ := readEvents(resp.Body, func(msg *protocol.JSONRPCMessage) bool {
+				c.dispatch(msg)
+				return false
+			})
+			resp.Body.Close()
+
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				resp, err = c.openStream(ctx, lastEventID)
+				if err == nil {
+					failures = 0
+					break
+				}
+				if failures++; failures > maxResumeAttempts {
+					c.logger.Printf("[MCP HTTP Client] Stopped listening: %v", err)
+					return
+				}
+				time.Sleep(time.Duration(failures) * time.Second)
+			}
+		}
+	}()
+	return nil
+}
+
+// call sends a request and waits for the response
 func (c *HTTPMCPClient) call(method string, params interface{}, result interface{}) error {
+	return c.callContext(context.Background(), method, params, result)
+}
+
+// callContext sends a request, starting a new session and retrying once
+// when the server has expired the current one. Calls without a deadline
+// get CallTimeout.
+func (c *HTTPMCPClient) callContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if _, ok := ctx.Deadline(); !ok && c.opts.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.CallTimeout)
+		defer cancel()
+	}
+
+	err := c.roundTrip(ctx, method, params, result)
+	if errors.Is(err, errSessionExpired) {
+		if err := c.InitializeContext(ctx); err != nil {
+			return err
+		}
+		return c.roundTrip(ctx, method, params, result)
+	}
+	return err
+}
+
+// roundTrip sends a JSON-RPC request over HTTP and waits for the response,
+// which arrives either as a JSON body or as an event of an SSE stream
+func (c *HTTPMCPClient) roundTrip(ctx context.Context, method string, params interface{}, result interface{}) error {
 	// Generate request ID
 	id := atomic.AddInt64(&c.requestID, 1)
 
@@ -93,33 +339,39 @@ func (c *HTTPMCPClient) call(method string, params interface{}, result interface
 		Params:  params,
 	}
 
-	// Marshal request
-	reqBody, err := json.Marshal(rpcReq)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+	// An initialize request starts a new session
+	if method == "initialize" {
+		c.mu.Lock()
+		c.sessionID = ""
+		c.version = ""
+		c.mu.Unlock()
 	}
 
-	// Send HTTP POST request
-	httpResp, err := c.httpClient.Post(c.baseURL, "application/json", bytes.NewReader(reqBody))
+	httpResp, err := c.post(ctx, rpcReq)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return c.abandon(ctx, id, err)
 	}
 	defer httpResp.Body.Close()
 
+	if method == "initialize" {
+		c.mu.Lock()
+		c.sessionID = httpResp.Header.Get(sessionHeader)
+		c.mu.Unlock()
+	}
+
 	// Check HTTP status
 	if httpResp.StatusCode != http.StatusOK {
 		return fmt.Errorf("HTTP error: %d", httpResp.StatusCode)
 	}
 
-	// Parse JSON-RPC response
-	var rpcResp protocol.JSONRPCMessage
-	if err := json.NewDecoder(httpResp.Body).Decode(&rpcResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+	rpcResp, err := c.readResponse(ctx, httpResp, id)
+	if err != nil {
+		return c.abandon(ctx, id, err)
 	}
 
 	// Check for RPC error
 	if rpcResp.Error != nil {
-		return fmt.Errorf("RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+		return rpcResp.Error
 	}
 
 	// Unmarshal result
@@ -137,7 +389,201 @@ func (c *HTTPMCPClient) call(method string, params interface{}, result interface
 	return nil
 }
 
-// Close closes the client (no-op for HTTP)
+// abandon returns the error of a failed request. When the request failed
+// because ctx is done, the server is told to cancel it, so it does not keep
+// working for nobody.
+func (c *HTTPMCPClient) abandon(ctx context.Context, id int64, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	notifyCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c.notify(notifyCtx, "notifications/cancelled", map[string]interface{}{
+		"requestId": id,
+		"reason":    ctx.Err().Error(),
+	})
+	return ctx.Err()
+}
+
+// notify sends a notification, which the server acknowledges without a
+// response
+func (c *HTTPMCPClient) notify(ctx context.Context, method string, params interface{}) error {
+	httpResp, err := c.post(ctx, protocol.JSONRPCMessage{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	httpResp.Body.Close()
+
+	// Servers predating Streamable HTTP may answer with 200
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP error: %d", httpResp.StatusCode)
+	}
+	return nil
+}
+
+// post sends one JSON-RPC message with the current session id
+func (c *HTTPMCPClient) post(ctx context.Context, msg protocol.JSONRPCMessage) (*http.Response, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	sessionID := c.setSessionHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound && sessionID != "" {
+		resp.Body.Close()
+		return nil, errSessionExpired
+	}
+	return resp, nil
+}
+
+// readResponse reads the response to request id from a JSON body or an SSE
+// stream, resuming the stream if it breaks before the response arrives
+func (c *HTTPMCPClient) readResponse(ctx context.Context, httpResp *http.Response, id int64) (*protocol.JSONRPCMessage, error) {
+	if !strings.HasPrefix(httpResp.Header.Get("Content-Type"), "text/event-stream") {
+		var rpcResp protocol.JSONRPCMessage
+		if err := json.NewDecoder(httpResp.Body).Decode(&rpcResp); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return &rpcResp, nil
+	}
+
+	var rpcResp *protocol.JSONRPCMessage
+	handle := func(msg *protocol.JSONRPCMessage) bool {
+		if msg.Method == "" && matchesID(msg.ID, id) {
+			rpcResp = msg
+			return true
+		}
+		c.dispatch(msg)
+		return false
+	}
+
+	body := httpResp.Body
+	for attempt := 0; ; attempt++ {
+		lastEventID, err := readEvents(body, handle)
+		if rpcResp != nil {
+			return rpcResp, nil
+		}
+		if lastEventID == "" || attempt == maxResumeAttempts {
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("event stream ended without a response: %w", err)
+		}
+
+		resumed, err := c.openStream(ctx, lastEventID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resume event stream: %w", err)
+		}
+		defer resumed.Body.Close()
+		body = resumed.Body
+	}
+}
+
+// setSessionHeaders names the current session and its revision on req,
+// returning the session id
+func (c *HTTPMCPClient) setSessionHeaders(req *http.Request) string {
+	c.mu.Lock()
+	sessionID, version := c.sessionID, c.version
+	c.mu.Unlock()
+	if sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+	if version != "" {
+		req.Header.Set(protocolHeader, version)
+	}
+	return sessionID
+}
+
+// openStream GETs an SSE stream: the server's stream of server-initiated
+// messages, or the stream containing lastEventID to resume it after that
+// event
+func (c *HTTPMCPClient) openStream(ctx context.Context, lastEventID string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	c.setSessionHeaders(req)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// dispatch passes a server-initiated message to the client's callbacks
+func (c *HTTPMCPClient) dispatch(msg *protocol.JSONRPCMessage) {
+	if msg.Method == "" {
+		return
+	}
+	c.mu.Lock()
+	handler := c.onNotify
+	c.mu.Unlock()
+	dispatchNotification(msg, c.opts, handler)
+}
+
+// matchesID reports whether a decoded JSON-RPC id is the given request id
+func matchesID(msgID interface{}, id int64) bool {
+	switch v := msgID.(type) {
+	case float64:
+		return int64(v) == id
+	case string:
+		return v == fmt.Sprint(id)
+	}
+	return false
+}
+
+// Close stops listening and ends the session, if the server started one
 func (c *HTTPMCPClient) Close() error {
+	c.mu.Lock()
+	if c.stopListener != nil {
+		c.stopListener()
+		c.stopListener = nil
+	}
+	sessionID, version := c.sessionID, c.version
+	c.sessionID, c.version = "", ""
+	c.mu.Unlock()
+
+	if sessionID == "" {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, c.baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(sessionHeader, sessionID)
+	if version != "" {
+		req.Header.Set(protocolHeader, version)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	resp.Body.Close()
+
+	// Servers may refuse to let clients end sessions
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to end session: HTTP %d", resp.StatusCode)
+	}
 	return nil
 }
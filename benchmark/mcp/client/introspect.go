@@ -0,0 +1,148 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// Introspect builds an MCPSpec describing an initialized server from its
+// tools, resources and prompts listings. Resources and prompts are only
+// requested when the server advertises them, and servers that do not
+// implement those methods are treated as having none.
+func Introspect(c Introspector) (*spec.MCPSpec, error) {
+	info := c.ServerInfo()
+	caps := c.ServerCapabilities()
+
+	tools, err := c.ListTools()
+	if err != nil {
+		return nil, err
+	}
+
+	mcpSpec := &spec.MCPSpec{
+		Name:    info.Name,
+		Version: info.Version,
+		Tools:   make([]spec.MCPTool, 0, len(tools)),
+	}
+
+	for _, tool := range tools {
+		mcpTool, err := toSpecTool(tool)
+		if err != nil {
+			return nil, err
+		}
+		mcpSpec.Tools = append(mcpSpec.Tools, mcpTool)
+	}
+
+	if caps.Resources != nil {
+		resources, err := c.ListResources()
+		if err != nil && !isMethodNotFound(err) {
+			return nil, err
+		}
+		for _, resource := range resources {
+			mcpSpec.Resources = append(mcpSpec.Resources, spec.MCPResource{
+				URI:         resource.URI,
+				Name:        resource.Name,
+				Description: resource.Description,
+				MimeType:    resource.MimeType,
+			})
+		}
+
+		templates, err := c.ListResourceTemplates()
+		if err != nil && !isMethodNotFound(err) {
+			return nil, err
+		}
+		for _, template := range templates {
+			mcpSpec.Resources = append(mcpSpec.Resources, spec.MCPResource{
+				URITemplate: template.URITemplate,
+				Name:        template.Name,
+				Description: template.Description,
+				MimeType:    template.MimeType,
+			})
+		}
+	}
+
+	if caps.Prompts != nil {
+		prompts, err := c.ListPrompts()
+		if err != nil && !isMethodNotFound(err) {
+			return nil, err
+		}
+		for _, prompt := range prompts {
+			mcpPrompt := spec.MCPPrompt{
+				Name:        prompt.Name,
+				Description: prompt.Description,
+			}
+			for _, arg := range prompt.Arguments {
+				mcpPrompt.Arguments = append(mcpPrompt.Arguments, spec.MCPArgument{
+					Name:        arg.Name,
+					Description: arg.Description,
+					Required:    arg.Required,
+				})
+			}
+			mcpSpec.Prompts = append(mcpSpec.Prompts, mcpPrompt)
+		}
+	}
+
+	return mcpSpec, nil
+}
+
+// ToolDefinitions converts the tools a server lists to tool definitions,
+// keeping their schemas and annotations
+func ToolDefinitions(tools []protocol.Tool) ([]spec.ToolDefinition, error) {
+	mcpSpec := spec.MCPSpec{Tools: make([]spec.MCPTool, 0, len(tools))}
+	for _, tool := range tools {
+		mcpTool, err := toSpecTool(tool)
+		if err != nil {
+			return nil, err
+		}
+		mcpSpec.Tools = append(mcpSpec.Tools, mcpTool)
+	}
+	return mcpSpec.ToToolDefinitions(), nil
+}
+
+// toSpecTool converts a protocol tool to its spec representation.
+// Both share the JSON Schema wire shape, so the schema is converted through JSON.
+func toSpecTool(tool protocol.Tool) (spec.MCPTool, error) {
+	mcpTool := spec.MCPTool{
+		Name:        tool.Name,
+		Description: tool.Description,
+	}
+
+	schemaJSON, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return mcpTool, fmt.Errorf("failed to marshal input schema for %s: %w", tool.Name, err)
+	}
+	if err := json.Unmarshal(schemaJSON, &mcpTool.InputSchema); err != nil {
+		return mcpTool, fmt.Errorf("failed to convert input schema for %s: %w", tool.Name, err)
+	}
+
+	if tool.Annotations != nil {
+		mcpTool.Annotations = &spec.ToolAnnotations{
+			Title:           tool.Annotations.Title,
+			ReadOnlyHint:    tool.Annotations.ReadOnlyHint,
+			DestructiveHint: tool.Annotations.DestructiveHint,
+			IdempotentHint:  tool.Annotations.IdempotentHint,
+			OpenWorldHint:   tool.Annotations.OpenWorldHint,
+		}
+	}
+
+	if tool.OutputSchema != nil {
+		schemaJSON, err := json.Marshal(tool.OutputSchema)
+		if err != nil {
+			return mcpTool, fmt.Errorf("failed to marshal output schema for %s: %w", tool.Name, err)
+		}
+		if err := json.Unmarshal(schemaJSON, &mcpTool.OutputSchema); err != nil {
+			return mcpTool, fmt.Errorf("failed to convert output schema for %s: %w", tool.Name, err)
+		}
+	}
+
+	return mcpTool, nil
+}
+
+// isMethodNotFound reports whether err is a JSON-RPC method-not-found error
+func isMethodNotFound(err error) bool {
+	var rpcErr *protocol.RPCError
+	return errors.As(err, &rpcErr) && rpcErr.Code == protocol.MethodNotFound
+}
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// pagedServer is an MCP server over HTTP that serves each listing in pages,
+// and answers the methods in missing with method not found
+type pagedServer struct {
+	capabilities protocol.ServerCapabilities
+	pages        map[string][]string // method -> JSON list results, one per page
+	missing      map[string]bool
+
+	mu      sync.Mutex
+	cursors map[string][]string // method -> cursors received
+}
+
+func (s *pagedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			Cursor string `json:"cursor"`
+		} `json:"params"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	if req.ID == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	var result interface{}
+	switch {
+	case req.Method == "initialize":
+		result = protocol.InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    s.capabilities,
+			ServerInfo:      protocol.ServerInfo{Name: "paged", Version: "2.0.0"},
+		}
+	case s.missing[req.Method]:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"Method not found"}}`, req.ID)
+		return
+	case s.pages[req.Method] == nil:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32603,"message":"Internal error"}}`, req.ID)
+		return
+	default:
+		s.mu.Lock()
+		if s.cursors == nil {
+			s.cursors = map[string][]string{}
+		}
+		s.cursors[req.Method] = append(s.cursors[req.Method], req.Params.Cursor)
+		s.mu.Unlock()
+
+		// Cursors are page numbers; the last page has none
+		pages := s.pages[req.Method]
+		page := 0
+		fmt.Sscan(req.Params.Cursor, &page)
+		var fields map[string]interface{}
+		json.Unmarshal([]byte(pages[page]), &fields)
+		if page+1 < len(pages) {
+			fields["nextCursor"] = fmt.Sprint(page + 1)
+		}
+		result = fields
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+// introspect connects an initialized client to srv over HTTP
+func introspect(t *testing.T, srv *pagedServer) *HTTPMCPClient {
+	t.Helper()
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	c := NewHTTPMCPClient(ts.URL)
+	t.Cleanup(func() { c.Close() })
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestIntrospectFollowsCursors(t *testing.T) {
+	srv := &pagedServer{
+		capabilities: protocol.ServerCapabilities{
+			Tools:     &protocol.ToolsCapability{},
+			Resources: &protocol.ResourcesCapability{},
+			Prompts:   &protocol.PromptsCapability{},
+		},
+		pages: map[string][]string{
+			"tools/list": {
+				`{"tools":[{"name":"a","inputSchema":{"type":"object","properties":{"n":{"type":"integer","minimum":1}},"required":["n"]}}]}`,
+				`{"tools":[{"name":"b","inputSchema":{"type":"object"},"annotations":{"readOnlyHint":true}}]}`,
+				`{"tools":[{"name":"c","inputSchema":{"type":"object"}}]}`,
+			},
+			"resources/list": {
+				`{"resources":[{"uri":"file:///a","name":"a"}]}`,
+				`{"resources":[{"uri":"file:///b","name":"b","mimeType":"text/plain"}]}`,
+			},
+			"resources/templates/list": {
+				`{"resourceTemplates":[{"uriTemplate":"file:///{path}","name":"file"}]}`,
+				`{"resourceTemplates":[{"uriTemplate":"db:///{table}","name":"table"}]}`,
+			},
+			"prompts/list": {
+				`{"prompts":[{"name":"p1"}]}`,
+				`{"prompts":[{"name":"p2","arguments":[{"name":"topic","required":true}]}]}`,
+			},
+		},
+	}
+	c := introspect(t, srv)
+
+	mcpSpec, err := Introspect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mcpSpec.Name != "paged" || mcpSpec.Version != "2.0.0" {
+		t.Errorf("Expected the server's identity, got %s %s", mcpSpec.Name, mcpSpec.Version)
+	}
+
+	var names []string
+	for _, tool := range mcpSpec.Tools {
+		names = append(names, tool.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+		t.Errorf("Expected the tools of all three pages, got %v", names)
+	}
+	if n := mcpSpec.Tools[0].InputSchema.Properties["n"]; n.Type != "integer" || len(mcpSpec.Tools[0].InputSchema.Required) != 1 {
+		t.Errorf("Expected the input schema to be converted, got %+v", mcpSpec.Tools[0].InputSchema)
+	}
+	if a := mcpSpec.Tools[1].Annotations; a == nil || a.ReadOnlyHint == nil || !*a.ReadOnlyHint {
+		t.Errorf("Expected the annotations to be kept, got %+v", a)
+	}
+
+	var uris []string
+	for _, resource := range mcpSpec.Resources {
+		uris = append(uris, resource.URI+resource.URITemplate)
+	}
+	if !reflect.DeepEqual(uris, []string{"file:///a", "file:///b", "file:///{path}", "db:///{table}"}) {
+		t.Errorf("Expected both pages of resources and of templates, got %v", uris)
+	}
+	if len(mcpSpec.Prompts) != 2 || !mcpSpec.Prompts[1].Arguments[0].Required {
+		t.Errorf("Expected both prompt pages, got %+v", mcpSpec.Prompts)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if got := srv.cursors["tools/list"]; !reflect.DeepEqual(got, []string{"", "1", "2"}) {
+		t.Errorf("Expected each cursor to be sent back once, got %q", got)
+	}
+}
+
+func TestIntrospectWithoutResourcesAndPrompts(t *testing.T) {
+	tools := map[string][]string{"tools/list": {`{"tools":[{"name":"a","inputSchema":{"type":"object"}}]}`}}
+
+	// Advertised, but answered with method not found
+	srv := &pagedServer{
+		capabilities: protocol.ServerCapabilities{Resources: &protocol.ResourcesCapability{}, Prompts: &protocol.PromptsCapability{}},
+		pages:        tools,
+		missing:      map[string]bool{"resources/list": true, "resources/templates/list": true, "prompts/list": true},
+	}
+	c := introspect(t, srv)
+	mcpSpec, err := Introspect(c)
+	if err != nil {
+		t.Fatalf("Expected method not found to read as no resources or prompts, got %v", err)
+	}
+	if len(mcpSpec.Tools) != 1 || mcpSpec.Resources != nil || mcpSpec.Prompts != nil {
+		t.Errorf("Expected only the tool, got %+v", mcpSpec)
+	}
+
+	// Not advertised, so not asked for
+	srv = &pagedServer{pages: tools}
+	c = introspect(t, srv)
+	if _, err := Introspect(c); err != nil {
+		t.Fatal(err)
+	}
+	srv.mu.Lock()
+	if len(srv.cursors) != 1 {
+		t.Errorf("Expected only tools/list to be called, got %v", srv.cursors)
+	}
+	srv.mu.Unlock()
+
+	// Other errors are not swallowed
+	srv = &pagedServer{capabilities: protocol.ServerCapabilities{Prompts: &protocol.PromptsCapability{}}, pages: tools}
+	c = introspect(t, srv)
+	if _, err := Introspect(c); err == nil {
+		t.Error("Expected an internal error listing prompts to fail introspection")
+	}
+}
+
+func TestIsMethodNotFound(t *testing.T) {
+	if !isMethodNotFound(fmt.Errorf("prompts/list: %w", &protocol.RPCError{Code: protocol.MethodNotFound})) {
+		t.Error("Expected a wrapped method not found error to be recognised")
+	}
+	if isMethodNotFound(&protocol.RPCError{Code: protocol.InvalidParams}) || isMethodNotFound(fmt.Errorf("method not found")) {
+		t.Error("Expected other errors not to be method not found")
+	}
+}
+
+func TestListingStopsOnRepeatedCursor(t *testing.T) {
+	calls := 0
+	call := func(method string, params interface{}, result interface{}) error {
+		calls++
+		return json.Unmarshal([]byte(`{"tools":[{"name":"a"}],"nextCursor":"same"}`), result)
+	}
+	if _, err := listAllTools(call); err == nil {
+		t.Error("Expected a repeated cursor to fail the listing")
+	}
+	if calls != 2 {
+		t.Errorf("Expected the listing to stop at the repeated cursor, got %d calls", calls)
+	}
+
+	calls = 0
+	call = func(method string, params interface{}, result interface{}) error {
+		calls++
+		return json.Unmarshal([]byte(fmt.Sprintf(`{"prompts":[],"nextCursor":"%d"}`, calls)), result)
+	}
+	if _, err := listAllPrompts(call); err == nil {
+		t.Error("Expected an endless listing to fail")
+	}
+	if calls != maxPages {
+		t.Errorf("Expected the listing to stop after %d pages, got %d", maxPages, calls)
+	}
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// NotificationHandler receives messages the server sends on its own
+// initiative, such as notifications/tools/list_changed
+type NotificationHandler func(msg *protocol.JSONRPCMessage)
+
+// dispatchNotification passes a server notification to the matching
+// callback of opts, and every notification to handler when it is set
+func dispatchNotification(msg *protocol.JSONRPCMessage, opts Options, handler NotificationHandler) {
+	switch msg.Method {
+	case "notifications/progress":
+		if opts.OnProgress != nil {
+			var progress protocol.ProgressNotification
+			if decodeNotification(msg, &progress) {
+				opts.OnProgress(progress)
+			}
+		}
+	case "notifications/message":
+		if opts.OnLog != nil {
+			var message protocol.LoggingMessageNotification
+			if decodeNotification(msg, &message) {
+				opts.OnLog(message)
+			}
+		}
+	}
+	if handler != nil {
+		handler(msg)
+	}
+}
+
+// decodeNotification decodes the params of a notification
+func decodeNotification(msg *protocol.JSONRPCMessage, v interface{}) bool {
+	data, err := json.Marshal(msg.Params)
+	return err == nil && json.Unmarshal(data, v) == nil
+}
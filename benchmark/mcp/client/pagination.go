@@ -0,0 +1,132 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// maxPages bounds the pages read from a single listing
+const maxPages = 1000
+
+// rpcCall sends a JSON-RPC request and decodes its result
+type rpcCall func(method string, params interface{}, result interface{}) error
+
+// pager follows the cursors of one listing. It fails when the server hands
+// back a cursor it already returned or the listing exceeds maxPages, so a
+// buggy server cannot make the client loop forever.
+type pager struct {
+	method string
+	seen   map[string]bool
+}
+
+func newPager(method string) *pager {
+	return &pager{method: method, seen: make(map[string]bool)}
+}
+
+// next returns the cursor of the following page, or false after the last page
+func (p *pager) next(nextCursor *string) (string, bool, error) {
+	if nextCursor == nil || *nextCursor == "" {
+		return "", false, nil
+	}
+	if p.seen[*nextCursor] {
+		return "", false, fmt.Errorf("%s: server repeated cursor %q", p.method, *nextCursor)
+	}
+	if len(p.seen)+1 >= maxPages {
+		return "", false, fmt.Errorf("%s: more than %d pages", p.method, maxPages)
+	}
+	p.seen[*nextCursor] = true
+	return *nextCursor, true, nil
+}
+
+// listAllTools calls tools/list until the server stops returning a cursor
+func listAllTools(call rpcCall) ([]protocol.Tool, error) {
+	var tools []protocol.Tool
+	cursor := ""
+	p := newPager("tools/list")
+	for {
+		var result protocol.ListToolsResult
+		if err := call("tools/list", protocol.ListToolsRequest{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		tools = append(tools, result.Tools...)
+
+		next, more, err := p.next(result.NextCursor)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return tools, nil
+		}
+		cursor = next
+	}
+}
+
+// listAllResources calls resources/list until the server stops returning a cursor
+func listAllResources(call rpcCall) ([]protocol.Resource, error) {
+	var resources []protocol.Resource
+	cursor := ""
+	p := newPager("resources/list")
+	for {
+		var result protocol.ListResourcesResult
+		if err := call("resources/list", protocol.ListResourcesRequest{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		resources = append(resources, result.Resources...)
+
+		next, more, err := p.next(result.NextCursor)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return resources, nil
+		}
+		cursor = next
+	}
+}
+
+// listAllPrompts calls prompts/list until the server stops returning a cursor
+func listAllPrompts(call rpcCall) ([]protocol.Prompt, error) {
+	var prompts []protocol.Prompt
+	cursor := ""
+	p := newPager("prompts/list")
+	for {
+		var result protocol.ListPromptsResult
+		if err := call("prompts/list", protocol.ListPromptsRequest{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, result.Prompts...)
+
+		next, more, err := p.next(result.NextCursor)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return prompts, nil
+		}
+		cursor = next
+	}
+}
+
+// listAllResourceTemplates calls resources/templates/list until the server stops returning a cursor
+func listAllResourceTemplates(call rpcCall) ([]protocol.ResourceTemplate, error) {
+	var templates []protocol.ResourceTemplate
+	cursor := ""
+	p := newPager("resources/templates/list")
+	for {
+		var result protocol.ListResourceTemplatesResult
+		if err := call("resources/templates/list", protocol.ListResourceTemplatesRequest{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		templates = append(templates, result.ResourceTemplates...)
+
+		next, more, err := p.next(result.NextCursor)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return templates, nil
+		}
+		cursor = next
+	}
+}
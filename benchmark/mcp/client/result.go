@@ -0,0 +1,101 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// Media is image or audio content with its data decoded
+type Media struct {
+	Type     string // protocol.ContentImage or protocol.ContentAudio
+	MimeType string
+	Data     []byte
+}
+
+// ResultValue unwraps a CallToolResult into plain Go values.
+// structuredContent is returned when present. Otherwise text content holding
+// JSON is decoded and other text is returned as a string, images and audio
+// as Media, embedded resources as protocol.ResourceContents and resource
+// links as protocol.Resource.
+// A single content item is returned directly, several are returned as a slice.
+// Results flagged with IsError are returned as an error.
+func ResultValue(result *protocol.CallToolResult) (interface{}, error) {
+	if result == nil {
+		return nil, nil
+	}
+
+	if result.IsError {
+		var texts []string
+		for _, content := range result.Content {
+			if content.Text != "" {
+				texts = append(texts, content.Text)
+			}
+		}
+		return nil, fmt.Errorf("tool error: %s", strings.Join(texts, "\n"))
+	}
+
+	if result.StructuredContent != nil {
+		return result.StructuredContent, nil
+	}
+
+	values := make([]interface{}, 0, len(result.Content))
+	for _, content := range result.Content {
+		values = append(values, contentValue(content))
+	}
+
+	switch len(values) {
+	case 0:
+		return nil, nil
+	case 1:
+		return values[0], nil
+	default:
+		return values, nil
+	}
+}
+
+// contentValue converts a single content item to a Go value
+func contentValue(content protocol.Content) interface{} {
+	switch content.Type {
+	case protocol.ContentText:
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(content.Text), &decoded); err == nil {
+			return decoded
+		}
+		return content.Text
+	case protocol.ContentImage, protocol.ContentAudio:
+		data, err := content.DecodeData()
+		if err != nil {
+			return content.Data
+		}
+		return Media{Type: content.Type, MimeType: content.MimeType, Data: data}
+	case protocol.ContentResource:
+		if content.Resource != nil {
+			return *content.Resource
+		}
+	case protocol.ContentResourceLink:
+		return protocol.Resource{
+			URI:         content.URI,
+			Name:        content.Name,
+			Description: content.Description,
+			MimeType:    content.MimeType,
+		}
+	}
+	return content.Data
+}
+
+// ResourceText joins the contents of a resources/read result. Text contents
+// are returned as-is and binary contents as their base64 encoding.
+func ResourceText(result *protocol.ReadResourceResult) string {
+	parts := make([]string, 0, len(result.Contents))
+	for _, content := range result.Contents {
+		if content.Text != "" {
+			parts = append(parts, content.Text)
+		} else {
+			parts = append(parts, content.Blob)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// readEvents reads a server-sent event stream, passing each JSON-RPC
+// message to handle until handle returns true or the stream ends. It
+// returns the id of the last event read, from which the stream can be
+// resumed with Last-Event-ID.
+func readEvents(body io.Reader, handle func(*protocol.JSONRPCMessage) bool) (string, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 10<<20)
+
+	var lastEventID string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event
+			if len(data) > 0 {
+				var msg protocol.JSONRPCMessage
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &msg); err == nil && handle(&msg) {
+					return lastEventID, nil
+				}
+			}
+			data = data[:0]
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment, e.g. a keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			lastEventID = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return lastEventID, scanner.Err()
+}
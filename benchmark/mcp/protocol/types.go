@@ -1,5 +1,13 @@
 package protocol
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
 // MCP Protocol Types following the Model Context Protocol specification
 // Based on JSON-RPC 2.0
 
@@ -20,6 +28,11 @@ type RPCError struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// Error implements the error interface so RPC errors can be returned and inspected with errors.As
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
 // InitializeRequest represents the initialize method parameters
 type InitializeRequest struct {
 	ProtocolVersion string                 `json:"protocolVersion"`
@@ -38,7 +51,8 @@ type ClientCapabilities struct {
 }
 
 type ToolsCapability struct {
-	Supported bool `json:"supported"`
+	Supported   bool `json:"supported"`
+	ListChanged bool `json:"listChanged,omitempty"` // the server sends notifications/tools/list_changed
 }
 
 type ResourcesCapability struct {
@@ -99,45 +113,313 @@ type ListToolsResult struct {
 	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
-// Tool represents an MCP tool definition
+// Tool represents an MCP tool definition. OutputSchema, when set, describes
+// the structuredContent of the tool's results.
 type Tool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema InputSchema `json:"inputSchema"`
+	Name         string           `json:"name"`
+	Title        string           `json:"title,omitempty"`
+	Description  string           `json:"description"`
+	InputSchema  InputSchema      `json:"inputSchema"`
+	OutputSchema *InputSchema     `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints about a tool's behaviour. Clients must not rely
+// on them for safety. Unset hints take the defaults: not read-only,
+// destructive, not idempotent and open-world.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
 }
 
-// InputSchema defines the parameters for a tool
+// InputSchema is a JSON Schema, as used for the arguments and results of a
+// tool and for each of their properties. The common keywords have fields;
+// all others, including a list of types, are kept in Extra, so schemas
+// decode and encode without loss.
 type InputSchema struct {
-	Type       string                 `json:"type"`
-	Properties map[string]Property    `json:"properties"`
-	Required   []string               `json:"required,omitempty"`
+	Ref         string              `json:"$ref,omitempty"`
+	Type        string              `json:"type,omitempty"`
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Format      string              `json:"format,omitempty"`
+	Enum        []interface{}       `json:"enum,omitempty"`
+	Default     interface{}         `json:"default,omitempty"`
+	Properties  map[string]Property `json:"properties,omitempty"`
+	Required    []string            `json:"required,omitempty"`
+	Items       *InputSchema        `json:"items,omitempty"`
+	AnyOf       []InputSchema       `json:"anyOf,omitempty"`
+	OneOf       []InputSchema       `json:"oneOf,omitempty"`
+	AllOf       []InputSchema       `json:"allOf,omitempty"`
+
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Property is the schema of one property of an object
+type Property = InputSchema
+
+// UnmarshalJSON decodes a schema, keeping keywords without a field in Extra
+func (s *InputSchema) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	// "type" may also be a list of types, which only Extra can hold
+	var extra map[string]interface{}
+	keep := func(key string) error {
+		var value interface{}
+		if err := json.Unmarshal(raw[key], &value); err != nil {
+			return err
+		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[key] = value
+		delete(raw, key)
+		return nil
+	}
+	if t, ok := raw["type"]; ok && len(t) > 0 && t[0] != '"' {
+		if err := keep("type"); err != nil {
+			return err
+		}
+	}
+	for key := range raw {
+		if !schemaKeywords[key] {
+			if err := keep(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	known, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	type plain InputSchema
+	var decoded plain
+	if err := json.Unmarshal(known, &decoded); err != nil {
+		return err
+	}
+	*s = InputSchema(decoded)
+	s.Extra = extra
+	return nil
 }
 
-// Property defines a single parameter
-type Property struct {
-	Type        string   `json:"type"`
-	Description string   `json:"description,omitempty"`
-	Enum        []string `json:"enum,omitempty"`
+// MarshalJSON encodes a schema together with its Extra keywords
+func (s InputSchema) MarshalJSON() ([]byte, error) {
+	type plain InputSchema
+	data, err := json.Marshal(plain(s))
+	if err != nil || len(s.Extra) == 0 {
+		return data, err
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range s.Extra {
+		if _, set := merged[key]; !set {
+			merged[key] = value
+		}
+	}
+	return json.Marshal(merged)
 }
 
+// schemaKeywords are the keywords InputSchema has fields for
+var schemaKeywords = func() map[string]bool {
+	keywords := make(map[string]bool)
+	t := reflect.TypeOf(InputSchema{})
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "-" {
+			keywords[name] = true
+		}
+	}
+	return keywords
+}()
+
 // CallToolRequest represents the tools/call method
 type CallToolRequest struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta is the _meta of a request. A progressToken asks the server to
+// report the request's progress with notifications/progress.
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
 }
 
 // CallToolResult is the response to tools/call
 type CallToolResult struct {
-	Content []Content `json:"content"`
-	IsError bool      `json:"isError,omitempty"`
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"` // a JSON object, since 2025-06-18
+	IsError           bool        `json:"isError,omitempty"`
 }
 
-// Content represents the result content
+// Content types
+const (
+	ContentText         = "text"
+	ContentImage        = "image"
+	ContentAudio        = "audio"
+	ContentResource     = "resource"      // an embedded resource
+	ContentResourceLink = "resource_link" // a resource the client can read, since 2025-06-18
+)
+
+// Content represents the result content. Images and audio carry base64
+// Data with its MimeType, embedded resources their Resource, and resource
+// links the URI, Name, Description and MimeType of the resource.
 type Content struct {
-	Type     string      `json:"type"`
-	Text     string      `json:"text,omitempty"`
-	Data     interface{} `json:"data,omitempty"`
-	MimeType string      `json:"mimeType,omitempty"`
+	Type        string            `json:"type"`
+	Text        string            `json:"text,omitempty"`
+	Data        interface{}       `json:"data,omitempty"`
+	MimeType    string            `json:"mimeType,omitempty"`
+	Resource    *ResourceContents `json:"resource,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// DecodeData decodes the base64 Data of image and audio content
+func (c Content) DecodeData() ([]byte, error) {
+	encoded, ok := c.Data.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s content data is %T, not a base64 string", c.Type, c.Data)
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+// ListResourcesRequest represents the resources/list method
+type ListResourcesRequest struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListResourcesResult is the response to resources/list
+type ListResourcesResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor *string    `json:"nextCursor,omitempty"`
+}
+
+// Resource represents an MCP resource definition
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ListResourceTemplatesRequest represents the resources/templates/list method
+type ListResourceTemplatesRequest struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListResourceTemplatesResult is the response to resources/templates/list
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        *string            `json:"nextCursor,omitempty"`
+}
+
+// ResourceTemplate represents a parameterized MCP resource, e.g. "users://{id}/profile"
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ReadResourceRequest represents the resources/read method
+type ReadResourceRequest struct {
+	URI string `json:"uri"`
+}
+
+// ReadResourceResult is the response to resources/read
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourceContents is the content of a resource, as text or base64-encoded blob
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ListPromptsRequest represents the prompts/list method
+type ListPromptsRequest struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListPromptsResult is the response to prompts/list
+type ListPromptsResult struct {
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor *string  `json:"nextCursor,omitempty"`
+}
+
+// Prompt represents an MCP prompt template definition
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument accepted by a prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// GetPromptRequest represents the prompts/get method
+type GetPromptRequest struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// GetPromptResult is the response to prompts/get
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptMessage is a single message of a rendered prompt
+type PromptMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// ProgressNotification is the params of notifications/progress
+type ProgressNotification struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+// LoggingLevels lists the levels of logging/setLevel and
+// notifications/message, least severe first
+var LoggingLevels = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// SetLevelRequest represents the logging/setLevel method
+type SetLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// LoggingMessageNotification is the params of notifications/message
+type LoggingMessageNotification struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
 }
 
 // Standard error codes
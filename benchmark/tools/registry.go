@@ -1,7 +1,9 @@
 package tools
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -9,17 +11,27 @@ import (
 	"github.com/imran31415/godemode/benchmark/systems/database"
 	"github.com/imran31415/godemode/benchmark/systems/graph"
 	"github.com/imran31415/godemode/benchmark/systems/security"
+	"github.com/imran31415/godemode/pkg/codegen"
+	"github.com/imran31415/godemode/pkg/spec"
 )
 
 // ToolFunc is a function signature for tools
 type ToolFunc func(args map[string]interface{}) (interface{}, error)
 
+// ContextToolFunc is the signature of tools that stop when their caller
+// cancels, such as an MCP client cancelling a request
+type ContextToolFunc func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+
 // ToolInfo contains metadata about a tool
 type ToolInfo struct {
 	Name        string
 	Description string
 	Parameters  []ParamInfo
 	Function    ToolFunc
+
+	// ContextFunction, when set, is used instead of Function and receives
+	// the caller's context
+	ContextFunction ContextToolFunc
 }
 
 // ParamInfo describes a parameter
@@ -644,11 +656,20 @@ func (r *Registry) Get(name string) (*ToolInfo, bool) {
 
 // Call invokes a tool by name with arguments
 func (r *Registry) Call(name string, args map[string]interface{}) (interface{}, error) {
+	return r.CallContext(context.Background(), name, args)
+}
+
+// CallContext invokes a tool by name with arguments, passing ctx to tools
+// with a ContextFunction
+func (r *Registry) CallContext(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
 	tool, found := r.Get(name)
 	if !found {
 		return nil, fmt.Errorf("tool not found: %s", name)
 	}
 
+	if tool.ContextFunction != nil {
+		return tool.ContextFunction(ctx, args)
+	}
 	return tool.Function(args)
 }
 
@@ -669,31 +690,37 @@ func (r *Registry) List() []string {
 	return names
 }
 
-// GetDocumentation returns formatted documentation for all tools
+// GetDocumentation returns prompt-ready documentation for all tools
 func (r *Registry) GetDocumentation() string {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	doc := "Available Tools:\n\n"
+	return codegen.RenderAPIDocs(ToolDefinitions(r.ListTools()), codegen.DocOptions{
+		Verbosity: codegen.VerbosityFull,
+	})
+}
 
-	for _, tool := range r.tools {
-		doc += fmt.Sprintf("## %s\n", tool.Name)
-		doc += fmt.Sprintf("%s\n\n", tool.Description)
-
-		if len(tool.Parameters) > 0 {
-			doc += "Parameters:\n"
-			for _, param := range tool.Parameters {
-				required := ""
-				if param.Required {
-					required = " (required)"
-				}
-				doc += fmt.Sprintf("  - %s (%s)%s\n", param.Name, param.Type, required)
+// ToolDefinitions converts registered tools to spec tool definitions, sorted
+// by name, for rendering with the codegen documentation helpers
+func ToolDefinitions(tools []*ToolInfo) []spec.ToolDefinition {
+	defs := make([]spec.ToolDefinition, len(tools))
+	for i, tool := range tools {
+		params := make([]spec.Parameter, len(tool.Parameters))
+		for j, param := range tool.Parameters {
+			params[j] = spec.Parameter{
+				Name:     param.Name,
+				Type:     param.Type,
+				Required: param.Required,
 			}
-			doc += "\n"
+		}
+		defs[i] = spec.ToolDefinition{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  params,
 		}
 	}
 
-	return doc
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
 }
 
 // ListTools returns a list of all registered tools
@@ -0,0 +1,103 @@
+// Command mcp-gateway serves the tools of several MCP servers as one MCP
+// server. The upstream servers are read from a JSON config file (see
+// gateway.Config):
+//
+//	mcp-gateway -config gateway.json
+//	mcp-gateway -config gateway.json -http :8090
+//	mcp-gateway -config gateway.json -code-mode
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/imran31415/godemode/pkg/mcp/codemode"
+	"github.com/imran31415/godemode/pkg/mcp/gateway"
+	"github.com/imran31415/godemode/pkg/mcp/server"
+)
+
+const version = "1.0.0"
+
+func main() {
+	configPath := flag.String("config", "", "Path to the JSON file listing the upstream servers (required)")
+	addr := flag.String("http", "", "Serve HTTP on this address (e.g. :8090) instead of stdio")
+	codeMode := flag.Bool("code-mode", false, "Serve execute_go and list_api over the upstream tools instead of the tools themselves")
+	timeout := flag.Duration("timeout", 30*time.Second, "Maximum run time of one execute_go call in code mode")
+	name := flag.String("name", "godemode-gateway", "Server name reported to clients")
+	verbose := flag.Bool("v", false, "Log requests and upstream connections to stderr")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: mcp-gateway -config <file> [-http <addr>] [-code-mode]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *configPath == "" || flag.NArg() > 0 || *timeout <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// stdout carries the protocol over stdio, so logs always go to stderr
+	logger := log.New(io.Discard, "", 0)
+	if *verbose {
+		logger = log.New(os.Stderr, "[mcp-gateway] ", log.LstdFlags)
+	}
+
+	if err := run(*configPath, *addr, *codeMode, *timeout, *name, logger); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run connects to the upstream servers and serves them until stdin closes,
+// the HTTP server fails or the process is interrupted
+func run(configPath, addr string, codeMode bool, timeout time.Duration, name string, logger *log.Logger) error {
+	config, err := gateway.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	gw, err := gateway.New(*config, gateway.Options{Logger: logger})
+	if err != nil {
+		return err
+	}
+	defer gw.Close()
+
+	var provider server.ToolProvider = gw
+	if codeMode {
+		// The API docs of execute_go are those of the tools listed now
+		if provider, err = codemode.NewProvider(gw, codemode.Options{Timeout: timeout}); err != nil {
+			return err
+		}
+	}
+	srv := server.New(provider, server.Options{Name: name, Version: version, Logger: logger})
+
+	// Stop the upstream servers launched by the gateway when interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if addr == "" {
+		return srv.ServeStdio(ctx, os.Stdin, os.Stdout)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", srv)
+	httpServer := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		httpServer.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving %d tools on http://%s/mcp\n", len(gw.Tools()), addr)
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/imran31415/godemode/pkg/mcp/conformance"
+)
+
+// runConformance implements the conformance subcommand and returns the
+// process exit code: 0 when the server passes every check, 1 when it
+// violates any and 2 on usage errors or when the server cannot be started
+func runConformance(args []string) int {
+	fs := flag.NewFlagSet("conformance", flag.ContinueOnError)
+	mcpCommand := fs.String("mcp-command", "", "Check the MCP server started by this command line over stdio")
+	mcpURL := fs.String("mcp-url", "", "Check the MCP server at this Streamable HTTP URL")
+	call := fs.String("call", "", "Also call this tool, which must succeed")
+	callArgs := fs.String("args", "{}", "JSON object of arguments for -call")
+	protocolVersion := fs.String("protocol-version", "", "Protocol version sent in initialize (default 2025-06-18)")
+	skipBatches := fs.Bool("skip-batches", false, "Leave out the JSON-RPC batch checks")
+	timeout := fs.Duration("timeout", 10*time.Second, "Time allowed for each check")
+	outputFormat := fs.String("format", "text", "Output format: text or json")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: spec-to-godemode conformance -mcp-command <command> | -mcp-url <url> [-call <tool> [-args <json>]] [-format text|json]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 || (*mcpCommand == "") == (*mcpURL == "") || (*outputFormat != "text" && *outputFormat != "json") {
+		fs.Usage()
+		return 2
+	}
+
+	opts := conformance.Options{ProtocolVersion: *protocolVersion, Timeout: *timeout, SkipBatches: *skipBatches}
+	if *call != "" {
+		opts.Call = &conformance.ToolCall{Name: *call}
+		if err := json.Unmarshal([]byte(*callArgs), &opts.Call.Arguments); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -args must be a JSON object: %v\n", err)
+			return 2
+		}
+	}
+
+	var conn conformance.Conn
+	if *mcpURL != "" {
+		conn = conformance.DialHTTP(*mcpURL)
+	} else {
+		var err error
+		if conn, err = conformance.DialStdio(*mcpCommand); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+	}
+	defer conn.Close()
+
+	report := conformance.Run(context.Background(), conn, opts)
+	if *outputFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to marshal conformance report: %v\n", err)
+			return 2
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Print(report.String())
+	}
+
+	if !report.Passed() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// runConvert implements the convert subcommand, which rewrites the tools of
+// one or more specs in another format, and returns the process exit code: 0
+// on success, 1 when the specs cannot be converted and 2 on usage errors
+func runConvert(args []string) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	outputFormat := fs.String("format", "", "Spec format to write: mcp, openapi, openrpc, openai or anthropic (required)")
+	name := fs.String("name", "", "API name (default: base name of the first spec file)")
+	apiVersion := fs.String("version", "1.0.0", "API version")
+	description := fs.String("description", "", "API description")
+	serverURL := fs.String("server-url", "", "OpenAPI or OpenRPC server URL the tools are served from")
+	output := fs.String("o", "", "File to write the spec to (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: spec-to-godemode convert -format <format> [-o <file>] <spec file>|<namespace>=<spec file>...")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	format := spec.SpecFormat(*outputFormat)
+	if fs.NArg() == 0 || !isExportFormat(format) {
+		fs.Usage()
+		return 2
+	}
+	if *name == "" {
+		first := parseSpecSource(fs.Arg(0)).path
+		*name = strings.TrimSuffix(filepath.Base(first), filepath.Ext(first))
+	}
+
+	// Keep stdout for the converted spec
+	if *output == "" {
+		progress = os.Stderr
+	}
+	_, tools, err := loadSpecs(fs.Args(), generateOptions{impl: implStub})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	data, err := spec.EncodeSpec(spec.ExportInfo{
+		Name:        *name,
+		Version:     *apiVersion,
+		Description: *description,
+		ServerURL:   *serverURL,
+	}, tools, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write spec: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s spec to %s\n", format, *output)
+	return 0
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// exportMainTemplate is a throwaway program that builds the registry and
+// prints its exported spec. It runs inside the caller's module so the
+// registry package resolves like any other import.
+const exportMainTemplate = `package main
+
+import (
+	"fmt"
+	"os"
+
+	exported {{printf "%q" .Package}}
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func main() {
+	data, err := spec.ExportRegistry(exported.{{.Constructor}}, spec.ExportInfo{
+		Name:        {{printf "%q" .Name}},
+		Version:     {{printf "%q" .Version}},
+		Description: {{printf "%q" .Description}},
+		ServerURL:   {{printf "%q" .ServerURL}},
+	}, spec.SpecFormat({{printf "%q" .Format}}))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(data)
+}
+`
+
+// runExport implements the export subcommand and returns the process exit
+// code: 0 on success, 1 when the registry cannot be exported and 2 on usage
+// errors
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	registryPkg := fs.String("registry", "", "Import path of the package declaring the registry (required)")
+	constructor := fs.String("constructor", "NewRegistry()", "Expression in that package building the registry")
+	outputFormat := fs.String("format", "mcp", "Spec format to write: mcp, openapi, openrpc, openai or anthropic")
+	name := fs.String("name", "", "API name (default: last element of the import path)")
+	apiVersion := fs.String("version", "1.0.0", "API version")
+	description := fs.String("description", "", "API description")
+	serverURL := fs.String("server-url", "", "OpenAPI or OpenRPC server URL the tools are served from")
+	output := fs.String("o", "", "File to write the spec to (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: spec-to-godemode export -registry <import path> [-constructor <expr>] [-format <format>] [-o <file>]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	format := spec.SpecFormat(*outputFormat)
+	if *registryPkg == "" || fs.NArg() > 0 || !isExportFormat(format) {
+		fs.Usage()
+		return 2
+	}
+	if *name == "" {
+		*name = path.Base(*registryPkg)
+	}
+
+	data, err := exportRegistry(exportOptions{
+		Package:     *registryPkg,
+		Constructor: *constructor,
+		Format:      format,
+		Name:        *name,
+		Version:     *apiVersion,
+		Description: *description,
+		ServerURL:   *serverURL,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write spec: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s spec to %s\n", format, *output)
+	return 0
+}
+
+// isExportFormat reports whether specs can be written in format
+func isExportFormat(format spec.SpecFormat) bool {
+	for _, f := range spec.ExportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// exportOptions configures exportRegistry
+type exportOptions struct {
+	Package     string
+	Constructor string
+	Format      spec.SpecFormat
+	Name        string
+	Version     string
+	Description string
+	ServerURL   string
+}
+
+// exportRegistry builds and runs a program in the current module that
+// constructs the registry and prints its spec
+func exportRegistry(opts exportOptions) ([]byte, error) {
+	dir, err := writeProgram("export", exportMainTemplate, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to export %s: %v\n%s", opts.Package, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// writeProgram renders a main package from a template into a new temporary
+// directory and returns the directory, which the caller removes. The program
+// must live inside the module so its imports resolve; a dot-directory keeps
+// it out of ./... patterns while it exists.
+func writeProgram(name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var src bytes.Buffer
+	if err := tmpl.Execute(&src, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(".", ".spec-to-godemode-"+name+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write %s program: %w", name, err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+)
+
+// introspectToSpecFile connects to a running MCP server, describes it as an
+// MCP spec and writes that spec to specPath
+func introspectToSpecFile(command, url, specPath string) error {
+	target := command
+	if target == "" {
+		target = url
+	}
+	fmt.Printf("Introspecting MCP server: %s\n", target)
+
+	c, err := client.Connect(command, url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MCP server: %w", err)
+	}
+	defer c.Close()
+
+	mcpSpec, err := client.Introspect(c)
+	if err != nil {
+		return fmt.Errorf("failed to introspect MCP server: %w", err)
+	}
+
+	data, err := json.MarshalIndent(mcpSpec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP spec: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(specPath), 0755); err != nil {
+		return fmt.Errorf("failed to create spec directory: %w", err)
+	}
+	if err := os.WriteFile(specPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write MCP spec: %w", err)
+	}
+
+	fmt.Printf("Wrote MCP spec with %d tools, %d resources and %d prompts to %s\n",
+		len(mcpSpec.Tools), len(mcpSpec.Resources), len(mcpSpec.Prompts), specPath)
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// lintResult is the JSON output for one linted spec file
+type lintResult struct {
+	File string `json:"file"`
+	spec.LintReport
+}
+
+// runLint implements the lint subcommand and returns the process exit code:
+// 0 when the specs are clean, 1 when any spec has errors (or warnings with
+// -strict) and 2 on usage errors
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	outputFormat := fs.String("format", "text", "Output format: text or json")
+	strict := fs.Bool("strict", false, "Exit non-zero on warnings as well as errors")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: spec-to-godemode lint [-format text|json] [-strict] <spec file>...")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 || (*outputFormat != "text" && *outputFormat != "json") {
+		fs.Usage()
+		return 2
+	}
+
+	failed := false
+	var results []lintResult
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read spec file: %v\n", err)
+			return 2
+		}
+
+		report := spec.Lint(data)
+		errors, warnings := report.Counts()
+		if errors > 0 || (*strict && warnings > 0) {
+			failed = true
+		}
+
+		if *outputFormat == "json" {
+			results = append(results, lintResult{File: path, LintReport: report})
+			continue
+		}
+		fmt.Printf("%s (%s)\n", path, report.Format)
+		fmt.Print(report.String())
+	}
+
+	if *outputFormat == "json" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to marshal lint results: %v\n", err)
+			return 2
+		}
+		fmt.Println(string(data))
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
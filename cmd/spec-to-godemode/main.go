@@ -3,8 +3,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/imran31415/godemode/pkg/codegen"
 	"github.com/imran31415/godemode/pkg/spec"
@@ -13,10 +15,38 @@ import (
 const version = "1.0.0"
 
 func main() {
+	// Subcommands are dispatched before the generator flags are parsed
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "convert":
+			os.Exit(runConvert(os.Args[2:]))
+		case "serve-mcp":
+			os.Exit(runServeMCP(os.Args[2:]))
+		case "conformance":
+			os.Exit(runConformance(os.Args[2:]))
+		}
+	}
+
 	// Define flags
-	specFile := flag.String("spec", "", "Path to MCP or OpenAPI specification file (required)")
+	var specs specList
+	flag.Var(&specs, "spec", "Path to MCP, OpenAPI, GraphQL or protobuf specification file, as [namespace=]path; repeat to merge several specs")
 	outputDir := flag.String("output", "./generated", "Output directory for generated code")
 	packageName := flag.String("package", "tools", "Package name for generated code")
+	impl := flag.String("impl", implStub, "Tool implementation style: stub or mcp-proxy")
+	mcpCommand := flag.String("mcp-command", "", "MCP server command line that mcp-proxy tools forward to")
+	mcpURL := flag.String("mcp-url", "", "MCP server HTTP URL that mcp-proxy tools forward to")
+	graphqlEndpoint := flag.String("graphql-endpoint", "", "GraphQL endpoint that tools generated from a GraphQL schema POST to")
+	grpcTarget := flag.String("grpc-target", "", "gRPC server address that tools generated from protobuf services dial")
+	fromMCP := flag.String("from-mcp", "", "Introspect the MCP server started by this command line instead of reading -spec")
+	fromMCPURL := flag.String("from-mcp-url", "", "Introspect the MCP server at this HTTP URL instead of reading -spec")
+	genTests := flag.Bool("tests", false, "Also generate registry_test.go with per-tool fixtures")
+	check := flag.Bool("check", false, "Report tools added, removed or changed since the last generation without writing files")
+	force := flag.Bool("force", false, "Overwrite a hand-written tools.go with generated mcp-proxy, GraphQL or gRPC implementations")
+	specOut := flag.String("spec-out", "", "Where to write the introspected MCP spec (default: <output>/mcp-spec.json)")
 	showVersion := flag.Bool("version", false, "Show version and exit")
 	help := flag.Bool("help", false, "Show help message")
 
@@ -34,15 +64,67 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Introspect a running server into a spec file when requested
+	if *fromMCP != "" || *fromMCPURL != "" {
+		if len(specs) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: -spec cannot be combined with -from-mcp or -from-mcp-url\n")
+			os.Exit(1)
+		}
+
+		specPath := *specOut
+		if specPath == "" {
+			specPath = filepath.Join(*outputDir, "mcp-spec.json")
+		}
+		if err := introspectToSpecFile(*fromMCP, *fromMCPURL, specPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		specs = specList{specPath}
+
+		// Proxy tools default to the server that was introspected
+		if *mcpCommand == "" && *mcpURL == "" {
+			*mcpCommand = *fromMCP
+			*mcpURL = *fromMCPURL
+		}
+	}
+
 	// Validate required flags
-	if *specFile == "" {
-		fmt.Fprintf(os.Stderr, "Error: -spec flag is required\n\n")
+	if len(specs) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: -spec, -from-mcp or -from-mcp-url is required\n\n")
 		printHelp()
 		os.Exit(1)
 	}
 
+	opts := generateOptions{
+		outputDir:   *outputDir,
+		packageName: *packageName,
+		impl:        *impl,
+		tests:       *genTests,
+		force:       *force,
+		graphqlURL:  *graphqlEndpoint,
+		grpcTarget:  *grpcTarget,
+		upstream: codegen.ProxyUpstream{
+			Command: *mcpCommand,
+			URL:     *mcpURL,
+		},
+	}
+
+	// Only report what changed since the last generation
+	if *check {
+		diff, err := checkSpecChanges(specs, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(diff.String())
+		if diff.HasChanges() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Run the conversion
-	if err := convertSpecToGoDeMode(*specFile, *outputDir, *packageName); err != nil {
+	if err := convertSpecToGoDeMode(specs, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -50,20 +132,42 @@ func main() {
 	fmt.Printf("✓ Successfully generated GoDeMode code in %s\n", *outputDir)
 }
 
-func convertSpecToGoDeMode(specFile, outputDir, packageName string) error {
+// Supported tool implementation styles
+const (
+	implStub     = "stub"
+	implMCPProxy = "mcp-proxy"
+)
+
+// progress receives messages about loaded specs; convert redirects it to stderr
+var progress io.Writer = os.Stdout
+
+// generateOptions controls how generated code is written
+type generateOptions struct {
+	outputDir   string
+	packageName string
+	impl        string
+	tests       bool
+	force       bool // overwrite a hand-written tools.go with generated implementations
+	graphqlURL  string
+	grpcTarget  string
+	upstream    codegen.ProxyUpstream
+}
+
+// loadToolDefinitions reads a spec file and converts it to tool definitions
+func loadToolDefinitions(specFile string, opts generateOptions) (spec.SpecFormat, []spec.ToolDefinition, error) {
 	// Read the spec file
 	data, err := os.ReadFile(specFile)
 	if err != nil {
-		return fmt.Errorf("failed to read spec file: %w", err)
+		return spec.FormatUnknown, nil, fmt.Errorf("failed to read spec file: %w", err)
 	}
 
 	// Detect spec format
 	format := spec.DetectSpecFormat(data)
 	if format == spec.FormatUnknown {
-		return fmt.Errorf("unknown spec format - file must be MCP or OpenAPI specification")
+		return spec.FormatUnknown, nil, fmt.Errorf("unknown spec format - file must be an MCP, OpenAPI, OpenRPC, GraphQL or protobuf specification, or a list of OpenAI functions or Anthropic tools")
 	}
 
-	fmt.Printf("Detected spec format: %s\n", format)
+	fmt.Fprintf(progress, "Detected spec format: %s\n", format)
 
 	// Parse the spec based on format
 	var tools []spec.ToolDefinition
@@ -71,32 +175,174 @@ func convertSpecToGoDeMode(specFile, outputDir, packageName string) error {
 	case spec.FormatMCP:
 		mcpSpec, err := spec.ParseMCPSpecFromBytes(data)
 		if err != nil {
-			return fmt.Errorf("failed to parse MCP spec: %w", err)
+			return spec.FormatUnknown, nil, fmt.Errorf("failed to parse MCP spec: %w", err)
 		}
 
 		// Validate the spec
 		if err := mcpSpec.Validate(); err != nil {
-			return fmt.Errorf("invalid MCP spec: %w", err)
+			return spec.FormatUnknown, nil, fmt.Errorf("invalid MCP spec: %w", err)
 		}
 
 		tools = mcpSpec.ToToolDefinitions()
-		fmt.Printf("Parsed %d tools from MCP spec '%s'\n", len(tools), mcpSpec.Name)
+		fmt.Fprintf(progress, "Parsed %d tools from MCP spec '%s'\n", len(tools), mcpSpec.Name)
 
 	case spec.FormatOpenAPI:
+		if opts.impl == implMCPProxy {
+			return spec.FormatUnknown, nil, fmt.Errorf("-impl=%s requires an MCP spec", implMCPProxy)
+		}
+
 		openAPISpec, err := spec.ParseOpenAPISpecFromBytes(data)
 		if err != nil {
-			return fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+			return spec.FormatUnknown, nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
 		}
 
 		tools = openAPISpec.ToToolDefinitions()
-		fmt.Printf("Parsed %d tools from OpenAPI spec '%s'\n", len(tools), openAPISpec.Info.Title)
+		fmt.Fprintf(progress, "Parsed %d tools from OpenAPI spec '%s'\n", len(tools), openAPISpec.Info.Title)
+
+	case spec.FormatOpenAI, spec.FormatAnthropic:
+		if opts.impl == implMCPProxy {
+			return spec.FormatUnknown, nil, fmt.Errorf("-impl=%s requires an MCP spec", implMCPProxy)
+		}
+
+		functionSpec, err := spec.ParseFunctionSpecFromBytes(data)
+		if err != nil {
+			return spec.FormatUnknown, nil, err
+		}
+		if err := functionSpec.Validate(); err != nil {
+			return spec.FormatUnknown, nil, fmt.Errorf("invalid %s tools: %w", format, err)
+		}
+
+		tools = functionSpec.ToToolDefinitions()
+		fmt.Fprintf(progress, "Parsed %d tools from %s function definitions\n", len(tools), format)
+
+	case spec.FormatOpenRPC:
+		if opts.impl == implMCPProxy {
+			return spec.FormatUnknown, nil, fmt.Errorf("-impl=%s requires an MCP spec", implMCPProxy)
+		}
+
+		openRPCSpec, err := spec.ParseOpenRPCSpecFromBytes(data)
+		if err != nil {
+			return spec.FormatUnknown, nil, err
+		}
+
+		tools = openRPCSpec.ToToolDefinitions()
+		fmt.Fprintf(progress, "Parsed %d tools from OpenRPC spec '%s'\n", len(tools), openRPCSpec.Info.Title)
+
+	case spec.FormatGraphQL:
+		if opts.impl != implStub {
+			return spec.FormatUnknown, nil, fmt.Errorf("-impl=%s requires an MCP spec", opts.impl)
+		}
+
+		schema, err := spec.ParseGraphQLSpecFromBytes(data)
+		if err != nil {
+			return spec.FormatUnknown, nil, err
+		}
+
+		tools = schema.ToToolDefinitions()
+		fmt.Fprintf(progress, "Parsed %d tools from GraphQL schema\n", len(tools))
+
+	case spec.FormatProtobuf:
+		if opts.impl != implStub {
+			return spec.FormatUnknown, nil, fmt.Errorf("-impl=%s requires an MCP spec", opts.impl)
+		}
+
+		// Parse from the path so that imports resolve relative to the file
+		protoSpec, err := spec.ParseProtobufSpec(specFile)
+		if err != nil {
+			return spec.FormatUnknown, nil, err
+		}
+
+		tools = protoSpec.ToToolDefinitions()
+		fmt.Fprintf(progress, "Parsed %d tools from protobuf services\n", len(tools))
 
 	default:
-		return fmt.Errorf("unsupported spec format: %s", format)
+		return spec.FormatUnknown, nil, fmt.Errorf("unsupported spec format: %s", format)
 	}
 
 	if len(tools) == 0 {
-		return fmt.Errorf("no tools found in specification")
+		return format, nil, fmt.Errorf("no tools found in specification")
+	}
+
+	return format, tools, nil
+}
+
+// checkSpecChanges compares the specs with the manifest of the last generation
+func checkSpecChanges(specs []string, opts generateOptions) (codegen.ManifestDiff, error) {
+	format, tools, err := loadSpecs(specs, opts)
+	if err != nil {
+		return codegen.ManifestDiff{}, err
+	}
+
+	previous, err := codegen.LoadManifest(filepath.Join(opts.outputDir, codegen.ManifestFileName))
+	if err != nil {
+		return codegen.ManifestDiff{}, fmt.Errorf("no previous generation found in %s: %w", opts.outputDir, err)
+	}
+
+	return codegen.DiffManifests(previous, codegen.NewManifest(format, tools)), nil
+}
+
+// loadDescriptorSet reads a protobuf spec as the FileDescriptorSet that
+// generated gRPC tools embed
+func loadDescriptorSet(specFile string) ([]byte, error) {
+	protoSpec, err := spec.ParseProtobufSpec(specFile)
+	if err != nil {
+		return nil, err
+	}
+	return protoSpec.DescriptorSet()
+}
+
+// isGeneratedFile reports whether Go source carries the standard
+// "// Code generated ... DO NOT EDIT." header
+func isGeneratedFile(src []byte) bool {
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+		if strings.HasPrefix(line, "// Code generated ") && strings.HasSuffix(line, " DO NOT EDIT.") {
+			return true
+		}
+	}
+	return false
+}
+
+func convertSpecToGoDeMode(specs []string, opts generateOptions) error {
+	outputDir := opts.outputDir
+
+	switch opts.impl {
+	case implStub:
+	case implMCPProxy:
+		if (opts.upstream.Command == "") == (opts.upstream.URL == "") {
+			return fmt.Errorf("-impl=%s requires exactly one of -mcp-command or -mcp-url", implMCPProxy)
+		}
+	default:
+		return fmt.Errorf("unknown -impl %q (expected %s or %s)", opts.impl, implStub, implMCPProxy)
+	}
+
+	if opts.tests && opts.impl != implStub {
+		return fmt.Errorf("-tests is only supported with -impl=%s", implStub)
+	}
+
+	format, tools, err := loadSpecs(specs, opts)
+	if err != nil {
+		return err
+	}
+	if opts.tests && (format == spec.FormatGraphQL || format == spec.FormatProtobuf) {
+		return fmt.Errorf("-tests is not supported for %s specs", format)
+	}
+
+	// Proxy, GraphQL and gRPC implementations are fully generated, while stub
+	// implementations belong to the user once written and are only extended.
+	// A tools.go without the generated header holds such implementations, so
+	// it is not replaced unless forced, and nothing is written.
+	toolsPath := filepath.Join(outputDir, "tools.go")
+	existingTools, err := os.ReadFile(toolsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read tools.go: %w", err)
+	}
+	fullyGenerated := opts.impl == implMCPProxy || format == spec.FormatGraphQL || format == spec.FormatProtobuf
+	if fullyGenerated && existingTools != nil && !isGeneratedFile(existingTools) && !opts.force {
+		return fmt.Errorf("%s has hand-written implementations that generated ones would replace; move it aside or pass -force to overwrite it", toolsPath)
 	}
 
 	// Create output directory
@@ -105,7 +351,7 @@ func convertSpecToGoDeMode(specFile, outputDir, packageName string) error {
 	}
 
 	// Generate code
-	gen := codegen.NewCodeGenerator(packageName)
+	gen := codegen.NewCodeGenerator(opts.packageName)
 
 	// Generate registry.go
 	fmt.Println("Generating registry.go...")
@@ -120,17 +366,76 @@ func convertSpecToGoDeMode(specFile, outputDir, packageName string) error {
 	}
 
 	// Generate tools.go
-	fmt.Println("Generating tools.go...")
-	toolsCode, err := gen.GenerateToolsFile(tools)
+	var toolsCode string
+	switch {
+	case opts.impl == implMCPProxy:
+		fmt.Println("Generating tools.go...")
+		toolsCode, err = gen.GenerateMCPProxyToolsFile(tools, opts.upstream)
+	case format == spec.FormatGraphQL:
+		fmt.Println("Generating tools.go...")
+		toolsCode, err = gen.GenerateGraphQLToolsFile(tools, opts.graphqlURL)
+	case format == spec.FormatProtobuf:
+		fmt.Println("Generating tools.go...")
+		var descriptors []byte
+		if descriptors, err = loadDescriptorSet(parseSpecSource(specs[0]).path); err == nil {
+			toolsCode, err = gen.GenerateGRPCToolsFile(tools, descriptors, opts.grpcTarget)
+		}
+		if err == nil {
+			fmt.Println("  tools.go imports google.golang.org/grpc; run 'go get google.golang.org/grpc' in the module it belongs to")
+		}
+	case existingTools != nil:
+		fmt.Println("Updating tools.go (existing implementations are preserved)...")
+		var added []string
+		toolsCode, added, err = gen.MergeToolsFile(string(existingTools), tools)
+		for _, name := range added {
+			fmt.Printf("  + stub added for %s\n", name)
+		}
+	default:
+		fmt.Println("Generating tools.go...")
+		toolsCode, err = gen.GenerateToolsFile(tools)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to generate tools: %w", err)
 	}
 
-	toolsPath := filepath.Join(outputDir, "tools.go")
 	if err := os.WriteFile(toolsPath, []byte(toolsCode), 0644); err != nil {
 		return fmt.Errorf("failed to write tools.go: %w", err)
 	}
 
+	// Generate context.go for specs with resources or prompts
+	var contextPath string
+	resources, prompts, err := loadContextDefinitions(specs)
+	if err != nil {
+		return err
+	}
+	if len(resources) > 0 || len(prompts) > 0 {
+		fmt.Printf("Generating context.go (%d resources, %d prompts)...\n", len(resources), len(prompts))
+		contextCode, err := gen.GenerateContextFile(resources, prompts, opts.impl == implMCPProxy)
+		if err != nil {
+			return fmt.Errorf("failed to generate context: %w", err)
+		}
+
+		contextPath = filepath.Join(outputDir, "context.go")
+		if err := os.WriteFile(contextPath, []byte(contextCode), 0644); err != nil {
+			return fmt.Errorf("failed to write context.go: %w", err)
+		}
+	}
+
+	// Generate registry_test.go
+	var testPath string
+	if opts.tests {
+		fmt.Println("Generating registry_test.go...")
+		testCode, err := gen.GenerateRegistryTest(tools)
+		if err != nil {
+			return fmt.Errorf("failed to generate tests: %w", err)
+		}
+
+		testPath = filepath.Join(outputDir, "registry_test.go")
+		if err := os.WriteFile(testPath, []byte(testCode), 0644); err != nil {
+			return fmt.Errorf("failed to write registry_test.go: %w", err)
+		}
+	}
+
 	// Generate README.md
 	fmt.Println("Generating README.md...")
 	readme := gen.GenerateREADME(tools, format)
@@ -139,29 +444,86 @@ func convertSpecToGoDeMode(specFile, outputDir, packageName string) error {
 		return fmt.Errorf("failed to write README.md: %w", err)
 	}
 
+	// Record what was generated and report changes since the last run
+	manifest := codegen.NewManifest(format, tools)
+	manifestPath := filepath.Join(outputDir, codegen.ManifestFileName)
+	if previous, err := codegen.LoadManifest(manifestPath); err == nil {
+		diff := codegen.DiffManifests(previous, manifest)
+		fmt.Printf("\nChanges since last generation:\n%s", diff.String())
+		if len(diff.Removed) > 0 {
+			fmt.Println("Implementations of removed tools are left in tools.go and can be deleted by hand.")
+		}
+	}
+
+	manifestData, err := manifest.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(manifestPath, manifestData, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", codegen.ManifestFileName, err)
+	}
+
 	fmt.Printf("\nGenerated files:\n")
 	fmt.Printf("  - %s\n", registryPath)
 	fmt.Printf("  - %s\n", toolsPath)
 	fmt.Printf("  - %s\n", readmePath)
+	if contextPath != "" {
+		fmt.Printf("  - %s\n", contextPath)
+	}
+	if testPath != "" {
+		fmt.Printf("  - %s\n", testPath)
+	}
+	fmt.Printf("  - %s\n", manifestPath)
 
 	return nil
 }
 
 func printHelp() {
-	fmt.Println("spec-to-godemode - Convert MCP or OpenAPI specs to GoDeMode tool registry")
+	fmt.Println("spec-to-godemode - Convert MCP, OpenAPI, OpenRPC, GraphQL, protobuf or function-calling specs to GoDeMode tool registry")
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  spec-to-godemode -spec <file> [options]")
+	fmt.Println("  spec-to-godemode -spec <namespace>=<file> -spec <namespace>=<file> ... [options]")
+	fmt.Println("  spec-to-godemode -from-mcp <command> | -from-mcp-url <url> [options]")
+	fmt.Println("  spec-to-godemode lint [-format text|json] [-strict] <file>...")
+	fmt.Println("  spec-to-godemode export -registry <import path> [-constructor <expr>] [-format <format>] [-o <file>]")
+	fmt.Println("  spec-to-godemode convert -format mcp|openapi|openrpc|openai|anthropic [-o <file>] <file>...")
+	fmt.Println("  spec-to-godemode serve-mcp -registry <import path> [-constructor <expr>] [-http <addr>]")
+	fmt.Println("  spec-to-godemode conformance -mcp-command <command> | -mcp-url <url> [-call <tool> [-args <json>]]")
 	fmt.Println()
-	fmt.Println("Required Flags:")
+	fmt.Println("Spec Source (one required):")
 	fmt.Println("  -spec string")
-	fmt.Println("        Path to MCP or OpenAPI specification file")
+	fmt.Println("        Path to MCP, OpenAPI, OpenRPC, GraphQL, protobuf, OpenAI functions or Anthropic tools file.")
+	fmt.Println("        Repeat as -spec <namespace>=<file>")
+	fmt.Println("        to merge several specs into one registry with namespaced tool names")
+	fmt.Println("  -from-mcp string")
+	fmt.Println("        Introspect the MCP server started by this command line")
+	fmt.Println("  -from-mcp-url string")
+	fmt.Println("        Introspect the MCP server at this HTTP URL")
 	fmt.Println()
 	fmt.Println("Optional Flags:")
 	fmt.Println("  -output string")
 	fmt.Println("        Output directory for generated code (default: ./generated)")
 	fmt.Println("  -package string")
 	fmt.Println("        Package name for generated code (default: tools)")
+	fmt.Println("  -impl string")
+	fmt.Println("        Tool implementation style: stub or mcp-proxy (default: stub)")
+	fmt.Println("  -mcp-command string")
+	fmt.Println("        MCP server command line that mcp-proxy tools forward to")
+	fmt.Println("  -mcp-url string")
+	fmt.Println("        MCP server HTTP URL that mcp-proxy tools forward to")
+	fmt.Println("  -graphql-endpoint string")
+	fmt.Println("        Endpoint that tools generated from a GraphQL schema POST to (also settable at runtime)")
+	fmt.Println("  -grpc-target string")
+	fmt.Println("        Address that tools generated from protobuf services dial (also settable at runtime)")
+	fmt.Println("  -tests")
+	fmt.Println("        Also generate registry_test.go with fixtures synthesised from each tool's schema")
+	fmt.Println("  -check")
+	fmt.Println("        Report tools added, removed or changed since the last generation and exit non-zero on changes")
+	fmt.Println("  -force")
+	fmt.Println("        Overwrite a hand-written tools.go with generated mcp-proxy, GraphQL or gRPC implementations")
+	fmt.Println("  -spec-out string")
+	fmt.Println("        Where to write an introspected MCP spec (default: <output>/mcp-spec.json)")
 	fmt.Println("  -version")
 	fmt.Println("        Show version and exit")
 	fmt.Println("  -help")
@@ -174,6 +536,39 @@ func printHelp() {
 	fmt.Println("  # Generate from OpenAPI spec with custom output")
 	fmt.Println("  spec-to-godemode -spec api-spec.json -output ./mytools -package mytools")
 	fmt.Println()
+	fmt.Println("  # Merge several specs into one namespaced registry")
+	fmt.Println("  spec-to-godemode -spec github=github-mcp.json -spec sqlite=sqlite-openapi.json -output ./tools")
+	fmt.Println()
+	fmt.Println("  # Generate tools that call a GraphQL API from its SDL or introspection result")
+	fmt.Println("  spec-to-godemode -spec schema.graphql -graphql-endpoint https://api.example.com/graphql")
+	fmt.Println()
+	fmt.Println("  # Generate tools that invoke the RPCs of a gRPC service")
+	fmt.Println("  spec-to-godemode -spec users.proto -grpc-target localhost:50051")
+	fmt.Println()
+	fmt.Println("  # Generate tools that forward to a live MCP server")
+	fmt.Println("  spec-to-godemode -spec mcp-server.json -impl=mcp-proxy -mcp-command \"npx some-server\"")
+	fmt.Println()
+	fmt.Println("  # Check whether a spec changed since the code was generated")
+	fmt.Println("  spec-to-godemode -spec mcp-server.json -check")
+	fmt.Println()
+	fmt.Println("  # Lint a spec before generating, failing on errors")
+	fmt.Println("  spec-to-godemode lint -format json mcp-server.json")
+	fmt.Println()
+	fmt.Println("  # Export a Go registry as an OpenAPI document (run inside its module)")
+	fmt.Println("  spec-to-godemode export -registry github.com/me/app/tools -format openapi -o openapi.json")
+	fmt.Println()
+	fmt.Println("  # Convert OpenAI function definitions to Anthropic tools")
+	fmt.Println("  spec-to-godemode convert -format anthropic -o anthropic-tools.json openai-tools.json")
+	fmt.Println()
+	fmt.Println("  # Serve a Go registry to MCP clients as an execute_go tool (run inside its module)")
+	fmt.Println("  spec-to-godemode serve-mcp -registry github.com/me/app/tools")
+	fmt.Println()
+	fmt.Println("  # Check an MCP server against the protocol, failing on violations")
+	fmt.Println("  spec-to-godemode conformance -mcp-url http://localhost:8080/mcp -call add -args '{\"a\": 1, \"b\": 2}'")
+	fmt.Println()
+	fmt.Println("  # Regenerate bindings from a running MCP server")
+	fmt.Println("  spec-to-godemode -from-mcp \"npx some-server\" -impl=mcp-proxy")
+	fmt.Println()
 	fmt.Println("  # Show version")
 	fmt.Println("  spec-to-godemode -version")
 }
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// serveMainTemplate is a throwaway program serving the registry in code
+// mode. Like the export program it is built inside the caller's module.
+const serveMainTemplate = `package main
+
+import (
+{{- if not .Addr}}
+	"context"
+{{- end}}
+	"log"
+{{- if .Addr}}
+	"net/http"
+{{- end}}
+	"os"
+
+	served {{printf "%q" .Package}}
+	"github.com/imran31415/godemode/pkg/mcp/codemode"
+	"github.com/imran31415/godemode/pkg/mcp/server"
+)
+
+func main() {
+	provider, err := codemode.NewProvider(served.{{.Constructor}}, codemode.Options{
+		Timeout:        {{printf "%d" .Timeout}},
+		DisableListAPI: {{not .ListAPI}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := server.New(provider, server.Options{
+		Name:    {{printf "%q" .Name}},
+		Version: {{printf "%q" .Version}},
+		Logger:  log.New(os.Stderr, "[serve-mcp] ", log.LstdFlags),
+	})
+{{if .Addr}}
+	http.Handle("/mcp", srv)
+	log.Printf("Serving %s on http://%s/mcp", {{printf "%q" .Package}}, {{printf "%q" .Addr}})
+	log.Fatal(http.ListenAndServe({{printf "%q" .Addr}}, nil))
+{{- else}}
+	if err := srv.ServeStdio(context.Background(), os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+{{- end}}
+}
+`
+
+// serveOptions configures the serve-mcp program
+type serveOptions struct {
+	Package     string
+	Constructor string
+	Name        string
+	Version     string
+	Timeout     time.Duration
+	ListAPI     bool
+	Addr        string
+}
+
+// runServeMCP implements the serve-mcp subcommand and returns the process
+// exit code: 0 when the server exits cleanly, 1 when it cannot be built or
+// fails and 2 on usage errors
+func runServeMCP(args []string) int {
+	fs := flag.NewFlagSet("serve-mcp", flag.ContinueOnError)
+	registryPkg := fs.String("registry", "", "Import path of the package declaring the registry (required)")
+	constructor := fs.String("constructor", "NewRegistry()", "Expression in that package building the registry")
+	name := fs.String("name", "", "Server name reported to clients (default: last element of the import path)")
+	serverVersion := fs.String("version", "1.0.0", "Server version reported to clients")
+	timeout := fs.Duration("timeout", 30*time.Second, "Maximum run time of one execute_go call")
+	listAPI := fs.Bool("list-api", true, "Also serve list_api, which documents the registry's tools")
+	addr := fs.String("http", "", "Serve HTTP on this address (e.g. :8080) instead of stdio")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: spec-to-godemode serve-mcp -registry <import path> [-constructor <expr>] [-http <addr>]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *registryPkg == "" || fs.NArg() > 0 || *timeout <= 0 {
+		fs.Usage()
+		return 2
+	}
+	if *name == "" {
+		*name = path.Base(*registryPkg) + "-code-mode"
+	}
+
+	if err := serveRegistry(serveOptions{
+		Package:     *registryPkg,
+		Constructor: *constructor,
+		Name:        *name,
+		Version:     *serverVersion,
+		Timeout:     *timeout,
+		ListAPI:     *listAPI,
+		Addr:        *addr,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// serveRegistry builds the serve-mcp program in the current module and runs
+// it with this process's standard streams, so an MCP client launching
+// spec-to-godemode talks to it directly over stdio
+func serveRegistry(opts serveOptions) error {
+	dir, err := writeProgram("serve-mcp", serveMainTemplate, opts)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Build before running so compiler output never reaches the client and
+	// build failures are reported as such
+	binary := filepath.Join(dir, "serve-mcp")
+	build := exec.Command("go", "build", "-o", binary, "./"+filepath.ToSlash(dir))
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("failed to build server for %s: %w", opts.Package, err)
+	}
+
+	cmd := exec.Command(binary)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start server for %s: %w", opts.Package, err)
+	}
+
+	// Pass interrupts on to the server and wait for it, so the temporary
+	// directory is still removed when the client stops us
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for sig := range signals {
+			cmd.Process.Signal(sig)
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("server for %s failed: %w", opts.Package, err)
+	}
+	return nil
+}
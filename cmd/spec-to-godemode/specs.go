@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// specList collects repeated -spec flags
+type specList []string
+
+func (s *specList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *specList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// specSource is a spec file and the namespace its tools are merged under
+type specSource struct {
+	namespace string
+	path      string
+	explicit  bool // the namespace was given as namespace=path
+}
+
+// parseSpecSource parses a -spec value of the form [namespace=]path. The
+// value is only split when the part before "=" is a valid namespace, so paths
+// such as out/a=b.json are read whole. Without an explicit namespace, the
+// file name without its extension is used.
+func parseSpecSource(value string) specSource {
+	if namespace, path, ok := strings.Cut(value, "="); ok && namespace != "" && !strings.ContainsAny(namespace, "./\\ \t\n") {
+		return specSource{namespace: namespace, path: path, explicit: true}
+	}
+
+	base := filepath.Base(value)
+	return specSource{
+		namespace: strings.TrimSuffix(base, filepath.Ext(base)),
+		path:      value,
+	}
+}
+
+// loadSpecs reads one or more specs. A single spec without an explicit
+// namespace keeps its tool names as they are; otherwise every spec's tools are
+// namespaced and merged into one list, failing on name conflicts.
+func loadSpecs(specs []string, opts generateOptions) (spec.SpecFormat, []spec.ToolDefinition, error) {
+	if len(specs) == 1 {
+		source := parseSpecSource(specs[0])
+		if !source.explicit {
+			return loadToolDefinitions(specs[0], opts)
+		}
+		// Proxy tools forward their own names, which the upstream server
+		// would not recognise with a namespace prefix
+		if opts.impl == implMCPProxy {
+			return spec.FormatUnknown, nil, fmt.Errorf("-impl=%s cannot put tools under namespace %q; pass -spec %s without it", implMCPProxy, source.namespace, source.path)
+		}
+	}
+
+	if len(specs) > 1 && opts.impl == implMCPProxy {
+		return spec.FormatUnknown, nil, fmt.Errorf("-impl=%s supports a single spec", implMCPProxy)
+	}
+
+	sets := make([]spec.ToolSet, 0, len(specs))
+	for _, value := range specs {
+		source := parseSpecSource(value)
+		format, tools, err := loadToolDefinitions(source.path, opts)
+		if err != nil {
+			return spec.FormatUnknown, nil, fmt.Errorf("%s: %w", source.path, err)
+		}
+		if len(specs) > 1 && format == spec.FormatGraphQL {
+			return spec.FormatUnknown, nil, fmt.Errorf("%s: GraphQL schemas cannot be merged with other specs", source.path)
+		}
+		if len(specs) > 1 && format == spec.FormatProtobuf {
+			return spec.FormatUnknown, nil, fmt.Errorf("%s: protobuf services cannot be merged with other specs", source.path)
+		}
+		sets = append(sets, spec.ToolSet{
+			Namespace: source.namespace,
+			Source:    source.path,
+			Format:    format,
+			Tools:     tools,
+		})
+	}
+
+	tools, err := spec.MergeToolSets(sets)
+	if err != nil {
+		return spec.FormatUnknown, nil, err
+	}
+
+	if len(sets) == 1 {
+		fmt.Fprintf(progress, "Namespaced %d tools under %s\n", len(tools), sets[0].Namespace)
+	} else {
+		fmt.Fprintf(progress, "Merged %d tools from %d specs\n", len(tools), len(sets))
+	}
+	return spec.MergedFormat(sets), tools, nil
+}
+
+// loadContextDefinitions reads the resources and prompts of the MCP specs
+// among the given specs. When several specs are merged, resource and prompt
+// names are namespaced like tool names; URIs are left unchanged.
+func loadContextDefinitions(specs []string) ([]spec.ResourceDefinition, []spec.PromptDefinition, error) {
+	namespaced := len(specs) > 1 || parseSpecSource(specs[0]).explicit
+
+	var resources []spec.ResourceDefinition
+	var prompts []spec.PromptDefinition
+	for _, value := range specs {
+		source := specSource{path: value}
+		if namespaced {
+			source = parseSpecSource(value)
+		}
+
+		data, err := os.ReadFile(source.path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read spec file: %w", err)
+		}
+		if spec.DetectSpecFormat(data) != spec.FormatMCP {
+			continue
+		}
+		mcpSpec, err := spec.ParseMCPSpecFromBytes(data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		prefix := ""
+		if namespaced {
+			prefix = source.namespace + "."
+		}
+		for _, resource := range mcpSpec.ToResourceDefinitions() {
+			resource.Name = prefix + resource.Name
+			resources = append(resources, resource)
+		}
+		for _, prompt := range mcpSpec.ToPromptDefinitions() {
+			prompt.Name = prefix + prompt.Name
+			prompts = append(prompts, prompt)
+		}
+	}
+
+	return resources, prompts, nil
+}
@@ -20,4 +20,5 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/tetratelabs/wazero v1.9.0 // indirect
 	github.com/traefik/yaegi v0.16.1 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
 )
@@ -1,166 +1,119 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	dataprocessing "github.com/imran31415/godemode/mcp-benchmark/data-processing"
+	mcpserver "github.com/imran31415/godemode/pkg/mcp/server"
+	"github.com/imran31415/godemode/pkg/spec"
 )
 
-type MCPRequest struct {
-	JSONRPC string                 `json:"jsonrpc"`
-	ID      int                    `json:"id"`
-	Method  string                 `json:"method"`
-	Params  map[string]interface{} `json:"params"`
-}
-
-type MCPResponse struct {
-	JSONRPC string      `json:"jsonrpc"`
-	ID      int         `json:"id"`
-	Result  interface{} `json:"result,omitempty"`
-	Error   *MCPError   `json:"error,omitempty"`
-}
-
-type MCPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
+// MCP Server
 type MCPServer struct {
 	registry *dataprocessing.Registry
+	server   *mcpserver.Server
 }
 
 func NewMCPServer() *MCPServer {
-	return &MCPServer{
+	s := &MCPServer{
 		registry: dataprocessing.NewRegistry(),
 	}
+	s.server = mcpserver.New(s, mcpserver.Options{
+		Name:    "data-processing-mcp-server",
+		Version: "1.0.0",
+		Logger:  log.New(os.Stderr, "[MCP Server] ", log.LstdFlags),
+	})
+	return s
 }
 
+// Handle MCP requests
 func (s *MCPServer) handleRequest(w http.ResponseWriter, r *http.Request) {
+	// Add latency tracking header
 	startTime := time.Now()
 
-	var req MCPRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("[Data MCP Server] Received: %s with params: %v", req.Method, req.Params)
-
-	var response MCPResponse
-	response.JSONRPC = "2.0"
-	response.ID = req.ID
-
-	switch req.Method {
-	case "tools/list":
-		response.Result = s.listTools()
-
-	case "tools/call":
-		toolName, ok := req.Params["name"].(string)
-		if !ok {
-			response.Error = &MCPError{Code: -32602, Message: "Invalid params: name required"}
-			break
-		}
-
-		args, ok := req.Params["arguments"].(map[string]interface{})
-		if !ok {
-			args = make(map[string]interface{})
-		}
-
-		result, err := s.registry.Call(toolName, args)
-		if err != nil {
-			response.Error = &MCPError{Code: -32000, Message: err.Error()}
-		} else {
-			response.Result = result
-		}
-
-	default:
-		response.Error = &MCPError{Code: -32601, Message: "Method not found"}
-	}
+	response := s.server.HandleMessage(r.Context(), body)
 
+	// Add timing header
 	w.Header().Set("X-MCP-Duration-Ms", fmt.Sprintf("%.2f", time.Since(startTime).Seconds()*1000))
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.Write(response)
+
+	log.Printf("[MCP Server] Responded in %v", time.Since(startTime))
+}
 
-	log.Printf("[Data MCP Server] Responded in %v", time.Since(startTime))
+// CallTool runs a tool of the registry for the MCP server
+func (s *MCPServer) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	return s.registry.Call(name, args)
 }
 
-func (s *MCPServer) listTools() interface{} {
-	return map[string]interface{}{
-		"tools": []map[string]interface{}{
-			{
-				"name":        "filterArray",
-				"description": "Filter an array based on a condition",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"data":      map[string]interface{}{"type": "array", "description": "Array of numbers to filter"},
-						"operation": map[string]interface{}{"type": "string", "description": "Operation: gt, lt, eq, gte, lte"},
-						"value":     map[string]interface{}{"type": "number", "description": "Value to compare against"},
-					},
-					"required": []string{"data", "operation", "value"},
-				},
+// Tools describes the tools of the registry for the MCP server
+func (s *MCPServer) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{
+		{
+			Name:        "filterArray",
+			Description: "Filter an array based on a condition",
+			Parameters: []spec.Parameter{
+				{Name: "data", Type: "[]interface{}", Description: "Array of numbers to filter", Required: true},
+				{Name: "operation", Type: "string", Description: "Operation: gt, lt, eq, gte, lte", Required: true},
+				{Name: "value", Type: "float64", Description: "Value to compare against", Required: true},
 			},
-			{
-				"name":        "mapArray",
-				"description": "Transform each element in an array",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"data":      map[string]interface{}{"type": "array", "description": "Array to transform"},
-						"operation": map[string]interface{}{"type": "string", "description": "Operation: double, square, negate"},
-					},
-					"required": []string{"data", "operation"},
-				},
+		},
+		{
+			Name:        "mapArray",
+			Description: "Transform each element in an array",
+			Parameters: []spec.Parameter{
+				{Name: "data", Type: "[]interface{}", Description: "Array to transform", Required: true},
+				{Name: "operation", Type: "string", Description: "Operation: double, square, negate", Required: true},
 			},
-			{
-				"name":        "reduceArray",
-				"description": "Reduce an array to a single value",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"data":      map[string]interface{}{"type": "array", "description": "Array of numbers"},
-						"operation": map[string]interface{}{"type": "string", "description": "Operation: sum, product, max, min, avg"},
-					},
-					"required": []string{"data", "operation"},
-				},
+		},
+		{
+			Name:        "reduceArray",
+			Description: "Reduce an array to a single value",
+			Parameters: []spec.Parameter{
+				{Name: "data", Type: "[]interface{}", Description: "Array of numbers", Required: true},
+				{Name: "operation", Type: "string", Description: "Operation: sum, product, max, min, avg", Required: true},
 			},
-			{
-				"name":        "sortArray",
-				"description": "Sort an array",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"data":  map[string]interface{}{"type": "array", "description": "Array to sort"},
-						"order": map[string]interface{}{"type": "string", "description": "Order: asc or desc"},
-					},
-					"required": []string{"data", "order"},
-				},
+		},
+		{
+			Name:        "sortArray",
+			Description: "Sort an array",
+			Parameters: []spec.Parameter{
+				{Name: "data", Type: "[]interface{}", Description: "Array to sort", Required: true},
+				{Name: "order", Type: "string", Description: "Order: asc or desc", Required: true},
 			},
-			{
-				"name":        "mergeArrays",
-				"description": "Merge multiple arrays",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"arrays": map[string]interface{}{"type": "array", "description": "Arrays to merge"},
-					},
-					"required": []string{"arrays"},
-				},
+		},
+		{
+			Name:        "mergeArrays",
+			Description: "Merge multiple arrays",
+			Parameters: []spec.Parameter{
+				{Name: "arrays", Type: "[]interface{}", Description: "Arrays to merge", Required: true},
 			},
-			{
-				"name":        "uniqueValues",
-				"description": "Get unique values from an array",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"data": map[string]interface{}{"type": "array", "description": "Array with potential duplicates"},
-					},
-					"required": []string{"data"},
-				},
+		},
+		{
+			Name:        "uniqueValues",
+			Description: "Get unique values from an array",
+			Parameters: []spec.Parameter{
+				{Name: "data", Type: "[]interface{}", Description: "Array with potential duplicates", Required: true},
 			},
 		},
 	}
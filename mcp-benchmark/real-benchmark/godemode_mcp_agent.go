@@ -12,6 +12,8 @@ import (
 	"time"
 
 	utilitytools "github.com/imran31415/godemode/mcp-benchmark/godemode"
+	"github.com/imran31415/godemode/pkg/codegen"
+	"github.com/imran31415/godemode/pkg/spec"
 )
 
 // GoDeMode MCP Agent - uses Claude to generate code that uses the tool registry
@@ -76,40 +78,17 @@ func (a *GoDeModeMCPAgent) getToolDescriptions() (string, error) {
 		return "", err
 	}
 
-	var desc strings.Builder
-	desc.WriteString("Available tools in the registry:\n\n")
-
+	defs := make([]spec.ToolDefinition, 0, len(tools))
 	for _, tool := range tools {
-		desc.WriteString(fmt.Sprintf("Tool: %s\n", tool.Name))
-		desc.WriteString(fmt.Sprintf("Description: %s\n", tool.Description))
-
-		// Format parameters
-		if props, ok := tool.InputSchema["properties"].(map[string]interface{}); ok {
-			desc.WriteString("Parameters:\n")
-			for paramName, paramInfo := range props {
-				if paramMap, ok := paramInfo.(map[string]interface{}); ok {
-					paramType := paramMap["type"]
-					paramDesc := paramMap["description"]
-					desc.WriteString(fmt.Sprintf("  - %s (%v): %v\n", paramName, paramType, paramDesc))
-				}
-			}
-		}
-
-		// Required parameters
-		if required, ok := tool.InputSchema["required"].([]interface{}); ok {
-			desc.WriteString("Required: ")
-			reqStrs := make([]string, len(required))
-			for i, r := range required {
-				reqStrs[i] = r.(string)
-			}
-			desc.WriteString(strings.Join(reqStrs, ", "))
-			desc.WriteString("\n")
+		def, err := spec.ToolDefinitionFromSchema(tool.Name, tool.Description, tool.InputSchema)
+		if err != nil {
+			return "", err
 		}
-
-		desc.WriteString("\n")
+		defs = append(defs, def)
 	}
 
-	return desc.String(), nil
+	docs := codegen.RenderAPIDocs(defs, codegen.DocOptions{Verbosity: codegen.VerbosityFull})
+	return "Available tools in the registry:\n\n" + docs, nil
 }
 
 // BenchmarkResult tracks metrics for the GoDeMode approach
@@ -78,8 +78,14 @@ func (c *MCPClient) CallTool(toolName string, args map[string]interface{}) (inte
 	defer resp.Body.Close()
 
 	var result struct {
-		Result interface{}            `json:"result"`
-		Error  map[string]interface{} `json:"error"`
+		Result struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error map[string]interface{} `json:"error"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -89,8 +95,22 @@ func (c *MCPClient) CallTool(toolName string, args map[string]interface{}) (inte
 	if result.Error != nil {
 		return nil, fmt.Errorf("MCP error: %v", result.Error)
 	}
+	if len(result.Result.Content) == 0 {
+		return nil, nil
+	}
 
-	return result.Result, nil
+	text := result.Result.Content[0].Text
+	if result.Result.IsError {
+		return nil, fmt.Errorf("MCP tool error: %s", text)
+	}
+
+	// Tool results are sent as JSON text; decode them so they are passed on
+	// to Claude as values rather than quoted strings
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return text, nil
+	}
+	return value, nil
 }
 
 // Native MCP Agent - uses Claude to orchestrate sequential tool calls
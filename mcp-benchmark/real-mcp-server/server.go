@@ -1,44 +1,35 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	utilitytools "github.com/imran31415/godemode/mcp-benchmark/godemode"
+	mcpserver "github.com/imran31415/godemode/pkg/mcp/server"
+	"github.com/imran31415/godemode/pkg/spec"
 )
 
-// MCP JSON-RPC request/response types
-type MCPRequest struct {
-	JSONRPC string                 `json:"jsonrpc"`
-	ID      int                    `json:"id"`
-	Method  string                 `json:"method"`
-	Params  map[string]interface{} `json:"params"`
-}
-
-type MCPResponse struct {
-	JSONRPC string      `json:"jsonrpc"`
-	ID      int         `json:"id"`
-	Result  interface{} `json:"result,omitempty"`
-	Error   *MCPError   `json:"error,omitempty"`
-}
-
-type MCPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
 // MCP Server
 type MCPServer struct {
 	registry *utilitytools.Registry
+	server   *mcpserver.Server
 }
 
 func NewMCPServer() *MCPServer {
-	return &MCPServer{
+	s := &MCPServer{
 		registry: utilitytools.NewRegistry(),
 	}
+	s.server = mcpserver.New(s, mcpserver.Options{
+		Name:    "utility-mcp-server",
+		Version: "1.0.0",
+		Logger:  log.New(os.Stderr, "[MCP Server] ", log.LstdFlags),
+	})
+	return s
 }
 
 // Handle MCP requests
@@ -46,106 +37,67 @@ func (s *MCPServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 	// Add latency tracking header
 	startTime := time.Now()
 
-	var req MCPRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("[MCP Server] Received: %s with params: %v", req.Method, req.Params)
-
-	var response MCPResponse
-	response.JSONRPC = "2.0"
-	response.ID = req.ID
-
-	switch req.Method {
-	case "tools/list":
-		response.Result = s.listTools()
-
-	case "tools/call":
-		toolName, ok := req.Params["name"].(string)
-		if !ok {
-			response.Error = &MCPError{Code: -32602, Message: "Invalid params: name required"}
-			break
-		}
-
-		args, ok := req.Params["arguments"].(map[string]interface{})
-		if !ok {
-			args = make(map[string]interface{})
-		}
-
-		result, err := s.registry.Call(toolName, args)
-		if err != nil {
-			response.Error = &MCPError{Code: -32000, Message: err.Error()}
-		} else {
-			response.Result = result
-		}
-
-	default:
-		response.Error = &MCPError{Code: -32601, Message: "Method not found"}
-	}
+	response := s.server.HandleMessage(r.Context(), body)
 
 	// Add timing header
 	w.Header().Set("X-MCP-Duration-Ms", fmt.Sprintf("%.2f", time.Since(startTime).Seconds()*1000))
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.Write(response)
 
 	log.Printf("[MCP Server] Responded in %v", time.Since(startTime))
 }
 
-func (s *MCPServer) listTools() interface{} {
-	return map[string]interface{}{
-		"tools": []map[string]interface{}{
-			{
-				"name":        "add",
-				"description": "Add two numbers together",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"a": map[string]interface{}{"type": "number", "description": "First number"},
-						"b": map[string]interface{}{"type": "number", "description": "Second number"},
-					},
-					"required": []string{"a", "b"},
-				},
-			},
-			{
-				"name":        "getCurrentTime",
-				"description": "Get the current time in RFC3339 format",
-				"inputSchema": map[string]interface{}{
-					"type":       "object",
-					"properties": map[string]interface{}{},
-				},
-			},
-			{
-				"name":        "generateUUID",
-				"description": "Generate a new UUID",
-				"inputSchema": map[string]interface{}{
-					"type":       "object",
-					"properties": map[string]interface{}{},
-				},
+// CallTool runs a tool of the registry for the MCP server
+func (s *MCPServer) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	return s.registry.Call(name, args)
+}
+
+// Tools describes the tools of the registry for the MCP server
+func (s *MCPServer) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{
+		{
+			Name:        "add",
+			Description: "Add two numbers together",
+			Parameters: []spec.Parameter{
+				{Name: "a", Type: "float64", Description: "First number", Required: true},
+				{Name: "b", Type: "float64", Description: "Second number", Required: true},
 			},
-			{
-				"name":        "concatenateStrings",
-				"description": "Concatenate an array of strings with a separator",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"strings":   map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
-						"separator": map[string]interface{}{"type": "string", "description": "Separator between strings"},
-					},
-					"required": []string{"strings"},
-				},
+		},
+		{
+			Name:        "getCurrentTime",
+			Description: "Get the current time in RFC3339 format",
+		},
+		{
+			Name:        "generateUUID",
+			Description: "Generate a new UUID",
+		},
+		{
+			Name:        "concatenateStrings",
+			Description: "Concatenate an array of strings with a separator",
+			Parameters: []spec.Parameter{
+				{Name: "strings", Type: "[]string", Required: true},
+				{Name: "separator", Type: "string", Description: "Separator between strings"},
 			},
-			{
-				"name":        "reverseString",
-				"description": "Reverse a string",
-				"inputSchema": map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"text": map[string]interface{}{"type": "string", "description": "Text to reverse"},
-					},
-					"required": []string{"text"},
-				},
+		},
+		{
+			Name:        "reverseString",
+			Description: "Reverse a string",
+			Parameters: []spec.Parameter{
+				{Name: "text", Type: "string", Description: "Text to reverse", Required: true},
 			},
 		},
 	}
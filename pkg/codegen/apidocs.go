@@ -0,0 +1,196 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// Verbosity selects how much detail RenderAPIDocs includes per tool
+type Verbosity int
+
+const (
+	// VerbositySignatures renders one Go-style signature line per tool
+	VerbositySignatures Verbosity = iota
+	// VerbosityCompact adds the first sentence of each tool's description
+	VerbosityCompact
+	// VerbosityFull adds full descriptions, parameter docs and an example call
+	VerbosityFull
+)
+
+// DocOptions controls RenderAPIDocs
+type DocOptions struct {
+	Verbosity Verbosity
+	// TokenBudget is the approximate maximum size of the output in tokens.
+	// Zero means unlimited.
+	TokenBudget int
+}
+
+// docDetail is one step of detail used when fitting docs into a token budget
+type docDetail struct {
+	description string // "", "short" or "full"
+	paramDocs   bool
+	examples    bool
+}
+
+// detailLevels lists the detail steps from most to least, in the order
+// detail is dropped to meet a token budget
+var detailLevels = []docDetail{
+	{description: "full", paramDocs: true, examples: true},
+	{description: "full", paramDocs: true},
+	{description: "short"},
+	{},
+}
+
+// RenderAPIDocs renders tools as a compact, prompt-ready API reference with
+// Go-style signatures, for example:
+//
+//	sendEmail(to string, subject string, priority? "low"|"high" = "low") // Send an email
+//
+// Optional parameters are marked with "?", enums are listed in place of the
+// type and defaults follow "=". When the output exceeds opts.TokenBudget,
+// examples, parameter docs and descriptions are dropped in that order, and
+// as a last resort tools at the end of the list are omitted, so callers
+// should order tools by priority.
+func RenderAPIDocs(tools []spec.ToolDefinition, opts DocOptions) string {
+	start := 0
+	switch opts.Verbosity {
+	case VerbosityCompact:
+		start = 2
+	case VerbositySignatures:
+		start = 3
+	}
+
+	var doc string
+	for _, detail := range detailLevels[start:] {
+		doc = renderTools(tools, detail)
+		if opts.TokenBudget <= 0 || EstimateTokens(doc) <= opts.TokenBudget {
+			return doc
+		}
+	}
+
+	// Even bare signatures are over budget: keep as many tools as fit
+	var sb strings.Builder
+	for i, tool := range tools {
+		entry := renderTool(tool, docDetail{})
+		omitted := fmt.Sprintf("// ... %d more tools omitted\n", len(tools)-i)
+		if EstimateTokens(sb.String()+entry+omitted) > opts.TokenBudget {
+			sb.WriteString(omitted)
+			break
+		}
+		sb.WriteString(entry)
+	}
+	return sb.String()
+}
+
+// EstimateTokens approximates the number of LLM tokens in s, assuming about
+// four characters per token
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// renderTools renders every tool at the given level of detail
+func renderTools(tools []spec.ToolDefinition, detail docDetail) string {
+	var sb strings.Builder
+	for i, tool := range tools {
+		if i > 0 && (detail.paramDocs || detail.examples) {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(renderTool(tool, detail))
+	}
+	return sb.String()
+}
+
+// renderTool renders a single tool at the given level of detail
+func renderTool(tool spec.ToolDefinition, detail docDetail) string {
+	var sb strings.Builder
+
+	params := make([]string, len(tool.Parameters))
+	for i, param := range tool.Parameters {
+		params[i] = renderParam(param)
+	}
+	sb.WriteString(fmt.Sprintf("%s(%s)", tool.Name, strings.Join(params, ", ")))
+
+	description := strings.TrimSpace(tool.Description)
+	switch {
+	case description == "" || detail.description == "":
+		sb.WriteString("\n")
+	case detail.description == "short":
+		sb.WriteString(fmt.Sprintf(" // %s\n", firstSentence(description)))
+	default:
+		sb.WriteString("\n")
+		for _, line := range strings.Split(description, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				sb.WriteString(fmt.Sprintf("  %s\n", line))
+			}
+		}
+	}
+
+	if detail.paramDocs {
+		for _, param := range tool.Parameters {
+			if param.Description != "" {
+				sb.WriteString(fmt.Sprintf("  %s: %s\n", param.Name, firstSentence(param.Description)))
+			}
+		}
+	}
+
+	if detail.examples {
+		example := make(map[string]interface{})
+		for _, param := range tool.Parameters {
+			if param.Required {
+				example[param.Name] = ExampleValue(param)
+			}
+		}
+		if data, err := json.Marshal(example); err == nil {
+			sb.WriteString(fmt.Sprintf("  e.g. %s(%s)\n", tool.Name, data))
+		}
+	}
+
+	return sb.String()
+}
+
+// renderParam renders a parameter as it appears in a signature
+func renderParam(param spec.Parameter) string {
+	name := param.Name
+	if !param.Required {
+		name += "?"
+	}
+
+	typ := strings.ReplaceAll(mapTypeToGo(param.Type), "interface{}", "any")
+	if len(param.Enum) > 0 {
+		values := make([]string, len(param.Enum))
+		for i, v := range param.Enum {
+			values[i] = jsonLiteral(v)
+		}
+		typ = strings.Join(values, "|")
+	}
+
+	rendered := name + " " + typ
+	if param.Default != nil {
+		rendered += " = " + jsonLiteral(param.Default)
+	}
+	return rendered
+}
+
+// jsonLiteral renders a spec value as compact JSON
+func jsonLiteral(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// firstSentence returns the first sentence or line of a description
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i+1]
+	}
+	return strings.TrimSpace(s)
+}
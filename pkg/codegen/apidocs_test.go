@@ -0,0 +1,98 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func apiDocsTestTools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{
+		{
+			Name:        "sendEmail",
+			Description: "Send an email. Delivery is asynchronous.",
+			Parameters: []spec.Parameter{
+				{Name: "to", Type: "string", Required: true, Description: "Recipient address", Format: "email"},
+				{Name: "priority", Type: "string", Enum: []interface{}{"low", "high"}, Default: "low"},
+			},
+		},
+		{
+			Name:        "listEmails",
+			Description: "List emails in a folder",
+			Parameters:  []spec.Parameter{{Name: "limit", Type: "integer"}},
+		},
+	}
+}
+
+func TestRenderAPIDocsVerbosity(t *testing.T) {
+	tools := apiDocsTestTools()
+
+	signatures := RenderAPIDocs(tools, DocOptions{Verbosity: VerbositySignatures})
+	expected := "sendEmail(to string, priority? \"low\"|\"high\" = \"low\")\nlistEmails(limit? int)\n"
+	if signatures != expected {
+		t.Errorf("Unexpected signatures:\n%s", signatures)
+	}
+
+	compact := RenderAPIDocs(tools, DocOptions{Verbosity: VerbosityCompact})
+	if !strings.Contains(compact, `= "low") // Send an email.`+"\n") {
+		t.Errorf("Compact docs should include the first sentence of the description:\n%s", compact)
+	}
+	if strings.Contains(compact, "asynchronous") {
+		t.Error("Compact docs should drop the rest of the description")
+	}
+
+	full := RenderAPIDocs(tools, DocOptions{Verbosity: VerbosityFull})
+	for _, exp := range []string{
+		"  Send an email. Delivery is asynchronous.\n",
+		"  to: Recipient address\n",
+		`  e.g. sendEmail({"to":"user@example.com"})`,
+	} {
+		if !strings.Contains(full, exp) {
+			t.Errorf("Full docs should contain %q:\n%s", exp, full)
+		}
+	}
+}
+
+func TestRenderAPIDocsTokenBudget(t *testing.T) {
+	tools := apiDocsTestTools()
+
+	full := RenderAPIDocs(tools, DocOptions{Verbosity: VerbosityFull})
+	signatures := RenderAPIDocs(tools, DocOptions{Verbosity: VerbositySignatures})
+
+	// A budget that only fits signatures drops every other detail
+	budgeted := RenderAPIDocs(tools, DocOptions{Verbosity: VerbosityFull, TokenBudget: EstimateTokens(signatures)})
+	if budgeted != signatures {
+		t.Errorf("Expected docs to degrade to signatures, got:\n%s", budgeted)
+	}
+	if EstimateTokens(full) <= EstimateTokens(signatures) {
+		t.Error("Full docs should be larger than signatures")
+	}
+
+	// A tiny budget omits tools from the end of the list
+	var many []spec.ToolDefinition
+	for i := 0; i < 10; i++ {
+		many = append(many, spec.ToolDefinition{Name: fmt.Sprintf("tool%d", i), Parameters: []spec.Parameter{{Name: "limit", Type: "integer"}}})
+	}
+	tiny := RenderAPIDocs(many, DocOptions{TokenBudget: 20})
+	if !strings.HasPrefix(tiny, "tool0(limit? int)\ntool1(") || !strings.Contains(tiny, "// ... 8 more tools omitted") {
+		t.Errorf("Expected trailing tools to be omitted, got:\n%s", tiny)
+	}
+	if EstimateTokens(tiny) > 20 {
+		t.Errorf("Docs exceed the token budget: %d tokens", EstimateTokens(tiny))
+	}
+}
+
+func TestGenerateRegistryEmbedsAPIDocs(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+
+	code, err := gen.GenerateRegistry(apiDocsTestTools())
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+
+	if !strings.Contains(code, `const APIDocs = "sendEmail(to string, priority? \"low\"|\"high\" = \"low\") // Send an email.\nlistEmails(limit? int) // List emails in a folder\n"`) {
+		t.Error("Registry should embed compact API docs")
+	}
+}
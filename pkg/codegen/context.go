@@ -0,0 +1,215 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// GenerateContextFile generates a context.go file exposing a spec's resources
+// and prompts through the registry: ReadResource and GetPrompt, a typed
+// Read<Resource> accessor per resource (taking template variables as
+// arguments) and a <Prompt>Prompt builder per prompt. With proxy set, reads
+// and renders are forwarded to the upstream server of the proxy tools;
+// otherwise handlers are installed with SetResourceHandler and SetPromptHandler.
+func (g *CodeGenerator) GenerateContextFile(resources []spec.ResourceDefinition, prompts []spec.PromptDefinition, proxy bool) (string, error) {
+	tmpl, err := template.New("context").Parse(contextTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	resourceLiterals := make([]string, len(resources))
+	for i, resource := range resources {
+		resourceLiterals[i] = resourceInfoLiteral(resource)
+	}
+	promptLiterals := make([]string, len(prompts))
+	for i, prompt := range prompts {
+		promptLiterals[i] = promptInfoLiteral(prompt)
+	}
+
+	var accessors []string
+	resourceNames := make([]string, len(resources))
+	for i, resource := range resources {
+		resourceNames[i] = resource.Name
+	}
+	// ReadResource is already a Registry method
+	accessorNames := exportedIdentifiers(resourceNames, map[string]bool{"Resource": true})
+	for _, resource := range resources {
+		accessors = append(accessors, resourceAccessor(resource, "Read"+accessorNames[resource.Name]))
+	}
+
+	promptNames := make([]string, len(prompts))
+	for i, prompt := range prompts {
+		promptNames[i] = prompt.Name
+	}
+	// GetPrompt is already a Registry method
+	builderNames := exportedIdentifiers(promptNames, map[string]bool{"Get": true})
+	for _, prompt := range prompts {
+		accessors = append(accessors, promptBuilder(prompt, builderNames[prompt.Name]+"Prompt"))
+	}
+
+	data := struct {
+		PackageName string
+		Proxy       bool
+		Resources   []string
+		Prompts     []string
+		Accessors   []string
+	}{
+		PackageName: g.packageName,
+		Proxy:       proxy,
+		Resources:   resourceLiterals,
+		Prompts:     promptLiterals,
+		Accessors:   accessors,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	// Format the generated code
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+// resourceInfoLiteral renders a resource as a ResourceInfo composite literal
+func resourceInfoLiteral(resource spec.ResourceDefinition) string {
+	literal := fmt.Sprintf("{Name: %s, Description: %s, MimeType: %s, URI: %s",
+		strconv.Quote(resource.Name), strconv.Quote(resource.Description),
+		strconv.Quote(resource.MimeType), strconv.Quote(resource.URI))
+	if resource.Templated() {
+		literal += fmt.Sprintf(", Variables: %s", goLiteral(resource.Variables))
+	}
+	return literal + "}"
+}
+
+// promptInfoLiteral renders a prompt as a PromptInfo composite literal
+func promptInfoLiteral(prompt spec.PromptDefinition) string {
+	args := make([]string, len(prompt.Arguments))
+	for i, arg := range prompt.Arguments {
+		args[i] = paramInfoLiteral(arg)
+	}
+	return fmt.Sprintf("{Name: %s, Description: %s, Arguments: []ParamInfo{%s}}",
+		strconv.Quote(prompt.Name), strconv.Quote(prompt.Description), strings.Join(args, ", "))
+}
+
+// resourceAccessor renders a typed Registry method reading one resource
+func resourceAccessor(resource spec.ResourceDefinition, method string) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf("// %s reads the %s resource\n", method, strconv.Quote(resource.Name)))
+	if !resource.Templated() {
+		buf.WriteString(fmt.Sprintf("func (r *Registry) %s() (string, error) {\n", method))
+		buf.WriteString(fmt.Sprintf("\treturn r.ReadResource(%s)\n", strconv.Quote(resource.URI)))
+		buf.WriteString("}\n")
+		return buf.String()
+	}
+
+	vars := resolveIdentifiers(resource.Variables, reservedSet(predeclaredIdentifiers, generatedLocalIdentifiers, []string{"r", "url"}))
+	params := make([]string, len(resource.Variables))
+	values := make([]string, len(resource.Variables))
+	for i, name := range resource.Variables {
+		params[i] = vars[name] + " string"
+		values[i] = fmt.Sprintf("%s: %s", strconv.Quote(name), vars[name])
+	}
+
+	buf.WriteString(fmt.Sprintf("func (r *Registry) %s(%s) (string, error) {\n", method, strings.Join(params, ", ")))
+	buf.WriteString(fmt.Sprintf("\treturn r.ReadResource(expandURITemplate(%s, map[string]string{%s}))\n",
+		strconv.Quote(resource.URI), strings.Join(values, ", ")))
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// promptBuilder renders an argument struct and a typed Registry method
+// rendering one prompt
+func promptBuilder(prompt spec.PromptDefinition, method string) string {
+	var buf bytes.Buffer
+	argsType := method + "Args"
+
+	names := make([]string, len(prompt.Arguments))
+	for i, arg := range prompt.Arguments {
+		names[i] = arg.Name
+	}
+	fields := exportedIdentifiers(names, nil)
+
+	buf.WriteString(fmt.Sprintf("// %s are the arguments of the %s prompt\n", argsType, strconv.Quote(prompt.Name)))
+	buf.WriteString(fmt.Sprintf("type %s struct {\n", argsType))
+	for _, arg := range prompt.Arguments {
+		comment := firstSentence(arg.Description)
+		if arg.Required {
+			comment = strings.TrimSpace("(required) " + comment)
+		}
+		if comment != "" {
+			comment = " // " + comment
+		}
+		buf.WriteString(fmt.Sprintf("\t%s string%s\n", fields[arg.Name], comment))
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// %s renders the %s prompt\n", method, strconv.Quote(prompt.Name)))
+	buf.WriteString(fmt.Sprintf("func (r *Registry) %s(args %s) ([]PromptMessage, error) {\n", method, argsType))
+	buf.WriteString("\tvalues := make(map[string]string)\n")
+	for _, arg := range prompt.Arguments {
+		buf.WriteString(fmt.Sprintf("\tif args.%s != \"\" {\n", fields[arg.Name]))
+		buf.WriteString(fmt.Sprintf("\t\tvalues[%s] = args.%s\n", strconv.Quote(arg.Name), fields[arg.Name]))
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString(fmt.Sprintf("\treturn r.GetPrompt(%s, values)\n", strconv.Quote(prompt.Name)))
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// exportedIdentifiers maps each name to a unique exported Go identifier, e.g.
+// "user-profile" and "user_profile" both become UserProfile. Collisions with
+// each other or with the reserved names are resolved with numeric suffixes.
+func exportedIdentifiers(names []string, reserved map[string]bool) map[string]string {
+	resolved := make(map[string]string, len(names))
+	taken := make(map[string]bool, len(names)+len(reserved))
+	for name := range reserved {
+		taken[name] = true
+	}
+
+	for _, name := range names {
+		if _, done := resolved[name]; done {
+			continue
+		}
+
+		var sb strings.Builder
+		upperNext := true
+		for _, r := range name {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				upperNext = true
+				continue
+			}
+			if upperNext {
+				r = unicode.ToUpper(r)
+				upperNext = false
+			}
+			sb.WriteRune(r)
+		}
+
+		base := sb.String()
+		if base == "" || unicode.IsDigit([]rune(base)[0]) {
+			base = "X" + base
+		}
+
+		ident := base
+		for i := 2; taken[ident]; i++ {
+			ident = base + strconv.Itoa(i)
+		}
+		resolved[name] = ident
+		taken[ident] = true
+	}
+
+	return resolved
+}
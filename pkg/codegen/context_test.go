@@ -0,0 +1,133 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func contextTestSpec() ([]spec.ResourceDefinition, []spec.PromptDefinition) {
+	resources := []spec.ResourceDefinition{
+		{Name: "app_config", URI: "config://app", MimeType: "application/json"},
+		{Name: "user-profile", URI: "users://{userId}/profile", Variables: []string{"userId"}},
+		{Name: "resource", URI: "misc://resource"},
+	}
+	prompts := []spec.PromptDefinition{
+		{
+			Name:        "code_review",
+			Description: "Review code",
+			Arguments: []spec.Parameter{
+				{Name: "code", Type: "string", Required: true, Description: "Code to review"},
+				{Name: "language", Type: "string"},
+			},
+		},
+	}
+	return resources, prompts
+}
+
+func TestGenerateContextFile(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+	resources, prompts := contextTestSpec()
+
+	code, err := gen.GenerateContextFile(resources, prompts, false)
+	if err != nil {
+		t.Fatalf("Failed to generate context file: %v", err)
+	}
+
+	expected := []string{
+		"package mytools",
+		`{Name: "user-profile", Description: "", MimeType: "", URI: "users://{userId}/profile", Variables: []string{"userId"}},`,
+		`{Name: "code_review", Description: "Review code", Arguments: []ParamInfo{{Name: "code", Type: "string", Description: "Code to review", Required: true}, {Name: "language", Type: "string", Required: false}}},`,
+		"func (r *Registry) ReadAppConfig() (string, error) {",
+		`return r.ReadResource("config://app")`,
+		"func (r *Registry) ReadUserProfile(userId string) (string, error) {",
+		`return r.ReadResource(expandURITemplate("users://{userId}/profile", map[string]string{"userId": userId}))`,
+		"func (r *Registry) ReadResource2() (string, error) {",
+		"type CodeReviewPromptArgs struct {",
+		"Code     string // (required) Code to review",
+		"func (r *Registry) CodeReviewPrompt(args CodeReviewPromptArgs) ([]PromptMessage, error) {",
+		`values["language"] = args.Language`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(code, exp) {
+			t.Errorf("Generated code should contain: %s", exp)
+		}
+	}
+
+	if strings.Contains(code, "getUpstream") {
+		t.Error("Stub context file should not forward to an upstream server")
+	}
+}
+
+func TestGenerateContextFileProxy(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+	resources, prompts := contextTestSpec()
+
+	code, err := gen.GenerateContextFile(resources, prompts, true)
+	if err != nil {
+		t.Fatalf("Failed to generate context file: %v", err)
+	}
+
+	expected := []string{
+		`"github.com/imran31415/godemode/benchmark/mcp/client"`,
+		"SetResourceHandler(readUpstreamResource)",
+		"provider.GetPrompt(name, args)",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(code, exp) {
+			t.Errorf("Generated code should contain: %s", exp)
+		}
+	}
+}
+
+func TestGeneratedContextMatchesTemplates(t *testing.T) {
+	gen := NewCodeGenerator("testtools")
+	resources, prompts := contextTestSpec()
+
+	registry, err := gen.GenerateRegistry([]spec.ToolDefinition{{Name: "ping", Description: "Ping"}})
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+	context, err := gen.GenerateContextFile(resources, prompts, false)
+	if err != nil {
+		t.Fatalf("Failed to generate context file: %v", err)
+	}
+	if !strings.Contains(context, "uriTemplatePatterns[resource.URI].MatchString(uri)") {
+		t.Error("Resource templates should be matched with patterns compiled once")
+	}
+
+	runGenerated(t, map[string]string{
+		"registry.go": registry,
+		"context.go":  context,
+		"tools.go": `package testtools
+
+func ping(args map[string]interface{}) (interface{}, error) {
+	return "pong", nil
+}
+`,
+		"context_test.go": `package testtools
+
+import "testing"
+
+func TestReadResource(t *testing.T) {
+	SetResourceHandler(func(uri string) (string, error) { return uri, nil })
+	r := NewRegistry()
+
+	for _, uri := range []string{"config://app", "users://42/profile", "users://a%2Fb/profile"} {
+		if got, err := r.ReadResource(uri); err != nil || got != uri {
+			t.Errorf("Expected %s to be read, got %q (%v)", uri, got, err)
+		}
+	}
+	for _, uri := range []string{"config://other", "users://42/x/profile", "users:///profile"} {
+		if _, err := r.ReadResource(uri); err == nil {
+			t.Errorf("Expected %s not to match any resource", uri)
+		}
+	}
+	if len(uriTemplatePatterns) != 1 {
+		t.Errorf("Expected one compiled template pattern, got %d", len(uriTemplatePatterns))
+	}
+}
+`,
+	})
+}
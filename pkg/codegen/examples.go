@@ -0,0 +1,189 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// formatExamples holds representative values for common JSON Schema string formats
+var formatExamples = map[string]string{
+	"email":     "user@example.com",
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "12:00:00",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uuid":      "123e4567-e89b-12d3-a456-426614174000",
+}
+
+// ExampleValue synthesises a representative argument value for a parameter.
+// Enum values win over defaults, which win over values derived from the type
+// and format. The value always has the Go type generated code expects.
+func ExampleValue(param spec.Parameter) interface{} {
+	goType := mapTypeToGo(param.Type)
+
+	if len(param.Enum) > 0 {
+		if v, ok := convertExample(goType, param.Enum[0]); ok {
+			return v
+		}
+	}
+	if param.Default != nil {
+		if v, ok := convertExample(goType, param.Default); ok {
+			return v
+		}
+	}
+
+	switch goType {
+	case "string":
+		if example, ok := formatExamples[param.Format]; ok {
+			return example
+		}
+		return "example-" + param.Name
+	case "int":
+		return 1
+	case "float64":
+		return 1.5
+	case "bool":
+		return true
+	case "map[string]interface{}":
+		return map[string]interface{}{}
+	case "[]string":
+		return []string{"example"}
+	case "[]int":
+		return []int{1}
+	case "[]interface{}":
+		return []interface{}{"example"}
+	default:
+		return "example"
+	}
+}
+
+// WrongTypeValue returns a value that does not match the parameter's type,
+// or false when every value is acceptable
+func WrongTypeValue(param spec.Parameter) (interface{}, bool) {
+	switch goType := mapTypeToGo(param.Type); {
+	case goType == "string":
+		return 12345, true
+	case goType == "int" || goType == "float64":
+		return "not-a-number", true
+	case goType == "bool":
+		return "not-a-bool", true
+	case goType == "map[string]interface{}":
+		return "not-an-object", true
+	case strings.HasPrefix(goType, "[]"):
+		return "not-a-list", true
+	default:
+		return nil, false
+	}
+}
+
+// convertExample converts a JSON-decoded spec value (enum or default) to the given Go type
+func convertExample(goType string, v interface{}) (interface{}, bool) {
+	switch goType {
+	case "string":
+		s, ok := v.(string)
+		return s, ok
+	case "int":
+		switch n := v.(type) {
+		case float64:
+			return int(n), n == float64(int(n))
+		case int:
+			return n, true
+		}
+	case "float64":
+		switch n := v.(type) {
+		case float64:
+			return n, true
+		case int:
+			return float64(n), true
+		}
+	case "bool":
+		b, ok := v.(bool)
+		return b, ok
+	case "map[string]interface{}":
+		m, ok := v.(map[string]interface{})
+		return m, ok
+	case "[]string":
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		strs := make([]string, len(items))
+		for i, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			strs[i] = s
+		}
+		return strs, true
+	case "[]int":
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		ints := make([]int, len(items))
+		for i, item := range items {
+			n, ok := convertExample("int", item)
+			if !ok {
+				return nil, false
+			}
+			ints[i] = n.(int)
+		}
+		return ints, true
+	}
+	return nil, false
+}
+
+// goLiteral renders an example, enum or default value as a Go expression that
+// keeps its dynamic type when stored in an interface{}
+func goLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return strconv.Quote(val)
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return fmt.Sprintf("float64(%s)", strconv.FormatFloat(val, 'g', -1, 64))
+	case bool:
+		return strconv.FormatBool(val)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = fmt.Sprintf("%s: %s", strconv.Quote(k), goLiteral(val[k]))
+		}
+		return "map[string]interface{}{" + strings.Join(parts, ", ") + "}"
+	case []string:
+		parts := make([]string, len(val))
+		for i, s := range val {
+			parts[i] = strconv.Quote(s)
+		}
+		return "[]string{" + strings.Join(parts, ", ") + "}"
+	case []int:
+		parts := make([]string, len(val))
+		for i, n := range val {
+			parts[i] = strconv.Itoa(n)
+		}
+		return "[]int{" + strings.Join(parts, ", ") + "}"
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = goLiteral(item)
+		}
+		return "[]interface{}{" + strings.Join(parts, ", ") + "}"
+	default:
+		return "nil"
+	}
+}
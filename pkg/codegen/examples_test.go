@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func TestExampleValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		param    spec.Parameter
+		expected interface{}
+	}{
+		{"enum wins", spec.Parameter{Name: "status", Type: "string", Enum: []interface{}{"open", "closed"}, Default: "closed"}, "open"},
+		{"default", spec.Parameter{Name: "limit", Type: "int", Default: float64(20)}, 20},
+		{"integer enum", spec.Parameter{Name: "level", Type: "int", Enum: []interface{}{float64(3)}}, 3},
+		{"email format", spec.Parameter{Name: "to", Type: "string", Format: "email"}, "user@example.com"},
+		{"date-time format", spec.Parameter{Name: "since", Type: "string", Format: "date-time"}, "2024-01-01T00:00:00Z"},
+		{"plain string", spec.Parameter{Name: "title", Type: "string"}, "example-title"},
+		{"number", spec.Parameter{Name: "ratio", Type: "float64"}, 1.5},
+		{"boolean", spec.Parameter{Name: "force", Type: "bool"}, true},
+		{"object", spec.Parameter{Name: "data", Type: "map[string]interface{}"}, map[string]interface{}{}},
+		{"string list", spec.Parameter{Name: "tags", Type: "[]string"}, []string{"example"}},
+		{"mismatched default ignored", spec.Parameter{Name: "count", Type: "int", Default: "ten"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExampleValue(tt.param)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ExampleValue() = %#v, want %#v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWrongTypeValue(t *testing.T) {
+	if v, ok := WrongTypeValue(spec.Parameter{Type: "string"}); !ok || v == nil {
+		t.Error("Expected a wrong-type value for string parameters")
+	}
+
+	if _, ok := WrongTypeValue(spec.Parameter{Type: "interface{}"}); ok {
+		t.Error("interface{} parameters accept any value")
+	}
+}
+
+func TestGoLiteral(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{"hi", `"hi"`},
+		{3, "3"},
+		{2.0, "float64(2)"},
+		{true, "true"},
+		{map[string]interface{}{"b": 1, "a": "x"}, `map[string]interface{}{"a": "x", "b": 1}`},
+		{[]string{"a"}, `[]string{"a"}`},
+		{[]interface{}{1.5}, "[]interface{}{float64(1.5)}"},
+	}
+
+	for _, tt := range tests {
+		if result := goLiteral(tt.input); result != tt.expected {
+			t.Errorf("goLiteral(%#v) = %s, want %s", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package codegen
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"go/format"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -24,7 +26,12 @@ func NewCodeGenerator(packageName string) *CodeGenerator {
 
 // GenerateRegistry generates a tool registry file from tool definitions
 func (g *CodeGenerator) GenerateRegistry(tools []spec.ToolDefinition) (string, error) {
-	tmpl, err := template.New("registry").Parse(registryTemplate)
+	funcNames := toolIdentifiers(tools)
+	tmpl, err := template.New("registry").Funcs(template.FuncMap{
+		"paramInfo":   paramInfoLiteral,
+		"annotations": annotationsLiteral,
+		"funcName":    func(name string) string { return funcNames[name] },
+	}).Parse(registryTemplate)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -32,9 +39,11 @@ func (g *CodeGenerator) GenerateRegistry(tools []spec.ToolDefinition) (string, e
 	data := struct {
 		PackageName string
 		Tools       []spec.ToolDefinition
+		APIDocs     string
 	}{
 		PackageName: g.packageName,
 		Tools:       tools,
+		APIDocs:     RenderAPIDocs(tools, DocOptions{Verbosity: VerbosityCompact}),
 	}
 
 	var buf bytes.Buffer
@@ -51,32 +60,96 @@ func (g *CodeGenerator) GenerateRegistry(tools []spec.ToolDefinition) (string, e
 	return string(formatted), nil
 }
 
+// paramInfoLiteral renders a parameter as a ParamInfo composite literal.
+// Enum and default values are converted to the parameter's Go type so that
+// validation compares and applies values of the type tools receive.
+func paramInfoLiteral(param spec.Parameter) string {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("{Name: %s, Type: %s", strconv.Quote(param.Name), strconv.Quote(param.Type)))
+	if param.Description != "" {
+		buf.WriteString(fmt.Sprintf(", Description: %s", strconv.Quote(param.Description)))
+	}
+	buf.WriteString(fmt.Sprintf(", Required: %t", param.Required))
+
+	goType := mapTypeToGo(param.Type)
+	if len(param.Enum) > 0 {
+		values := make([]string, len(param.Enum))
+		for i, v := range param.Enum {
+			if converted, ok := convertExample(goType, v); ok {
+				v = converted
+			}
+			values[i] = goLiteral(v)
+		}
+		buf.WriteString(fmt.Sprintf(", Enum: []interface{}{%s}", strings.Join(values, ", ")))
+	}
+	if param.Default != nil {
+		v := param.Default
+		if converted, ok := convertExample(goType, v); ok {
+			v = converted
+		}
+		buf.WriteString(fmt.Sprintf(", Default: %s", goLiteral(v)))
+	}
+	if param.Format != "" {
+		buf.WriteString(fmt.Sprintf(", Format: %s", strconv.Quote(param.Format)))
+	}
+	if len(param.Properties) > 0 {
+		props := make([]string, len(param.Properties))
+		for i, prop := range param.Properties {
+			props[i] = paramInfoLiteral(prop)
+		}
+		buf.WriteString(fmt.Sprintf(", Properties: []ParamInfo{%s}", strings.Join(props, ", ")))
+	}
+	if len(param.Schema) > 0 {
+		buf.WriteString(fmt.Sprintf(", Schema: %s", goLiteral(param.Schema)))
+	}
+
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// annotationsLiteral renders tool annotations as the map literal of a
+// ToolInfo, keyed by their MCP names
+func annotationsLiteral(annotations *spec.ToolAnnotations) string {
+	data, _ := json.Marshal(annotations)
+	var hints map[string]interface{}
+	json.Unmarshal(data, &hints)
+	return goLiteral(hints)
+}
+
 // GenerateToolImplementation generates stub implementation for a single tool
 func (g *CodeGenerator) GenerateToolImplementation(tool spec.ToolDefinition) string {
+	return g.generateToolImplementation(tool, toolIdentifiers([]spec.ToolDefinition{tool})[tool.Name])
+}
+
+// generateToolImplementation generates the stub for a tool under the given
+// Go function name. Arguments are still looked up by their wire names.
+func (g *CodeGenerator) generateToolImplementation(tool spec.ToolDefinition, funcName string) string {
 	var buf bytes.Buffer
+	vars := paramIdentifiers(tool.Parameters)
 
 	// Generate function signature
-	buf.WriteString(fmt.Sprintf("func %s(args map[string]interface{}) (interface{}, error) {\n", tool.Name))
+	buf.WriteString(fmt.Sprintf("func %s(args map[string]interface{}) (interface{}, error) {\n", funcName))
 
 	// Extract parameters
 	for _, param := range tool.Parameters {
+		name := vars[param.Name]
 		if param.Required {
 			buf.WriteString(fmt.Sprintf("\t// Required parameter: %s (%s)\n", param.Name, param.Type))
-			buf.WriteString(fmt.Sprintf("\t%s, ok := args[\"%s\"]", param.Name, param.Name))
+			buf.WriteString(fmt.Sprintf("\t%s, ok := args[%s]", name, strconv.Quote(param.Name)))
 			buf.WriteString(".(")
 			buf.WriteString(mapTypeToGo(param.Type))
 			buf.WriteString(")\n")
 			buf.WriteString("\tif !ok {\n")
-			buf.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"required parameter '%s' not found or wrong type\")\n", param.Name))
+			buf.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(%s)\n", strconv.Quote(fmt.Sprintf("required parameter '%s' not found or wrong type", param.Name))))
 			buf.WriteString("\t}\n")
-			buf.WriteString(fmt.Sprintf("\t_ = %s // TODO: Use this parameter in your implementation\n\n", param.Name))
+			buf.WriteString(fmt.Sprintf("\t_ = %s // TODO: Use this parameter in your implementation\n\n", name))
 		} else {
 			buf.WriteString(fmt.Sprintf("\t// Optional parameter: %s (%s)\n", param.Name, param.Type))
-			buf.WriteString(fmt.Sprintf("\t%s, _ := args[\"%s\"]", param.Name, param.Name))
+			buf.WriteString(fmt.Sprintf("\t%s, _ := args[%s]", name, strconv.Quote(param.Name)))
 			buf.WriteString(".(")
 			buf.WriteString(mapTypeToGo(param.Type))
 			buf.WriteString(")\n")
-			buf.WriteString(fmt.Sprintf("\t_ = %s // TODO: Use this parameter in your implementation\n\n", param.Name))
+			buf.WriteString(fmt.Sprintf("\t_ = %s // TODO: Use this parameter in your implementation\n\n", name))
 		}
 	}
 
@@ -87,7 +160,7 @@ func (g *CodeGenerator) GenerateToolImplementation(tool spec.ToolDefinition) str
 	// Return placeholder result
 	buf.WriteString("\treturn map[string]interface{}{\n")
 	buf.WriteString("\t\t\"status\": \"success\",\n")
-	buf.WriteString(fmt.Sprintf("\t\t\"message\": \"%s executed\",\n", tool.Name))
+	buf.WriteString(fmt.Sprintf("\t\t\"message\": %s,\n", strconv.Quote(tool.Name+" executed")))
 	buf.WriteString("\t}, nil\n")
 
 	buf.WriteString("}\n")
@@ -103,9 +176,10 @@ func (g *CodeGenerator) GenerateToolsFile(tools []spec.ToolDefinition) (string,
 	}
 
 	// Generate individual tool implementations
+	funcNames := toolIdentifiers(tools)
 	implementations := make([]string, len(tools))
 	for i, tool := range tools {
-		implementations[i] = g.GenerateToolImplementation(tool)
+		implementations[i] = g.generateToolImplementation(tool, funcNames[tool.Name])
 	}
 
 	data := struct {
@@ -130,6 +204,216 @@ func (g *CodeGenerator) GenerateToolsFile(tools []spec.ToolDefinition) (string,
 	return string(formatted), nil
 }
 
+// ProxyUpstream identifies the MCP server that proxy implementations forward to
+type ProxyUpstream struct {
+	Command string // stdio server command line, e.g. "npx some-server"
+	URL     string // HTTP endpoint of the server
+}
+
+// GenerateMCPProxyToolsFile generates a tools.go file whose implementations
+// forward every call to a live MCP server instead of returning stubs
+func (g *CodeGenerator) GenerateMCPProxyToolsFile(tools []spec.ToolDefinition, upstream ProxyUpstream) (string, error) {
+	if (upstream.Command == "") == (upstream.URL == "") {
+		return "", fmt.Errorf("exactly one of upstream command or URL must be set")
+	}
+
+	funcNames := toolIdentifiers(tools)
+	tmpl, err := template.New("mcpProxyTools").Funcs(template.FuncMap{
+		"funcName": func(name string) string { return funcNames[name] },
+	}).Parse(mcpProxyToolsTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := struct {
+		PackageName string
+		Upstream    ProxyUpstream
+		Tools       []spec.ToolDefinition
+	}{
+		PackageName: g.packageName,
+		Upstream:    upstream,
+		Tools:       tools,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	// Format the generated code
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+// GenerateGraphQLToolsFile generates a tools.go file whose implementations
+// POST each tool's GraphQL operation to an endpoint, which can be changed at
+// runtime through the generated GraphQLEndpoint variable
+func (g *CodeGenerator) GenerateGraphQLToolsFile(tools []spec.ToolDefinition, endpoint string) (string, error) {
+	for _, tool := range tools {
+		if tool.GraphQL == nil {
+			return "", fmt.Errorf("tool %s has no GraphQL operation", tool.Name)
+		}
+	}
+
+	funcNames := toolIdentifiers(tools)
+	tmpl, err := template.New("graphqlTools").Funcs(template.FuncMap{
+		"funcName":  func(name string) string { return funcNames[name] },
+		"operation": graphQLOperationLiteral,
+	}).Parse(graphqlToolsTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := struct {
+		PackageName string
+		Endpoint    string
+		Tools       []spec.ToolDefinition
+	}{
+		PackageName: g.packageName,
+		Endpoint:    endpoint,
+		Tools:       tools,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	// Format the generated code
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+// graphQLOperationLiteral renders an operation as a graphQLOperation composite literal
+func graphQLOperationLiteral(op *spec.GraphQLOperation) string {
+	vars := make([]string, len(op.Variables))
+	for i, v := range op.Variables {
+		vars[i] = fmt.Sprintf("{name: %s, typ: %s}", strconv.Quote(v.Name), strconv.Quote(v.Type))
+	}
+
+	return fmt.Sprintf("graphQLOperation{\n\t\toperation: %s,\n\t\tfield: %s,\n\t\tvariables: []graphQLVariable{%s},\n\t\tselection: %s,\n\t\tfieldsParam: %s,\n\t}",
+		strconv.Quote(op.Type), strconv.Quote(op.Field), strings.Join(vars, ", "),
+		strconv.Quote(op.Selection), strconv.Quote(op.FieldsParam))
+}
+
+// GenerateGRPCToolsFile generates a tools.go file whose implementations
+// invoke each tool's RPC with dynamic messages built from descriptors, a
+// serialized FileDescriptorSet that is embedded in the generated code. The
+// connection is dialed to target unless one is set through GRPCConn.
+func (g *CodeGenerator) GenerateGRPCToolsFile(tools []spec.ToolDefinition, descriptors []byte, target string) (string, error) {
+	for _, tool := range tools {
+		if tool.GRPC == nil {
+			return "", fmt.Errorf("tool %s has no gRPC method", tool.Name)
+		}
+	}
+
+	funcNames := toolIdentifiers(tools)
+	tmpl, err := template.New("grpcTools").Funcs(template.FuncMap{
+		"funcName": func(name string) string { return funcNames[name] },
+	}).Parse(grpcToolsTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := struct {
+		PackageName string
+		Target      string
+		Descriptors []byte
+		Tools       []spec.ToolDefinition
+	}{
+		PackageName: g.packageName,
+		Target:      target,
+		Descriptors: descriptors,
+		Tools:       tools,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	// Format the generated code
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+// GenerateRegistryTest generates a registry_test.go file that checks every
+// tool is registered, that Call rejects wrong types and missing required
+// parameters without a default, and that each tool handles arguments
+// synthesised from its schema.
+// The last check fails for a tool until its stub has been implemented.
+func (g *CodeGenerator) GenerateRegistryTest(tools []spec.ToolDefinition) (string, error) {
+	tmpl, err := template.New("registryTest").Parse(registryTestTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	type fixture struct {
+		Name       string
+		Required   string
+		Args       string
+		WrongTypes string
+	}
+
+	fixtures := make([]fixture, len(tools))
+	for i, tool := range tools {
+		var required, args, wrongTypes []string
+		for _, param := range tool.Parameters {
+			key := strconv.Quote(param.Name)
+			args = append(args, fmt.Sprintf("%s: %s", key, goLiteral(ExampleValue(param))))
+
+			// Call fills in the default of a missing parameter, so only
+			// parameters without one must be passed
+			if param.Required && param.Default == nil {
+				required = append(required, key)
+			}
+			if wrong, ok := WrongTypeValue(param); ok {
+				wrongTypes = append(wrongTypes, fmt.Sprintf("%s: %s", key, goLiteral(wrong)))
+			}
+		}
+
+		fixtures[i] = fixture{
+			Name:       tool.Name,
+			Required:   strings.Join(required, ", "),
+			Args:       strings.Join(args, ", "),
+			WrongTypes: strings.Join(wrongTypes, ", "),
+		}
+	}
+
+	data := struct {
+		PackageName string
+		Fixtures    []fixture
+	}{
+		PackageName: g.packageName,
+		Fixtures:    fixtures,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	// Format the generated code
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
 // GenerateTypes generates type definitions from tool definitions
 func (g *CodeGenerator) GenerateTypes(tools []spec.ToolDefinition) (string, error) {
 	var buf bytes.Buffer
@@ -144,13 +428,17 @@ func (g *CodeGenerator) GenerateTypes(tools []spec.ToolDefinition) (string, erro
 	buf.WriteString("\tDescription string\n")
 	buf.WriteString("\tParameters  []ParamInfo\n")
 	buf.WriteString("\tFunction    ToolFunc\n")
+	buf.WriteString("\tNamespace   string\n")
 	buf.WriteString("}\n\n")
 
 	buf.WriteString("// ParamInfo describes a parameter\n")
 	buf.WriteString("type ParamInfo struct {\n")
-	buf.WriteString("\tName     string\n")
-	buf.WriteString("\tType     string\n")
-	buf.WriteString("\tRequired bool\n")
+	buf.WriteString("\tName        string\n")
+	buf.WriteString("\tType        string\n")
+	buf.WriteString("\tDescription string\n")
+	buf.WriteString("\tRequired    bool\n")
+	buf.WriteString("\tEnum        []interface{}\n")
+	buf.WriteString("\tDefault     interface{}\n")
 	buf.WriteString("}\n")
 
 	// Format the generated code
@@ -1,9 +1,19 @@
 package codegen
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/imran31415/godemode/pkg/mcp/server"
 	"github.com/imran31415/godemode/pkg/spec"
 )
 
@@ -355,3 +365,907 @@ func TestGenerateRegistryComplexTypes(t *testing.T) {
 		t.Error("Should generate implementation for tool with complex types")
 	}
 }
+
+func TestGenerateMCPProxyToolsFile(t *testing.T) {
+	gen := NewCodeGenerator("proxytools")
+
+	tools := []spec.ToolDefinition{
+		{
+			Name:        "readFile",
+			Description: "Read a file",
+			Parameters: []spec.Parameter{
+				{Name: "path", Type: "string", Required: true},
+			},
+		},
+	}
+
+	code, err := gen.GenerateMCPProxyToolsFile(tools, ProxyUpstream{Command: "npx some-server"})
+	if err != nil {
+		t.Fatalf("Failed to generate proxy tools file: %v", err)
+	}
+
+	if !strings.Contains(code, `upstreamCommand = "npx some-server"`) {
+		t.Error("Generated code should embed the upstream command")
+	}
+
+	if !strings.Contains(code, "func readFile(args map[string]interface{}) (interface{}, error)") {
+		t.Error("Generated code should contain readFile function")
+	}
+
+	if !strings.Contains(code, `return callUpstream("readFile", args)`) {
+		t.Error("Generated code should forward readFile to the upstream server")
+	}
+
+	if !strings.Contains(code, "client.ResultValue(result)") {
+		t.Error("Generated code should unwrap call results")
+	}
+}
+
+// proxyProvider serves the upstream tools of the generated proxy tests
+type proxyProvider struct{}
+
+func (proxyProvider) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{
+		{Name: "read-file", Parameters: []spec.Parameter{{Name: "path", Type: "string", Required: true}}},
+		{Name: "stat", Parameters: []spec.Parameter{{Name: "path", Type: "string", Required: true}}},
+	}
+}
+
+func (proxyProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	path, _ := args["path"].(string)
+	if path == "/missing" {
+		return nil, errors.New("no such file")
+	}
+	if name == "stat" {
+		return map[string]interface{}{"path": path, "size": 42}, nil
+	}
+	return "contents of " + path, nil
+}
+
+func TestGeneratedMCPProxyForwardsCalls(t *testing.T) {
+	ts := httptest.NewServer(server.New(proxyProvider{}, server.Options{}))
+	defer ts.Close()
+
+	gen := NewCodeGenerator("testtools")
+	tools := proxyProvider{}.Tools()
+
+	registry, err := gen.GenerateRegistry(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+	proxy, err := gen.GenerateMCPProxyToolsFile(tools, ProxyUpstream{URL: ts.URL})
+	if err != nil {
+		t.Fatalf("Failed to generate proxy tools file: %v", err)
+	}
+
+	runGenerated(t, map[string]string{
+		"registry.go": registry,
+		"tools.go":    proxy,
+		"proxy_test.go": `package testtools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForwardsToUpstream(t *testing.T) {
+	defer CloseUpstream()
+	r := NewRegistry()
+
+	got, err := r.Call("read-file", map[string]interface{}{"path": "/etc/motd"})
+	if err != nil || got != "contents of /etc/motd" {
+		t.Errorf("Expected the upstream text result, got %#v (%v)", got, err)
+	}
+
+	got, err = r.Call("stat", map[string]interface{}{"path": "/etc/motd"})
+	if want := map[string]interface{}{"path": "/etc/motd", "size": float64(42)}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the upstream JSON result to be decoded, got %#v (%v)", got, err)
+	}
+
+	if _, err := r.Call("read-file", map[string]interface{}{"path": "/missing"}); err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Errorf("Expected the upstream tool error, got %v", err)
+	}
+}
+`,
+	})
+}
+
+func TestGenerateMCPProxyToolsFileRequiresOneUpstream(t *testing.T) {
+	gen := NewCodeGenerator("proxytools")
+
+	if _, err := gen.GenerateMCPProxyToolsFile(nil, ProxyUpstream{}); err == nil {
+		t.Error("Expected error when no upstream is configured")
+	}
+
+	both := ProxyUpstream{Command: "server", URL: "http://localhost:8080"}
+	if _, err := gen.GenerateMCPProxyToolsFile(nil, both); err == nil {
+		t.Error("Expected error when both command and URL are configured")
+	}
+}
+
+func TestGenerateGraphQLToolsFile(t *testing.T) {
+	gen := NewCodeGenerator("issues")
+
+	tools := []spec.ToolDefinition{
+		{
+			Name:        "issue",
+			Description: "Fetch an issue",
+			Parameters: []spec.Parameter{
+				{Name: "id", Type: "string", Required: true},
+				{Name: "fields", Type: "[]string"},
+			},
+			GraphQL: &spec.GraphQLOperation{
+				Type:        "query",
+				Field:       "issue",
+				Variables:   []spec.GraphQLVariable{{Name: "id", Type: "ID!"}},
+				Selection:   "id title",
+				FieldsParam: "fields",
+			},
+		},
+	}
+
+	code, err := gen.GenerateGraphQLToolsFile(tools, "https://example.com/graphql")
+	if err != nil {
+		t.Fatalf("Failed to generate GraphQL tools file: %v", err)
+	}
+
+	if !strings.Contains(code, `GraphQLEndpoint = "https://example.com/graphql"`) {
+		t.Error("Generated code should embed the endpoint")
+	}
+
+	if !strings.Contains(code, "func issue(args map[string]interface{}) (interface{}, error)") {
+		t.Error("Generated code should contain issue function")
+	}
+
+	if !strings.Contains(code, `variables:   []graphQLVariable{{name: "id", typ: "ID!"}}`) {
+		t.Error("Generated code should declare the operation variables")
+	}
+
+	if !strings.Contains(code, `selection:   "id title"`) {
+		t.Error("Generated code should embed the default selection")
+	}
+
+	if _, err := gen.GenerateGraphQLToolsFile([]spec.ToolDefinition{{Name: "plain"}}, ""); err == nil {
+		t.Error("Expected error for a tool without a GraphQL operation")
+	}
+}
+
+// graphQLEcho is a GraphQL endpoint that answers each root field with the
+// operation, variables and Authorization header it received, and fails
+// operations on the broken field with GraphQL errors
+func graphQLEcho(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if strings.Contains(req.Query, "broken") {
+		fmt.Fprint(w, `{"data":null,"errors":[{"message":"issue not found"},{"message":"try again"}]}`)
+		return
+	}
+	field := strings.Fields(req.Query[strings.Index(req.Query, "{")+1:])[0]
+	field = strings.SplitN(field, "(", 2)[0]
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{
+			field: map[string]interface{}{
+				"query":         req.Query,
+				"variables":     req.Variables,
+				"authorization": r.Header.Get("Authorization"),
+			},
+		},
+	})
+}
+
+func TestGeneratedGraphQLToolsCallEndpoint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(graphQLEcho))
+	defer ts.Close()
+
+	gen := NewCodeGenerator("testtools")
+	tools := []spec.ToolDefinition{
+		{
+			Name: "issue",
+			Parameters: []spec.Parameter{
+				{Name: "id", Type: "string", Required: true},
+				{Name: "fields", Type: "[]string"},
+			},
+			GraphQL: &spec.GraphQLOperation{
+				Type:        "query",
+				Field:       "issue",
+				Variables:   []spec.GraphQLVariable{{Name: "id", Type: "ID!"}},
+				Selection:   "id title",
+				FieldsParam: "fields",
+			},
+		},
+		{
+			Name: "createIssue",
+			Parameters: []spec.Parameter{
+				{Name: "input", Type: "object", Required: true, Properties: []spec.Parameter{
+					{Name: "title", Type: "string", Required: true},
+					{Name: "labels", Type: "[]string"},
+				}},
+			},
+			GraphQL: &spec.GraphQLOperation{
+				Type:      "mutation",
+				Field:     "createIssue",
+				Variables: []spec.GraphQLVariable{{Name: "input", Type: "CreateIssueInput!"}},
+				Selection: "id",
+			},
+		},
+		{
+			Name:    "broken",
+			GraphQL: &spec.GraphQLOperation{Type: "query", Field: "broken"},
+		},
+	}
+
+	registry, err := gen.GenerateRegistry(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+	graphql, err := gen.GenerateGraphQLToolsFile(tools, ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to generate GraphQL tools file: %v", err)
+	}
+
+	runGenerated(t, map[string]string{
+		"registry.go": registry,
+		"tools.go":    graphql,
+		"graphql_test.go": `package testtools
+
+import (
+	"reflect"
+	"testing"
+)
+
+// echoed returns the operation and variables the endpoint received
+func echoed(t *testing.T, name string, args map[string]interface{}) (string, map[string]interface{}) {
+	t.Helper()
+	result, err := NewRegistry().Call(name, args)
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	fields := result.(map[string]interface{})
+	variables, _ := fields["variables"].(map[string]interface{})
+	return fields["query"].(string), variables
+}
+
+func TestQueryWithDefaultSelection(t *testing.T) {
+	query, variables := echoed(t, "issue", map[string]interface{}{"id": "42"})
+	if want := "query($id: ID!) { issue(id: $id) { id title } }"; query != want {
+		t.Errorf("Expected %q, got %q", want, query)
+	}
+	if !reflect.DeepEqual(variables, map[string]interface{}{"id": "42"}) {
+		t.Errorf("Expected the id variable, got %v", variables)
+	}
+}
+
+func TestQueryWithRequestedFields(t *testing.T) {
+	query, _ := echoed(t, "issue", map[string]interface{}{
+		"id":     "42",
+		"fields": []interface{}{"title", "author.name", "author.email"},
+	})
+	if want := "query($id: ID!) { issue(id: $id) { author { email name } title } }"; query != want {
+		t.Errorf("Expected %q, got %q", want, query)
+	}
+
+	if _, err := NewRegistry().Call("issue", map[string]interface{}{"id": "42", "fields": []string{"author.na-me"}}); err == nil {
+		t.Error("Expected an invalid field path to be rejected")
+	}
+}
+
+func TestMutationWithInputObject(t *testing.T) {
+	GraphQLHeaders["Authorization"] = "Bearer secret"
+	defer delete(GraphQLHeaders, "Authorization")
+
+	result, err := NewRegistry().Call("createIssue", map[string]interface{}{
+		"input": map[string]interface{}{"title": "Bug", "labels": []interface{}{"p1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := result.(map[string]interface{})
+	if want := "mutation($input: CreateIssueInput!) { createIssue(input: $input) { id } }"; fields["query"] != want {
+		t.Errorf("Expected %q, got %q", want, fields["query"])
+	}
+	want := map[string]interface{}{"input": map[string]interface{}{"title": "Bug", "labels": []interface{}{"p1"}}}
+	if !reflect.DeepEqual(fields["variables"], want) {
+		t.Errorf("Expected the input variable, got %v", fields["variables"])
+	}
+	if fields["authorization"] != "Bearer secret" {
+		t.Errorf("Expected GraphQLHeaders to be sent, got %v", fields["authorization"])
+	}
+
+	if _, err := NewRegistry().Call("createIssue", map[string]interface{}{"input": map[string]interface{}{}}); err == nil {
+		t.Error("Expected an input without its required title to be rejected")
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	_, err := NewRegistry().Call("broken", map[string]interface{}{})
+	if want := "GraphQL query broken failed: issue not found; try again"; err == nil || err.Error() != want {
+		t.Errorf("Expected %q, got %v", want, err)
+	}
+}
+`,
+	})
+}
+
+func TestGenerateGRPCToolsFile(t *testing.T) {
+	gen := NewCodeGenerator("users")
+
+	tools := []spec.ToolDefinition{
+		{
+			Name:        "GetUser",
+			Description: "Fetch a user",
+			Parameters: []spec.Parameter{
+				{Name: "id", Type: "string"},
+			},
+			GRPC: &spec.GRPCMethod{Service: "users.v1.UserService", Method: "GetUser"},
+		},
+		{
+			Name:        "WatchUsers",
+			Description: "Stream users",
+			GRPC:        &spec.GRPCMethod{Service: "users.v1.UserService", Method: "WatchUsers", ServerStreaming: true},
+		},
+	}
+
+	code, err := gen.GenerateGRPCToolsFile(tools, []byte("\x0a\x03abc"), "localhost:50051")
+	if err != nil {
+		t.Fatalf("Failed to generate gRPC tools file: %v", err)
+	}
+
+	if !strings.Contains(code, `GRPCTarget = "localhost:50051"`) {
+		t.Error("Generated code should embed the target")
+	}
+
+	if !strings.Contains(code, `grpcFileDescriptorSet = []byte("\n\x03abc")`) {
+		t.Error("Generated code should embed the descriptor set")
+	}
+
+	if !strings.Contains(code, `return callGRPC("users.v1.UserService", "GetUser", false, args)`) {
+		t.Error("Generated code should invoke GetUser as a unary RPC")
+	}
+
+	if !strings.Contains(code, `return callGRPC("users.v1.UserService", "WatchUsers", true, args)`) {
+		t.Error("Generated code should invoke WatchUsers as a server-streaming RPC")
+	}
+
+	if _, err := gen.GenerateGRPCToolsFile([]spec.ToolDefinition{{Name: "plain"}}, nil, ""); err == nil {
+		t.Error("Expected error for a tool without a gRPC method")
+	}
+}
+
+func TestGeneratedGRPCToolsInvokeRPCs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiling gRPC is slow; skipped in short mode")
+	}
+	requireGRPC(t)
+
+	protoSpec, err := spec.ParseProtobufSpecFromBytes([]byte(`syntax = "proto3";
+package users.v1;
+
+message GetUserRequest { string id = 1; }
+message WatchUsersRequest { int32 count = 1; }
+message User {
+  string id = 1;
+  string display_name = 2;
+  repeated string roles = 3;
+}
+
+service UserService {
+  rpc GetUser(GetUserRequest) returns (User);
+  rpc WatchUsers(WatchUsersRequest) returns (stream User);
+}
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse proto: %v", err)
+	}
+	descriptors, err := protoSpec.DescriptorSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewCodeGenerator("testtools")
+	tools := protoSpec.ToToolDefinitions()
+
+	registry, err := gen.GenerateRegistry(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+	grpcTools, err := gen.GenerateGRPCToolsFile(tools, descriptors, "")
+	if err != nil {
+		t.Fatalf("Failed to generate gRPC tools file: %v", err)
+	}
+
+	// The server answers with dynamic messages built from the same embedded
+	// descriptors, and the tools dial it through GRPCTarget
+	runGenerated(t, map[string]string{
+		"registry.go": registry,
+		"tools.go":    grpcTools,
+		"grpc_test.go": `package testtools
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// user builds a User response message
+func user(md protoreflect.MethodDescriptor, id string) *dynamicpb.Message {
+	msg := dynamicpb.NewMessage(md.Output())
+	fields := md.Output().Fields()
+	msg.Set(fields.ByName("id"), protoreflect.ValueOfString(id))
+	msg.Set(fields.ByName("display_name"), protoreflect.ValueOfString("User "+id))
+	return msg
+}
+
+// serveUsers implements UserService for any method through the unknown service handler
+func serveUsers(srv interface{}, stream grpc.ServerStream) error {
+	fullMethod, _ := grpc.MethodFromServerStream(stream)
+	parts := strings.Split(strings.TrimPrefix(fullMethod, "/"), "/")
+	md, err := grpcMethod(parts[0], parts[1])
+	if err != nil {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+
+	req := dynamicpb.NewMessage(md.Input())
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+
+	switch md.Name() {
+	case "GetUser":
+		id := req.Get(md.Input().Fields().ByName("id")).String()
+		if id == "missing" {
+			return status.Error(codes.NotFound, "no user "+id)
+		}
+		return stream.SendMsg(user(md, id))
+	case "WatchUsers":
+		count := req.Get(md.Input().Fields().ByName("count")).Int()
+		for i := int64(0); i < count; i++ {
+			if err := stream.SendMsg(user(md, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return status.Error(codes.Unimplemented, fullMethod)
+}
+
+func TestMain(m *testing.M) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(serveUsers))
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	GRPCTarget = lis.Addr().String()
+	m.Run()
+}
+
+func TestUnaryRPC(t *testing.T) {
+	got, err := NewRegistry().Call("GetUser", map[string]interface{}{"id": "7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": "7", "displayName": "User 7", "roles": []interface{}{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestServerStreamingRPC(t *testing.T) {
+	got, err := NewRegistry().Call("WatchUsers", map[string]interface{}{"count": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, ok := got.([]interface{})
+	if !ok || len(users) != 3 {
+		t.Fatalf("Expected three streamed users, got %v", got)
+	}
+	if id := users[2].(map[string]interface{})["id"]; id != "2" {
+		t.Errorf("Expected the responses in order, got %v", users)
+	}
+
+	got, err = NewRegistry().Call("WatchUsers", map[string]interface{}{"count": 0})
+	if err != nil || !reflect.DeepEqual(got, []interface{}{}) {
+		t.Errorf("Expected an empty stream to return an empty list, got %v (%v)", got, err)
+	}
+}
+
+func TestRPCErrors(t *testing.T) {
+	_, err := NewRegistry().Call("GetUser", map[string]interface{}{"id": "missing"})
+	if err == nil || status.Code(err) != codes.NotFound {
+		t.Errorf("Expected the NotFound status, got %v", err)
+	}
+
+	if _, err := callGRPC("users.v1.UserService", "GetUser", false, map[string]interface{}{"nickname": "x"}); err == nil {
+		t.Error("Expected arguments that are not request fields to be rejected")
+	}
+}
+`,
+	})
+}
+
+func TestGenerateRegistryTest(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+
+	tools := []spec.ToolDefinition{
+		{
+			Name:        "sendEmail",
+			Description: "Send an email",
+			Parameters: []spec.Parameter{
+				{Name: "to", Type: "string", Required: true, Format: "email"},
+				{Name: "priority", Type: "int", Enum: []interface{}{float64(1), float64(2)}},
+			},
+		},
+	}
+
+	code, err := gen.GenerateRegistryTest(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry test: %v", err)
+	}
+
+	if !strings.Contains(code, "package mytools") {
+		t.Error("Generated test should contain package declaration")
+	}
+
+	if !strings.Contains(code, `"to": "user@example.com", "priority": 1`) {
+		t.Error("Generated test should contain example args synthesised from the schema")
+	}
+
+	if !strings.Contains(code, `required:   []string{"to"}`) {
+		t.Error("Generated test should list required parameters")
+	}
+
+	if !strings.Contains(code, `wrongTypes: map[string]interface{}{"to": 12345, "priority": "not-a-number"}`) {
+		t.Error("Generated test should contain wrong-type values for every parameter")
+	}
+
+	for _, test := range []string{
+		"func TestRegistryRegistersAllTools(",
+		"func TestCallRejectsMissingRequiredParams(",
+		"func TestCallRejectsWrongTypes(",
+		"func TestToolsWithExampleArgs(",
+	} {
+		if !strings.Contains(code, test) {
+			t.Errorf("Generated test should contain %s", test)
+		}
+	}
+}
+
+func TestGeneratedRegistryTestWithRequiredDefaults(t *testing.T) {
+	gen := NewCodeGenerator("testtools")
+
+	tools := []spec.ToolDefinition{
+		{
+			Name: "search",
+			Parameters: []spec.Parameter{
+				{Name: "query", Type: "string", Required: true},
+				{Name: "limit", Type: "int", Required: true, Default: float64(10)},
+			},
+		},
+	}
+
+	registryTest, err := gen.GenerateRegistryTest(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry test: %v", err)
+	}
+	if !strings.Contains(registryTest, `required:   []string{"query"}`) {
+		t.Error("Generated test should not require parameters that have a default")
+	}
+
+	registry, err := gen.GenerateRegistry(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+
+	runGenerated(t, map[string]string{
+		"registry.go":      registry,
+		"registry_test.go": registryTest,
+		"tools.go": `package testtools
+
+func search(args map[string]interface{}) (interface{}, error) {
+	return args, nil
+}
+`,
+	})
+}
+
+func TestGenerateRegistryValidatesArgs(t *testing.T) {
+	gen := NewCodeGenerator("testtools")
+
+	tools := []spec.ToolDefinition{
+		{
+			Name:        "listItems",
+			Description: "List items",
+			Parameters: []spec.Parameter{
+				{Name: "owner", Type: "string", Required: true},
+				{Name: "status", Type: "string", Enum: []interface{}{"open", "closed"}},
+				{Name: "limit", Type: "int", Default: float64(20)},
+				{Name: "archived", Type: "bool"},
+				{Name: "labels", Type: "[]string", Default: []interface{}{"bug"}},
+				{
+					Name: "filter",
+					Type: "object",
+					Properties: []spec.Parameter{
+						{Name: "since", Type: "string", Required: true},
+						{Name: "depth", Type: "int"},
+					},
+				},
+			},
+		},
+	}
+
+	code, err := gen.GenerateRegistry(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+
+	if !strings.Contains(code, `{Name: "status", Type: "string", Required: false, Enum: []interface{}{"open", "closed"}}`) {
+		t.Error("Generated code should include enum values")
+	}
+
+	if !strings.Contains(code, `{Name: "limit", Type: "int", Required: false, Default: 20}`) {
+		t.Error("Generated code should include defaults converted to the parameter type")
+	}
+
+	if !strings.Contains(code, `{Name: "labels", Type: "[]string", Required: false, Default: []string{"bug"}}`) {
+		t.Error("Generated code should include list defaults with the element type")
+	}
+
+	// The tool echoes the arguments it receives, so the test sees what
+	// validation made of them
+	runGenerated(t, map[string]string{
+		"registry.go": code,
+		"tools.go": `package testtools
+
+func listItems(args map[string]interface{}) (interface{}, error) {
+	return args, nil
+}
+`,
+		"registry_test.go": `package testtools
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func call(t *testing.T, args map[string]interface{}) (map[string]interface{}, error) {
+	t.Helper()
+	result, err := NewRegistry().Call("listItems", args)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]interface{}), nil
+}
+
+func TestCoercionAndDefaults(t *testing.T) {
+	got, err := call(t, map[string]interface{}{"owner": "me", "limit": "5", "archived": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"owner": "me", "limit": 5, "archived": true, "labels": []string{"bug"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected numeric and boolean strings to be coerced, got %#v", got)
+	}
+
+	got, err = call(t, map[string]interface{}{"owner": "me", "limit": float64(7), "status": "open"})
+	if err != nil || got["limit"] != 7 || got["status"] != "open" {
+		t.Errorf("Expected JSON numbers to become ints, got %#v (%v)", got, err)
+	}
+
+	got, err = call(t, map[string]interface{}{"owner": "me"})
+	if err != nil || got["limit"] != 20 {
+		t.Errorf("Expected the default limit, got %#v (%v)", got, err)
+	}
+	if labels, ok := got["labels"].([]string); !ok || len(labels) != 1 || labels[0] != "bug" {
+		t.Errorf("Expected the default labels as a []string, got %#v", got["labels"])
+	}
+
+	got, err = call(t, map[string]interface{}{"owner": "me", "filter": map[string]interface{}{"since": "2024-01-01", "depth": "3", "extra": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]interface{}{"since": "2024-01-01", "depth": 3, "extra": true}
+	if !reflect.DeepEqual(got["filter"], want) {
+		t.Errorf("Expected nested fields to be coerced, got %#v", got["filter"])
+	}
+}
+
+func TestRejections(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want []string
+	}{
+		{"missing required", map[string]interface{}{"limit": 1}, []string{"owner"}},
+		{"outside enum", map[string]interface{}{"owner": "me", "status": "pending"}, []string{"status"}},
+		{"wrong type", map[string]interface{}{"owner": "me", "limit": "many"}, []string{"limit"}},
+		{"all at once", map[string]interface{}{"status": "pending", "limit": 1.5}, []string{"owner", "status", "limit"}},
+		{"missing nested field", map[string]interface{}{"owner": "me", "filter": map[string]interface{}{}}, []string{"filter.since"}},
+		{"wrong nested type", map[string]interface{}{"owner": "me", "filter": map[string]interface{}{"since": "today", "depth": "deep"}}, []string{"filter.depth"}},
+	}
+	for _, tt := range tests {
+		_, err := call(t, tt.args)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.Problems) != len(tt.want) {
+			t.Errorf("%s: expected %d problems, got %v", tt.name, len(tt.want), err)
+			continue
+		}
+		for _, param := range tt.want {
+			if !strings.Contains(err.Error(), param+":") {
+				t.Errorf("%s: expected a problem with %s, got %v", tt.name, param, err)
+			}
+		}
+	}
+}
+`,
+	})
+}
+
+// runGenerated runs go test on generated files in a module of their own
+func runGenerated(t *testing.T, files map[string]string) {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/testtools\n\ngo 1.24\n"
+	if importsRepo(files) {
+		// Generated code importing this module builds against the working
+		// tree, with this module's checksums
+		root, err := filepath.Abs(filepath.Join("..", ".."))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files["go.mod"] += "\nrequire " + repoModule + " v0.0.0\n\nreplace " + repoModule + " => " + root + "\n"
+		files["go.sum"] = string(sum)
+	}
+	if importsModule(files, "google.golang.org/grpc") {
+		// This module does not depend on gRPC, so generated gRPC tools need it
+		// required like users of the generator do
+		files["go.mod"] += "\nrequire google.golang.org/grpc " + grpcTestVersion + "\n"
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goTool, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Generated code failed its tests: %v\n%s", err, out)
+	}
+}
+
+// repoModule is the path of this module, imported by some generated code
+const repoModule = "github.com/imran31415/godemode"
+
+// grpcTestVersion is the gRPC release generated gRPC tools are tested with
+const grpcTestVersion = "v1.84.0"
+
+// requireGRPC skips the test unless gRPC and its dependencies can be resolved,
+// from the module cache or the configured proxy, since this module does not
+// depend on it
+func requireGRPC(t *testing.T) {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+
+	dir := t.TempDir()
+	mod := "module example.com/grpcprobe\n\ngo 1.24\n\nrequire google.golang.org/grpc " + grpcTestVersion + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goTool, "list", "-deps", "google.golang.org/grpc")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("google.golang.org/grpc@%s cannot be resolved: %v\n%s", grpcTestVersion, err, out)
+	}
+}
+
+// importsRepo reports whether any of the generated files imports this module
+func importsRepo(files map[string]string) bool {
+	return importsModule(files, repoModule)
+}
+
+// importsModule reports whether any of the generated files imports a package
+// of the given module
+func importsModule(files map[string]string, module string) bool {
+	for _, content := range files {
+		if strings.Contains(content, `"`+module+`"`) || strings.Contains(content, `"`+module+`/`) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGenerateRegistryWithNamespaces(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+
+	tools := []spec.ToolDefinition{
+		{Name: "github.listIssues", Namespace: "github", Description: "List issues"},
+		{Name: "sqlite.query", Namespace: "sqlite", Description: "Run a query"},
+	}
+
+	code, err := gen.GenerateRegistry(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+
+	expected := []string{
+		`Name:        "github.listIssues"`,
+		"Function:    githubListIssues,",
+		`Namespace:   "github",`,
+		"func (r *Registry) Namespaces() []string",
+		`doc += fmt.Sprintf("# %s\n\n", namespace)`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(code, exp) {
+			t.Errorf("Generated code should contain: %s", exp)
+		}
+	}
+}
+
+func TestGenerateRegistryCarriesSchemas(t *testing.T) {
+	gen := NewCodeGenerator("schemas")
+	readOnly := true
+
+	tools := []spec.ToolDefinition{
+		{
+			Name: "getItem",
+			Parameters: []spec.Parameter{
+				{Name: "id", Type: "int", Required: true, Schema: map[string]interface{}{"minimum": float64(1)}},
+				{Name: "since", Type: "string", Format: "date-time"},
+				{Name: "page", Type: "map[string]interface{}", Properties: []spec.Parameter{{Name: "size", Type: "int"}}},
+			},
+			Annotations: &spec.ToolAnnotations{ReadOnlyHint: &readOnly},
+		},
+	}
+
+	code, err := gen.GenerateRegistry(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+
+	for _, want := range []string{
+		`Schema: map[string]interface{}{"minimum": float64(1)}`,
+		`Format: "date-time"`,
+		`Properties: []ParamInfo{{Name: "size", Type: "int", Required: false}}`,
+		`Annotations: map[string]interface{}{"readOnlyHint": true}`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated registry to contain %s", want)
+		}
+	}
+}
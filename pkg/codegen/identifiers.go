@@ -0,0 +1,150 @@
+package codegen
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// predeclaredIdentifiers are Go's universe-scope names. Generated code refers
+// to several of them (string, int, bool, ...), so they must not be shadowed.
+var predeclaredIdentifiers = []string{
+	"any", "bool", "byte", "comparable", "complex64", "complex128", "error",
+	"float32", "float64", "int", "int8", "int16", "int32", "int64", "rune",
+	"string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+	"true", "false", "iota", "nil",
+	"append", "cap", "clear", "close", "complex", "copy", "delete", "imag",
+	"len", "make", "max", "min", "new", "panic", "print", "println", "real",
+	"recover",
+}
+
+// generatedPackageIdentifiers are the package-level names emitted by the
+// registry, proxy, GraphQL, gRPC, context and test templates, plus the packages
+// they import
+var generatedPackageIdentifiers = []string{
+	"init", "main", "_",
+	"APIDocs", "Registry", "NewRegistry", "ToolFunc", "ToolInfo", "ParamInfo",
+	"ValidationProblem", "ValidationError", "validateArgs", "validateParams", "goType",
+	"coerceArg", "coerceItems", "inEnum",
+	"upstreamCommand", "upstreamURL", "upstreamMu", "upstream",
+	"SetUpstream", "CloseUpstream", "getUpstream", "callUpstream",
+	"toolFixture", "toolFixtures", "copyArgs", "isStubResult",
+	"ResourceInfo", "PromptInfo", "PromptMessage", "ResourceHandler", "PromptHandler",
+	"SetResourceHandler", "SetPromptHandler", "contextMu", "resourceHandler",
+	"promptHandler", "resources", "prompts", "knownResource", "uriTemplatePattern",
+	"uriTemplatePatterns", "expandURITemplate", "readUpstreamResource", "getUpstreamPrompt",
+	"GraphQLEndpoint", "GraphQLHeaders", "GraphQLClient", "graphQLOperation",
+	"graphQLVariable", "callGraphQL", "graphQLSelection", "graphQLNamePattern",
+	"GRPCTarget", "GRPCConn", "GRPCTimeout", "grpcFileDescriptorSet", "grpcMu",
+	"grpcFilesOnce", "grpcFiles", "grpcFilesErr", "grpcConnection", "grpcMethod",
+	"callGRPC", "grpcResult",
+	"fmt", "reflect", "sort", "strconv", "strings", "sync", "client", "testing",
+	"url", "regexp", "bytes", "json", "http", "context", "io", "time", "grpc",
+	"insecure", "protojson", "proto", "protodesc", "protoreflect", "protoregistry",
+	"descriptorpb", "dynamicpb",
+}
+
+// generatedLocalIdentifiers are the names used inside generated tool stubs
+var generatedLocalIdentifiers = []string{"_", "args", "ok", "fmt"}
+
+// reservedSet builds a lookup set from the given name lists
+func reservedSet(lists ...[]string) map[string]bool {
+	set := make(map[string]bool)
+	for _, list := range lists {
+		for _, name := range list {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// goIdentifier converts a wire name into a valid Go identifier. Names that are
+// already valid and not reserved are kept unchanged; otherwise separators are
+// dropped and the following word capitalised ("read-file" becomes readFile),
+// a leading digit is prefixed with "x", and a reserved result gets a "_" suffix.
+func goIdentifier(name string, reserved map[string]bool) string {
+	if token.IsIdentifier(name) && !reserved[name] {
+		return name
+	}
+
+	var sb strings.Builder
+	upperNext := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			upperNext = sb.Len() > 0
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		sb.WriteRune(r)
+	}
+
+	ident := sb.String()
+	if ident == "" {
+		ident = "x"
+	}
+	if first := []rune(ident)[0]; unicode.IsDigit(first) {
+		ident = "x" + ident
+	}
+	if token.IsKeyword(ident) || reserved[ident] {
+		ident += "_"
+	}
+	return ident
+}
+
+// resolveIdentifiers maps each wire name to a unique Go identifier. Names that
+// need no sanitisation claim their identifier first, so an existing function
+// keeps its name when a colliding tool is added later; remaining collisions
+// are resolved with numeric suffixes (readFile2, readFile3, ...) in input order.
+func resolveIdentifiers(names []string, reserved map[string]bool) map[string]string {
+	resolved := make(map[string]string, len(names))
+	taken := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		if _, done := resolved[name]; done {
+			continue
+		}
+		if token.IsIdentifier(name) && !reserved[name] {
+			resolved[name] = name
+			taken[name] = true
+		}
+	}
+
+	for _, name := range names {
+		if _, done := resolved[name]; done {
+			continue
+		}
+		base := goIdentifier(name, reserved)
+		ident := base
+		for i := 2; taken[ident]; i++ {
+			ident = base + strconv.Itoa(i)
+		}
+		resolved[name] = ident
+		taken[ident] = true
+	}
+
+	return resolved
+}
+
+// toolIdentifiers maps each tool's wire name to its Go function name
+func toolIdentifiers(tools []spec.ToolDefinition) map[string]string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return resolveIdentifiers(names, reservedSet(predeclaredIdentifiers, generatedPackageIdentifiers))
+}
+
+// paramIdentifiers maps each parameter's wire name to its local variable name
+func paramIdentifiers(params []spec.Parameter) map[string]string {
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Name
+	}
+	return resolveIdentifiers(names, reservedSet(predeclaredIdentifiers, generatedLocalIdentifiers))
+}
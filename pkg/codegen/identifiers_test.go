@@ -0,0 +1,100 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func TestGoIdentifier(t *testing.T) {
+	reserved := reservedSet(predeclaredIdentifiers, generatedLocalIdentifiers)
+
+	tests := map[string]string{
+		"get_user":   "get_user",
+		"readEmail":  "readEmail",
+		"read-file":  "readFile",
+		"list.items": "listItems",
+		"a b/c":      "aBC",
+		"2fa":        "x2fa",
+		"type":       "type_",
+		"range":      "range_",
+		"string":     "string_",
+		"args":       "args_",
+		"-":          "x",
+	}
+
+	for name, want := range tests {
+		if got := goIdentifier(name, reserved); got != want {
+			t.Errorf("goIdentifier(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestResolveIdentifiersAvoidsCollisions(t *testing.T) {
+	names := []string{"read-file", "read.file", "readFile"}
+
+	resolved := resolveIdentifiers(names, nil)
+
+	// The already-valid name keeps its identifier regardless of order
+	if resolved["readFile"] != "readFile" {
+		t.Errorf("Expected readFile to keep its name, got %q", resolved["readFile"])
+	}
+	if resolved["read-file"] != "readFile2" || resolved["read.file"] != "readFile3" {
+		t.Errorf("Expected numeric suffixes for colliding names, got %v", resolved)
+	}
+}
+
+func TestGeneratedCodeSanitizesIdentifiers(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+
+	tools := []spec.ToolDefinition{
+		{
+			Name:        "read-file",
+			Description: "Read a \"file\"\nfrom disk",
+			Parameters: []spec.Parameter{
+				{Name: "type", Type: "string", Required: true},
+				{Name: "range", Type: "integer"},
+			},
+		},
+		{Name: "list.items"},
+	}
+
+	registry, err := gen.GenerateRegistry(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate registry: %v", err)
+	}
+	if !strings.Contains(registry, `Name:        "read-file"`) {
+		t.Error("Registry should keep the wire name for calls")
+	}
+	if !strings.Contains(registry, "Function: readFile,") {
+		t.Error("Registry should reference the sanitized function name")
+	}
+	if !strings.Contains(registry, `Description: "Read a \"file\"\nfrom disk"`) {
+		t.Error("Registry should quote descriptions")
+	}
+
+	toolsFile, err := gen.GenerateToolsFile(tools)
+	if err != nil {
+		t.Fatalf("Failed to generate tools file: %v", err)
+	}
+	expected := []string{
+		"func readFile(args map[string]interface{})",
+		"func listItems(args map[string]interface{})",
+		`type_, ok := args["type"].(string)`,
+		`range_, _ := args["range"].(int)`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(toolsFile, exp) {
+			t.Errorf("Tools file should contain: %s", exp)
+		}
+	}
+
+	proxy, err := gen.GenerateMCPProxyToolsFile(tools, ProxyUpstream{URL: "http://localhost:8080/mcp"})
+	if err != nil {
+		t.Fatalf("Failed to generate proxy tools file: %v", err)
+	}
+	if !strings.Contains(proxy, `return callUpstream("read-file", args)`) {
+		t.Error("Proxy should forward using the wire name")
+	}
+}
@@ -0,0 +1,203 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// ManifestFileName is the file recording what the last generation produced
+const ManifestFileName = "godemode-manifest.json"
+
+// Manifest records the tool signatures a package was generated from, so later
+// runs can report what changed in the spec since then
+type Manifest struct {
+	SpecFormat spec.SpecFormat `json:"specFormat"`
+	Tools      []ManifestTool  `json:"tools"`
+}
+
+// ManifestTool is the recorded signature of a single tool
+type ManifestTool struct {
+	Name       string          `json:"name"`
+	Parameters []ManifestParam `json:"parameters,omitempty"`
+}
+
+// ManifestParam is the recorded signature of a single parameter
+type ManifestParam struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// NewManifest builds a manifest from tool definitions
+func NewManifest(format spec.SpecFormat, tools []spec.ToolDefinition) *Manifest {
+	m := &Manifest{
+		SpecFormat: format,
+		Tools:      make([]ManifestTool, 0, len(tools)),
+	}
+
+	for _, tool := range tools {
+		mt := ManifestTool{Name: tool.Name}
+		for _, param := range tool.Parameters {
+			mt.Parameters = append(mt.Parameters, ManifestParam{
+				Name:     param.Name,
+				Type:     param.Type,
+				Required: param.Required,
+			})
+		}
+		sort.Slice(mt.Parameters, func(i, j int) bool {
+			return mt.Parameters[i].Name < mt.Parameters[j].Name
+		})
+		m.Tools = append(m.Tools, mt)
+	}
+
+	sort.Slice(m.Tools, func(i, j int) bool {
+		return m.Tools[i].Name < m.Tools[j].Name
+	})
+
+	return m
+}
+
+// LoadManifest reads a manifest written by a previous generation
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// Marshal encodes the manifest as indented JSON
+func (m *Manifest) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ManifestDiff lists the tool changes between two manifests
+type ManifestDiff struct {
+	Added   []string
+	Removed []string
+	Changed []ToolChange
+}
+
+// ToolChange describes how the signature of a tool changed
+type ToolChange struct {
+	Name    string
+	Details []string
+}
+
+// HasChanges reports whether any tool was added, removed or changed
+func (d ManifestDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// String renders the diff in a human-readable form
+func (d ManifestDiff) String() string {
+	if !d.HasChanges() {
+		return "No tool changes since last generation\n"
+	}
+
+	var sb strings.Builder
+	for _, name := range d.Added {
+		sb.WriteString(fmt.Sprintf("+ %s (added)\n", name))
+	}
+	for _, name := range d.Removed {
+		sb.WriteString(fmt.Sprintf("- %s (removed)\n", name))
+	}
+	for _, change := range d.Changed {
+		sb.WriteString(fmt.Sprintf("~ %s (signature changed)\n", change.Name))
+		for _, detail := range change.Details {
+			sb.WriteString(fmt.Sprintf("    %s\n", detail))
+		}
+	}
+	return sb.String()
+}
+
+// DiffManifests compares the previous manifest with the current one
+func DiffManifests(previous, current *Manifest) ManifestDiff {
+	var diff ManifestDiff
+
+	prevTools := make(map[string]ManifestTool, len(previous.Tools))
+	for _, tool := range previous.Tools {
+		prevTools[tool.Name] = tool
+	}
+	currTools := make(map[string]ManifestTool, len(current.Tools))
+	for _, tool := range current.Tools {
+		currTools[tool.Name] = tool
+	}
+
+	for _, tool := range current.Tools {
+		prev, existed := prevTools[tool.Name]
+		if !existed {
+			diff.Added = append(diff.Added, tool.Name)
+			continue
+		}
+		if details := diffParams(prev.Parameters, tool.Parameters); len(details) > 0 {
+			diff.Changed = append(diff.Changed, ToolChange{Name: tool.Name, Details: details})
+		}
+	}
+
+	for _, tool := range previous.Tools {
+		if _, exists := currTools[tool.Name]; !exists {
+			diff.Removed = append(diff.Removed, tool.Name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Name < diff.Changed[j].Name
+	})
+
+	return diff
+}
+
+// diffParams describes parameter-level differences between two signatures
+func diffParams(previous, current []ManifestParam) []string {
+	var details []string
+
+	prevParams := make(map[string]ManifestParam, len(previous))
+	for _, param := range previous {
+		prevParams[param.Name] = param
+	}
+	currParams := make(map[string]ManifestParam, len(current))
+	for _, param := range current {
+		currParams[param.Name] = param
+	}
+
+	for _, param := range current {
+		prev, existed := prevParams[param.Name]
+		if !existed {
+			details = append(details, fmt.Sprintf("parameter %s added (%s)", param.Name, param.Type))
+			continue
+		}
+		if prev.Type != param.Type {
+			details = append(details, fmt.Sprintf("parameter %s type %s -> %s", param.Name, prev.Type, param.Type))
+		}
+		if prev.Required != param.Required {
+			details = append(details, fmt.Sprintf("parameter %s required %t -> %t", param.Name, prev.Required, param.Required))
+		}
+	}
+
+	for _, param := range previous {
+		if _, exists := currParams[param.Name]; !exists {
+			details = append(details, fmt.Sprintf("parameter %s removed", param.Name))
+		}
+	}
+
+	sort.Strings(details)
+	return details
+}
@@ -0,0 +1,97 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func TestDiffManifests(t *testing.T) {
+	previous := NewManifest(spec.FormatMCP, []spec.ToolDefinition{
+		{Name: "keep", Parameters: []spec.Parameter{{Name: "id", Type: "string", Required: true}}},
+		{Name: "change", Parameters: []spec.Parameter{
+			{Name: "count", Type: "int"},
+			{Name: "gone", Type: "string"},
+		}},
+		{Name: "remove"},
+	})
+
+	current := NewManifest(spec.FormatMCP, []spec.ToolDefinition{
+		{Name: "keep", Parameters: []spec.Parameter{{Name: "id", Type: "string", Required: true}}},
+		{Name: "change", Parameters: []spec.Parameter{
+			{Name: "count", Type: "float64", Required: true},
+			{Name: "extra", Type: "bool"},
+		}},
+		{Name: "add"},
+	})
+
+	diff := DiffManifests(previous, current)
+
+	if !diff.HasChanges() {
+		t.Fatal("Expected changes")
+	}
+	if len(diff.Added) != 1 || diff.Added[0] != "add" {
+		t.Errorf("Expected 'add' to be added, got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "remove" {
+		t.Errorf("Expected 'remove' to be removed, got %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Name != "change" {
+		t.Fatalf("Expected 'change' to be changed, got %v", diff.Changed)
+	}
+
+	details := strings.Join(diff.Changed[0].Details, "\n")
+	for _, want := range []string{
+		"parameter count type int -> float64",
+		"parameter count required false -> true",
+		"parameter extra added (bool)",
+		"parameter gone removed",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected detail %q in:\n%s", want, details)
+		}
+	}
+}
+
+func TestDiffManifestsNoChanges(t *testing.T) {
+	tools := []spec.ToolDefinition{
+		{Name: "b", Parameters: []spec.Parameter{{Name: "y", Type: "int"}, {Name: "x", Type: "string"}}},
+		{Name: "a"},
+	}
+
+	diff := DiffManifests(NewManifest(spec.FormatMCP, tools), NewManifest(spec.FormatMCP, tools))
+	if diff.HasChanges() {
+		t.Errorf("Expected no changes, got:\n%s", diff)
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	manifest := NewManifest(spec.FormatOpenAPI, []spec.ToolDefinition{
+		{Name: "getUser", Parameters: []spec.Parameter{{Name: "id", Type: "string", Required: true}}},
+	})
+
+	data, err := manifest.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal manifest: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), ManifestFileName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+
+	if loaded.SpecFormat != spec.FormatOpenAPI {
+		t.Errorf("Expected format openapi, got %s", loaded.SpecFormat)
+	}
+	if DiffManifests(manifest, loaded).HasChanges() {
+		t.Error("Loaded manifest should match the original")
+	}
+}
@@ -0,0 +1,75 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// MergeToolsFile adds stub implementations for tools missing from an existing,
+// hand-edited tools.go. Existing code is never modified or removed, so tool
+// bodies filled in after an earlier generation survive regeneration.
+// It returns the merged source and the names of the tools that were added.
+func (g *CodeGenerator) MergeToolsFile(existing string, tools []spec.ToolDefinition) (string, []string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "tools.go", existing, parser.ParseComments)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse existing tools file: %w", err)
+	}
+
+	// Collect the package-level functions that are already implemented
+	defined := make(map[string]bool)
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			defined[fn.Name.Name] = true
+		}
+	}
+
+	var added []string
+	var stubs strings.Builder
+	funcNames := toolIdentifiers(tools)
+	for _, tool := range tools {
+		if defined[funcNames[tool.Name]] {
+			continue
+		}
+		stubs.WriteString("\n")
+		stubs.WriteString(g.generateToolImplementation(tool, funcNames[tool.Name]))
+		added = append(added, tool.Name)
+	}
+
+	if len(added) == 0 {
+		return existing, nil, nil
+	}
+
+	merged := existing
+	if strings.Contains(stubs.String(), "fmt.") && !importsPackage(file, "fmt") {
+		// A separate import declaration after the package clause is valid Go
+		// and leaves the user's own import block untouched
+		offset := fset.Position(file.Name.End()).Offset
+		merged = merged[:offset] + "\n\nimport \"fmt\"\n" + merged[offset:]
+	}
+	merged = strings.TrimRight(merged, "\n") + "\n" + stubs.String()
+
+	formatted, err := format.Source([]byte(merged))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to format merged tools file: %w", err)
+	}
+
+	return string(formatted), added, nil
+}
+
+// importsPackage reports whether the file imports the given package path
+func importsPackage(file *ast.File, path string) bool {
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == path {
+			return true
+		}
+	}
+	return false
+}
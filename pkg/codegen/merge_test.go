@@ -0,0 +1,77 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func TestMergeToolsFilePreservesImplementations(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+
+	existing := `package mytools
+
+// readEmail was implemented by hand
+func readEmail(args map[string]interface{}) (interface{}, error) {
+	return "hand-written", nil
+}
+`
+
+	tools := []spec.ToolDefinition{
+		{Name: "readEmail", Parameters: []spec.Parameter{{Name: "id", Type: "string", Required: true}}},
+		{Name: "sendEmail", Parameters: []spec.Parameter{{Name: "to", Type: "string", Required: true}}},
+	}
+
+	merged, added, err := gen.MergeToolsFile(existing, tools)
+	if err != nil {
+		t.Fatalf("Failed to merge tools file: %v", err)
+	}
+
+	if len(added) != 1 || added[0] != "sendEmail" {
+		t.Errorf("Expected only sendEmail to be added, got %v", added)
+	}
+
+	if !strings.Contains(merged, `return "hand-written", nil`) {
+		t.Error("Merged code should keep the hand-written implementation")
+	}
+
+	if !strings.Contains(merged, "// readEmail was implemented by hand") {
+		t.Error("Merged code should keep comments")
+	}
+
+	if !strings.Contains(merged, "func sendEmail(args map[string]interface{}) (interface{}, error)") {
+		t.Error("Merged code should contain a stub for the new tool")
+	}
+
+	if !strings.Contains(merged, `import "fmt"`) {
+		t.Error("Merged code should import fmt for the new stub")
+	}
+}
+
+func TestMergeToolsFileNothingToAdd(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+
+	existing := "package mytools\n\nfunc ping(args map[string]interface{}) (interface{}, error) { return nil, nil }\n"
+
+	merged, added, err := gen.MergeToolsFile(existing, []spec.ToolDefinition{{Name: "ping"}})
+	if err != nil {
+		t.Fatalf("Failed to merge tools file: %v", err)
+	}
+
+	if len(added) != 0 {
+		t.Errorf("Expected no added tools, got %v", added)
+	}
+
+	if merged != existing {
+		t.Error("Existing file should be returned unchanged")
+	}
+}
+
+func TestMergeToolsFileInvalidSource(t *testing.T) {
+	gen := NewCodeGenerator("mytools")
+
+	if _, _, err := gen.MergeToolsFile("not go code", nil); err == nil {
+		t.Error("Expected error for unparsable tools file")
+	}
+}
@@ -1,12 +1,22 @@
 package codegen
 
-const registryTemplate = `package {{.PackageName}}
+const registryTemplate = `// Code generated by spec-to-godemode. DO NOT EDIT.
+
+package {{.PackageName}}
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 )
 
+// APIDocs is a compact, prompt-ready reference of every tool, with
+// Go-style signatures and one-line descriptions
+const APIDocs = {{printf "%q" .APIDocs}}
+
 // ToolFunc is a function signature for tools
 type ToolFunc func(args map[string]interface{}) (interface{}, error)
 
@@ -16,13 +26,21 @@ type ToolInfo struct {
 	Description string
 	Parameters  []ParamInfo
 	Function    ToolFunc
+	Namespace   string                 // spec the tool was merged from, if generated from several
+	Annotations map[string]interface{} // MCP behaviour hints, e.g. "readOnlyHint": true
 }
 
 // ParamInfo describes a parameter
 type ParamInfo struct {
-	Name     string
-	Type     string
-	Required bool
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	Enum        []interface{}          // allowed values, if restricted
+	Default     interface{}            // value used when the argument is omitted
+	Format      string                 // JSON Schema format, e.g. "email"
+	Properties  []ParamInfo            // fields of object parameters, when known
+	Schema      map[string]interface{} // further JSON Schema keywords, e.g. "minimum"
 }
 
 // Registry manages all available tools
@@ -43,12 +61,18 @@ func NewRegistry() *Registry {
 // registerTools registers all generated tools
 func (r *Registry) registerTools() {
 {{range .Tools}}	r.Register(&ToolInfo{
-		Name:        "{{.Name}}",
-		Description: "{{.Description}}",
+		Name:        {{printf "%q" .Name}},
+		Description: {{printf "%q" .Description}},
 		Parameters: []ParamInfo{
-{{range .Parameters}}			{Name: "{{.Name}}", Type: "{{.Type}}", Required: {{.Required}}},
+{{range .Parameters}}			{{paramInfo .}},
 {{end}}		},
-		Function: {{.Name}},
+		Function: {{funcName .Name}},
+{{- if .Namespace}}
+		Namespace: {{printf "%q" .Namespace}},
+{{- end}}
+{{- if .Annotations}}
+		Annotations: {{annotations .Annotations}},
+{{- end}}
 	})
 {{end}}
 }
@@ -75,14 +99,257 @@ func (r *Registry) Get(name string) (*ToolInfo, bool) {
 	return tool, found
 }
 
-// Call invokes a tool by name with arguments
+// Call invokes a tool by name with arguments.
+// Arguments are validated against the tool's parameters first: defaults are
+// applied, values are coerced to the declared types, and a *ValidationError
+// listing every problem is returned if any argument is invalid.
 func (r *Registry) Call(name string, args map[string]interface{}) (interface{}, error) {
 	tool, found := r.Get(name)
 	if !found {
 		return nil, fmt.Errorf("tool not found: %s", name)
 	}
 
-	return tool.Function(args)
+	validated, err := validateArgs(tool, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return tool.Function(validated)
+}
+
+// ValidationProblem describes a single invalid argument
+type ValidationProblem struct {
+	Param   string
+	Message string
+}
+
+// ValidationError lists every problem found in the arguments of a tool call
+type ValidationError struct {
+	Tool     string
+	Problems []ValidationProblem
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		msgs[i] = fmt.Sprintf("%s: %s", problem.Param, problem.Message)
+	}
+	return fmt.Sprintf("invalid arguments for %s: %s", e.Tool, strings.Join(msgs, "; "))
+}
+
+// validateArgs checks args against the tool's parameters, applies defaults and
+// coerces values to the declared Go types
+func validateArgs(tool *ToolInfo, args map[string]interface{}) (map[string]interface{}, error) {
+	validated, problems := validateParams(tool.Parameters, args, "")
+
+	known := make(map[string]bool, len(tool.Parameters))
+	for _, param := range tool.Parameters {
+		known[param.Name] = true
+	}
+	var unknown []string
+	for name := range args {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, ValidationProblem{Param: name, Message: "unknown parameter"})
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Tool: tool.Name, Problems: problems}
+	}
+	return validated, nil
+}
+
+// validateParams validates and coerces the values of params in args. Object
+// parameters with known properties are validated recursively, and their
+// problems are reported under dotted paths such as "input.title".
+func validateParams(params []ParamInfo, args map[string]interface{}, path string) (map[string]interface{}, []ValidationProblem) {
+	validated := make(map[string]interface{}, len(params))
+	var problems []ValidationProblem
+
+	for _, param := range params {
+		name := path + param.Name
+
+		value, present := args[param.Name]
+		if !present || value == nil {
+			if param.Default == nil {
+				if param.Required {
+					problems = append(problems, ValidationProblem{Param: name, Message: "required parameter is missing"})
+				}
+				continue
+			}
+			// Defaults go through the same coercion as supplied values, so
+			// tools always receive the declared Go type
+			value = param.Default
+		}
+
+		coerced, err := coerceArg(param.Type, value)
+		if err != nil {
+			problems = append(problems, ValidationProblem{Param: name, Message: err.Error()})
+			continue
+		}
+
+		if len(param.Enum) > 0 && !inEnum(coerced, param.Enum) {
+			problems = append(problems, ValidationProblem{
+				Param:   name,
+				Message: fmt.Sprintf("value %v is not one of %v", coerced, param.Enum),
+			})
+			continue
+		}
+
+		if fields, ok := coerced.(map[string]interface{}); ok && len(param.Properties) > 0 {
+			nested, nestedProblems := validateParams(param.Properties, fields, name+".")
+			if len(nestedProblems) > 0 {
+				problems = append(problems, nestedProblems...)
+				continue
+			}
+			// Fields the schema does not describe are passed through unchanged
+			for key, field := range fields {
+				if _, described := nested[key]; !described {
+					nested[key] = field
+				}
+			}
+			coerced = nested
+		}
+
+		validated[param.Name] = coerced
+	}
+
+	return validated, problems
+}
+
+// goType resolves a declared parameter type to the Go type tools receive
+func goType(paramType string) string {
+	switch paramType {
+	case "string":
+		return "string"
+	case "int", "int32", "int64", "integer":
+		return "int"
+	case "float32", "float64", "number":
+		return "float64"
+	case "bool", "boolean":
+		return "bool"
+	case "map", "map[string]interface{}", "object":
+		return "map[string]interface{}"
+	default:
+		if strings.HasPrefix(paramType, "[]") {
+			return paramType
+		}
+		return "interface{}"
+	}
+}
+
+// coerceArg converts a decoded JSON value (or a string) to the declared Go type
+func coerceArg(paramType string, value interface{}) (interface{}, error) {
+	switch target := goType(paramType); target {
+	case "string":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case "int":
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case int32:
+			return int(v), nil
+		case int64:
+			return int(v), nil
+		case float64:
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+		case string:
+			if n, err := strconv.Atoi(v); err == nil {
+				return n, nil
+			}
+		}
+	case "float64":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case float32:
+			return float64(v), nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, nil
+			}
+		}
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	case "map[string]interface{}":
+		if m, ok := value.(map[string]interface{}); ok {
+			return m, nil
+		}
+	case "[]interface{}":
+		return coerceItems("interface{}", value)
+	case "[]string":
+		items, err := coerceItems("string", value)
+		if err != nil {
+			return nil, err
+		}
+		strs := make([]string, len(items))
+		for i, item := range items {
+			strs[i] = item.(string)
+		}
+		return strs, nil
+	case "[]int":
+		items, err := coerceItems("int", value)
+		if err != nil {
+			return nil, err
+		}
+		ints := make([]int, len(items))
+		for i, item := range items {
+			ints[i] = item.(int)
+		}
+		return ints, nil
+	default:
+		// Types without a known coercion are passed through unchanged
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("expected %s, got %T", goType(paramType), value)
+}
+
+// coerceItems coerces every element of a slice value to elemType
+func coerceItems(elemType string, value interface{}) ([]interface{}, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("expected a list, got %T", value)
+	}
+
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		item, err := coerceArg(elemType, rv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %v", i, err)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// inEnum reports whether value is one of the allowed values
+func inEnum(value interface{}, allowed []interface{}) bool {
+	for _, candidate := range allowed {
+		if fmt.Sprint(candidate) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
 }
 
 // List returns all registered tool names
@@ -109,14 +376,49 @@ func (r *Registry) ListTools() []*ToolInfo {
 	return tools
 }
 
-// GetDocumentation returns formatted documentation for all tools
+// Namespaces returns the sorted namespaces of tools merged from several specs
+func (r *Registry) Namespaces() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, tool := range r.tools {
+		if tool.Namespace != "" && !seen[tool.Namespace] {
+			seen[tool.Namespace] = true
+			namespaces = append(namespaces, tool.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// GetDocumentation returns formatted documentation for all tools,
+// grouped by namespace when tools were merged from several specs
 func (r *Registry) GetDocumentation() string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	tools := make([]*ToolInfo, 0, len(r.tools))
+	for _, tool := range r.tools {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].Namespace != tools[j].Namespace {
+			return tools[i].Namespace < tools[j].Namespace
+		}
+		return tools[i].Name < tools[j].Name
+	})
+
 	doc := "Available Tools:\n\n"
 
-	for _, tool := range r.tools {
+	namespace := ""
+	for _, tool := range tools {
+		if tool.Namespace != namespace {
+			namespace = tool.Namespace
+			doc += fmt.Sprintf("# %s\n\n", namespace)
+		}
+
 		doc += fmt.Sprintf("## %s\n", tool.Name)
 		doc += fmt.Sprintf("%s\n\n", tool.Description)
 
@@ -127,6 +429,12 @@ func (r *Registry) GetDocumentation() string {
 				if param.Required {
 					required = " (required)"
 				}
+				if len(param.Enum) > 0 {
+					required += fmt.Sprintf(" one of %v", param.Enum)
+				}
+				if param.Default != nil {
+					required += fmt.Sprintf(" default %v", param.Default)
+				}
 				doc += fmt.Sprintf("  - %s (%s)%s\n", param.Name, param.Type, required)
 			}
 			doc += "\n"
@@ -145,7 +453,833 @@ import (
 
 // Generated tool implementations
 // TODO: Replace stub implementations with your actual business logic
+//
+// This file is owned by you: regenerating only appends stubs for new tools
+// and never rewrites existing functions.
 
 {{range .Implementations}}{{.}}
 {{end}}
 `
+
+const mcpProxyToolsTemplate = `// Code generated by spec-to-godemode. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+)
+
+// Upstream MCP server that the generated tools forward to.
+// Exactly one of these is set at generation time; use SetUpstream to override.
+const (
+	upstreamCommand = {{printf "%q" .Upstream.Command}}
+	upstreamURL     = {{printf "%q" .Upstream.URL}}
+)
+
+var (
+	upstreamMu sync.Mutex
+	upstream   client.ToolCaller
+)
+
+// SetUpstream replaces the MCP client used by the generated tools
+func SetUpstream(c client.ToolCaller) {
+	upstreamMu.Lock()
+	defer upstreamMu.Unlock()
+	upstream = c
+}
+
+// CloseUpstream shuts down the connection to the upstream MCP server
+func CloseUpstream() error {
+	upstreamMu.Lock()
+	defer upstreamMu.Unlock()
+
+	if upstream == nil {
+		return nil
+	}
+	err := upstream.Close()
+	upstream = nil
+	return err
+}
+
+// getUpstream lazily connects to the configured upstream MCP server
+func getUpstream() (client.ToolCaller, error) {
+	upstreamMu.Lock()
+	defer upstreamMu.Unlock()
+
+	if upstream != nil {
+		return upstream, nil
+	}
+
+	c, err := client.Connect(upstreamCommand, upstreamURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to upstream MCP server: %w", err)
+	}
+	upstream = c
+	return upstream, nil
+}
+
+// callUpstream forwards a tool call to the upstream MCP server and unwraps its content
+func callUpstream(name string, args map[string]interface{}) (interface{}, error) {
+	c, err := getUpstream()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.CallTool(name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.ResultValue(result)
+}
+
+// Generated tool implementations forwarding to the upstream MCP server
+
+{{range .Tools}}// {{funcName .Name}} forwards to the upstream {{printf "%q" .Name}} tool
+func {{funcName .Name}}(args map[string]interface{}) (interface{}, error) {
+	return callUpstream({{printf "%q" .Name}}, args)
+}
+
+{{end}}`
+
+const graphqlToolsTemplate = `// Code generated by spec-to-godemode. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GraphQLEndpoint is the URL the generated tools POST their operations to
+var GraphQLEndpoint = {{printf "%q" .Endpoint}}
+
+// GraphQLHeaders are added to every request, e.g. {"Authorization": "Bearer ..."}
+var GraphQLHeaders = map[string]string{}
+
+// GraphQLClient sends the requests
+var GraphQLClient = http.DefaultClient
+
+// graphQLOperation describes the root field a tool calls
+type graphQLOperation struct {
+	operation   string
+	field       string
+	variables   []graphQLVariable
+	selection   string
+	fieldsParam string
+}
+
+// graphQLVariable is an operation variable and its GraphQL type
+type graphQLVariable struct {
+	name string
+	typ  string
+}
+
+// graphQLNamePattern matches a single GraphQL field name
+var graphQLNamePattern = regexp.MustCompile(` + "`" + `^[_A-Za-z][_0-9A-Za-z]*$` + "`" + `)
+
+// callGraphQL builds the operation for the given arguments, POSTs it to
+// GraphQLEndpoint and returns the data of the root field
+func callGraphQL(op graphQLOperation, args map[string]interface{}) (interface{}, error) {
+	if GraphQLEndpoint == "" {
+		return nil, fmt.Errorf("GraphQLEndpoint is not set")
+	}
+
+	// Only declare variables for arguments that were passed
+	var decls, params []string
+	variables := make(map[string]interface{})
+	for _, v := range op.variables {
+		value, ok := args[v.name]
+		if !ok {
+			continue
+		}
+		decls = append(decls, "$"+v.name+": "+v.typ)
+		params = append(params, v.name+": $"+v.name)
+		variables[v.name] = value
+	}
+
+	selection, err := graphQLSelection(op, args)
+	if err != nil {
+		return nil, err
+	}
+
+	query := op.operation
+	if len(decls) > 0 {
+		query += "(" + strings.Join(decls, ", ") + ")"
+	}
+	query += " { " + op.field
+	if len(params) > 0 {
+		query += "(" + strings.Join(params, ", ") + ")"
+	}
+	if selection != "" {
+		query += " { " + selection + " }"
+	}
+	query += " }"
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode GraphQL request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, GraphQLEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for name, value := range GraphQLHeaders {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := GraphQLClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GraphQL request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   map[string]interface{} ` + "`" + `json:"data"` + "`" + `
+		Errors []struct {
+			Message string ` + "`" + `json:"message"` + "`" + `
+		} ` + "`" + `json:"errors"` + "`" + `
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode GraphQL response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if len(result.Errors) > 0 {
+		msgs := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			msgs[i] = e.Message
+		}
+		return nil, fmt.Errorf("GraphQL %s %s failed: %s", op.operation, op.field, strings.Join(msgs, "; "))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GraphQL request failed: HTTP %d", resp.StatusCode)
+	}
+
+	return result.Data[op.field], nil
+}
+
+// graphQLSelection builds the selection set from the dotted field paths passed
+// in the fields parameter, falling back to the default selection
+func graphQLSelection(op graphQLOperation, args map[string]interface{}) (string, error) {
+	if op.fieldsParam == "" {
+		return op.selection, nil
+	}
+
+	var paths []string
+	switch v := args[op.fieldsParam].(type) {
+	case []string:
+		paths = v
+	case []interface{}:
+		for _, item := range v {
+			if path, ok := item.(string); ok {
+				paths = append(paths, path)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return op.selection, nil
+	}
+
+	tree := map[string]interface{}{}
+	for _, path := range paths {
+		node := tree
+		for _, name := range strings.Split(path, ".") {
+			if !graphQLNamePattern.MatchString(name) {
+				return "", fmt.Errorf("invalid field path %q", path)
+			}
+			child, ok := node[name].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[name] = child
+			}
+			node = child
+		}
+	}
+
+	var render func(node map[string]interface{}) string
+	render = func(node map[string]interface{}) string {
+		names := make([]string, 0, len(node))
+		for name := range node {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = name
+			if child := node[name].(map[string]interface{}); len(child) > 0 {
+				parts[i] += " { " + render(child) + " }"
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+	return render(tree), nil
+}
+
+// Generated tool implementations calling the GraphQL endpoint
+
+{{range .Tools}}// {{funcName .Name}} calls the GraphQL {{.GraphQL.Type}} field {{.GraphQL.Field}}
+func {{funcName .Name}}(args map[string]interface{}) (interface{}, error) {
+	return callGraphQL({{operation .GraphQL}}, args)
+}
+
+{{end}}`
+
+const grpcToolsTemplate = `// Code generated by spec-to-godemode. DO NOT EDIT.
+
+package {{.PackageName}}
+
+// This file imports google.golang.org/grpc, which godemode itself does not
+// depend on. Add it to the module this package belongs to with
+//
+//	go get google.golang.org/grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCTarget is the address dialed, without TLS, when GRPCConn is not set
+var GRPCTarget = {{printf "%q" .Target}}
+
+// GRPCConn is the connection RPCs are invoked over. Set it to a connection
+// dialed with the credentials and interceptors the service needs.
+var GRPCConn grpc.ClientConnInterface
+
+// GRPCTimeout bounds each call
+var GRPCTimeout = 30 * time.Second
+
+// grpcFileDescriptorSet holds the protobuf definitions the tools were
+// generated from, used to build request and response messages at runtime
+var grpcFileDescriptorSet = []byte({{printf "%q" .Descriptors}})
+
+var (
+	grpcMu        sync.Mutex
+	grpcFilesOnce sync.Once
+	grpcFiles     *protoregistry.Files
+	grpcFilesErr  error
+)
+
+// grpcConnection returns GRPCConn, dialing GRPCTarget on first use if it is not set
+func grpcConnection() (grpc.ClientConnInterface, error) {
+	grpcMu.Lock()
+	defer grpcMu.Unlock()
+
+	if GRPCConn != nil {
+		return GRPCConn, nil
+	}
+	if GRPCTarget == "" {
+		return nil, fmt.Errorf("GRPCTarget is not set")
+	}
+
+	conn, err := grpc.NewClient(GRPCTarget, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", GRPCTarget, err)
+	}
+	GRPCConn = conn
+	return conn, nil
+}
+
+// grpcMethod finds a method in the embedded descriptors
+func grpcMethod(service, method string) (protoreflect.MethodDescriptor, error) {
+	grpcFilesOnce.Do(func() {
+		set := &descriptorpb.FileDescriptorSet{}
+		if grpcFilesErr = proto.Unmarshal(grpcFileDescriptorSet, set); grpcFilesErr == nil {
+			grpcFiles, grpcFilesErr = protodesc.NewFiles(set)
+		}
+	})
+	if grpcFilesErr != nil {
+		return nil, fmt.Errorf("invalid embedded descriptors: %w", grpcFilesErr)
+	}
+
+	d, err := grpcFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service %s: %w", service, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("unknown method %s/%s", service, method)
+	}
+	return md, nil
+}
+
+// callGRPC builds the request message from the arguments, which use the
+// message's JSON field names, invokes the RPC and returns the response in its
+// JSON form. Server-streaming responses are collected into a list.
+func callGRPC(service, method string, serverStreaming bool, args map[string]interface{}) (interface{}, error) {
+	md, err := grpcMethod(service, method)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	req := dynamicpb.NewMessage(md.Input())
+	if err := protojson.Unmarshal(data, req); err != nil {
+		return nil, fmt.Errorf("invalid arguments for %s: %w", method, err)
+	}
+
+	conn, err := grpcConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), GRPCTimeout)
+	defer cancel()
+	fullMethod := "/" + service + "/" + method
+
+	if !serverStreaming {
+		resp := dynamicpb.NewMessage(md.Output())
+		if err := conn.Invoke(ctx, fullMethod, req, resp); err != nil {
+			return nil, fmt.Errorf("%s failed: %w", fullMethod, err)
+		}
+		return grpcResult(resp)
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{StreamName: method, ServerStreams: true}, fullMethod)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", fullMethod, err)
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", fullMethod, err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", fullMethod, err)
+	}
+
+	results := []interface{}{}
+	for {
+		resp := dynamicpb.NewMessage(md.Output())
+		if err := stream.RecvMsg(resp); err == io.EOF {
+			return results, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s failed: %w", fullMethod, err)
+		}
+
+		result, err := grpcResult(resp)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+}
+
+// grpcResult converts a response message to plain values through its JSON form
+func grpcResult(msg proto.Message) (interface{}, error) {
+	data, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result, nil
+}
+
+// Generated tool implementations invoking the gRPC service
+
+{{range .Tools}}// {{funcName .Name}} invokes the {{.GRPC.FullMethod}} RPC
+func {{funcName .Name}}(args map[string]interface{}) (interface{}, error) {
+	return callGRPC({{printf "%q" .GRPC.Service}}, {{printf "%q" .GRPC.Method}}, {{.GRPC.ServerStreaming}}, args)
+}
+
+{{end}}`
+
+const registryTestTemplate = `// Code generated by spec-to-godemode. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"reflect"
+	"testing"
+)
+
+// toolFixture holds example arguments synthesised from a tool's schema
+type toolFixture struct {
+	name       string
+	required   []string
+	args       map[string]interface{}
+	wrongTypes map[string]interface{}
+}
+
+var toolFixtures = []toolFixture{
+{{range .Fixtures}}	{
+		name:       {{printf "%q" .Name}},
+		required:   []string{ {{- .Required -}} },
+		args:       map[string]interface{}{ {{- .Args -}} },
+		wrongTypes: map[string]interface{}{ {{- .WrongTypes -}} },
+	},
+{{end}}}
+
+// copyArgs returns a shallow copy of a fixture's arguments
+func copyArgs(args map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		copied[k] = v
+	}
+	return copied
+}
+
+// isStubResult reports whether a tool still returns its generated placeholder
+func isStubResult(name string, result interface{}) bool {
+	return reflect.DeepEqual(result, map[string]interface{}{
+		"status":  "success",
+		"message": name + " executed",
+	})
+}
+
+func TestRegistryRegistersAllTools(t *testing.T) {
+	r := NewRegistry()
+
+	for _, f := range toolFixtures {
+		if _, found := r.Get(f.name); !found {
+			t.Errorf("tool %s is not registered", f.name)
+		}
+	}
+
+	if got := len(r.List()); got != len(toolFixtures) {
+		t.Errorf("expected %d registered tools, got %d", len(toolFixtures), got)
+	}
+}
+
+func TestCallRejectsMissingRequiredParams(t *testing.T) {
+	r := NewRegistry()
+
+	for _, f := range toolFixtures {
+		for _, param := range f.required {
+			f, param := f, param
+			t.Run(f.name+"/"+param, func(t *testing.T) {
+				args := copyArgs(f.args)
+				delete(args, param)
+
+				if _, err := r.Call(f.name, args); err == nil {
+					t.Errorf("expected error when required parameter %s is missing", param)
+				}
+			})
+		}
+	}
+}
+
+func TestCallRejectsWrongTypes(t *testing.T) {
+	r := NewRegistry()
+
+	for _, f := range toolFixtures {
+		for param, value := range f.wrongTypes {
+			f, param, value := f, param, value
+			t.Run(f.name+"/"+param, func(t *testing.T) {
+				args := copyArgs(f.args)
+				args[param] = value
+
+				if _, err := r.Call(f.name, args); err == nil {
+					t.Errorf("expected error when parameter %s has type %T", param, value)
+				}
+			})
+		}
+	}
+}
+
+func TestToolsWithExampleArgs(t *testing.T) {
+	r := NewRegistry()
+
+	for _, f := range toolFixtures {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			result, err := r.Call(f.name, copyArgs(f.args))
+			if err != nil {
+				t.Fatalf("call with example args failed: %v", err)
+			}
+
+			if isStubResult(f.name, result) {
+				t.Errorf("%s still returns the generated stub result; implement it in tools.go", f.name)
+			}
+		})
+	}
+}
+`
+
+const contextTemplate = `// Code generated by spec-to-godemode. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+{{- if .Proxy}}
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+{{- end}}
+)
+
+// ResourceInfo describes a resource the server exposes for reading
+type ResourceInfo struct {
+	Name        string
+	Description string
+	MimeType    string
+	URI         string   // fixed URI, or an RFC 6570 template when Variables is set
+	Variables   []string // template variables
+}
+
+// PromptInfo describes a prompt template and its string arguments
+type PromptInfo struct {
+	Name        string
+	Description string
+	Arguments   []ParamInfo
+}
+
+// PromptMessage is a single message of a rendered prompt
+type PromptMessage struct {
+	Role string
+	Text string
+}
+
+// ResourceHandler returns the contents of the resource at a URI
+type ResourceHandler func(uri string) (string, error)
+
+// PromptHandler renders a prompt with already validated arguments
+type PromptHandler func(name string, args map[string]string) ([]PromptMessage, error)
+
+var (
+	contextMu       sync.RWMutex
+	resourceHandler ResourceHandler
+	promptHandler   PromptHandler
+)
+
+// SetResourceHandler sets the function that serves ReadResource
+func SetResourceHandler(h ResourceHandler) {
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	resourceHandler = h
+}
+
+// SetPromptHandler sets the function that serves GetPrompt
+func SetPromptHandler(h PromptHandler) {
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	promptHandler = h
+}
+
+// resources lists every resource in the spec
+var resources = []ResourceInfo{
+{{range .Resources}}	{{.}},
+{{end}}}
+
+// prompts lists every prompt in the spec
+var prompts = []PromptInfo{
+{{range .Prompts}}	{{.}},
+{{end}}}
+
+// ListResources returns every resource the server exposes
+func (r *Registry) ListResources() []ResourceInfo {
+	return append([]ResourceInfo(nil), resources...)
+}
+
+// ListPrompts returns every prompt the server exposes
+func (r *Registry) ListPrompts() []PromptInfo {
+	return append([]PromptInfo(nil), prompts...)
+}
+
+// ReadResource reads a resource by URI. The URI must be a fixed resource URI
+// or match one of the resource templates.
+func (r *Registry) ReadResource(uri string) (string, error) {
+	if !knownResource(uri) {
+		return "", fmt.Errorf("resource not found: %s", uri)
+	}
+
+	contextMu.RLock()
+	h := resourceHandler
+	contextMu.RUnlock()
+
+	if h == nil {
+		return "", fmt.Errorf("no resource handler set; call SetResourceHandler")
+	}
+	return h(uri)
+}
+
+// GetPrompt renders a prompt after checking that every required argument is
+// set and no unknown arguments are passed
+func (r *Registry) GetPrompt(name string, args map[string]string) ([]PromptMessage, error) {
+	var prompt *PromptInfo
+	for i := range prompts {
+		if prompts[i].Name == name {
+			prompt = &prompts[i]
+			break
+		}
+	}
+	if prompt == nil {
+		return nil, fmt.Errorf("prompt not found: %s", name)
+	}
+
+	var problems []string
+	known := make(map[string]bool, len(prompt.Arguments))
+	for _, arg := range prompt.Arguments {
+		known[arg.Name] = true
+		if arg.Required && args[arg.Name] == "" {
+			problems = append(problems, fmt.Sprintf("%s: required parameter is missing", arg.Name))
+		}
+	}
+	var unknown []string
+	for key := range args {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("%s: unknown parameter", key))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid arguments for prompt %s: %s", name, strings.Join(problems, "; "))
+	}
+
+	contextMu.RLock()
+	h := promptHandler
+	contextMu.RUnlock()
+
+	if h == nil {
+		return nil, fmt.Errorf("no prompt handler set; call SetPromptHandler")
+	}
+	return h(name, args)
+}
+
+// knownResource reports whether uri is a fixed resource URI or matches a template
+func knownResource(uri string) bool {
+	for _, resource := range resources {
+		if len(resource.Variables) == 0 {
+			if resource.URI == uri {
+				return true
+			}
+		} else if uriTemplatePatterns[resource.URI].MatchString(uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// uriTemplatePatterns holds the compiled pattern of each resource template,
+// so reads do not recompile them
+var uriTemplatePatterns = func() map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp)
+	for _, resource := range resources {
+		if len(resource.Variables) > 0 {
+			patterns[resource.URI] = uriTemplatePattern(resource.URI)
+		}
+	}
+	return patterns
+}()
+
+// uriTemplatePattern compiles a URI template into a regexp matching its expansions
+func uriTemplatePattern(template string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	rest := template
+	for {
+		start := strings.Index(rest, "{")
+		end := strings.Index(rest, "}")
+		if start < 0 || end < start {
+			sb.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		sb.WriteString(regexp.QuoteMeta(rest[:start]))
+		sb.WriteString("[^/]+")
+		rest = rest[end+1:]
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// expandURITemplate substitutes escaped variable values into a URI template
+func expandURITemplate(template string, vars map[string]string) string {
+	for name, value := range vars {
+		template = strings.ReplaceAll(template, "{"+name+"}", url.PathEscape(value))
+	}
+	return template
+}
+{{- if .Proxy}}
+
+func init() {
+	SetResourceHandler(readUpstreamResource)
+	SetPromptHandler(getUpstreamPrompt)
+}
+
+// readUpstreamResource reads a resource from the upstream MCP server
+func readUpstreamResource(uri string) (string, error) {
+	c, err := getUpstream()
+	if err != nil {
+		return "", err
+	}
+	provider, ok := c.(client.ContextProvider)
+	if !ok {
+		return "", fmt.Errorf("upstream MCP client cannot read resources")
+	}
+
+	result, err := provider.ReadResource(uri)
+	if err != nil {
+		return "", err
+	}
+	return client.ResourceText(result), nil
+}
+
+// getUpstreamPrompt renders a prompt on the upstream MCP server
+func getUpstreamPrompt(name string, args map[string]string) ([]PromptMessage, error) {
+	c, err := getUpstream()
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := c.(client.ContextProvider)
+	if !ok {
+		return nil, fmt.Errorf("upstream MCP client cannot render prompts")
+	}
+
+	result, err := provider.GetPrompt(name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]PromptMessage, len(result.Messages))
+	for i, message := range result.Messages {
+		messages[i] = PromptMessage{Role: message.Role, Text: message.Content.Text}
+	}
+	return messages, nil
+}
+{{- end}}
+
+// Typed resource accessors and prompt builders
+{{range .Accessors}}
+{{.}}
+{{end}}`
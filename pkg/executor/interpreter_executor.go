@@ -264,6 +264,12 @@ func (e *InterpreterExecutor) ExecuteWithSymbols(ctx context.Context, sourceCode
 // ExecuteGeneratedCode is a high-level API for executing LLM-generated code
 // It handles markdown extraction, code preprocessing, and registry injection
 func (e *InterpreterExecutor) ExecuteGeneratedCode(ctx context.Context, rawCode string, timeout time.Duration, registryCall func(string, map[string]interface{}) (interface{}, error)) (*ExecutionResult, error) {
+	return e.ExecuteGeneratedCodeWithHelpers(ctx, rawCode, timeout, registryCall, nil)
+}
+
+// ExecuteGeneratedCodeWithHelpers is ExecuteGeneratedCode with further
+// functions the code can call by name, e.g. to report its progress
+func (e *InterpreterExecutor) ExecuteGeneratedCodeWithHelpers(ctx context.Context, rawCode string, timeout time.Duration, registryCall func(string, map[string]interface{}) (interface{}, error), helpers map[string]interface{}) (*ExecutionResult, error) {
 	// Preprocess the code
 	preprocessor := NewCodePreprocessor()
 	processedCode := preprocessor.Process(rawCode, "registryCall")
@@ -282,6 +288,9 @@ func (e *InterpreterExecutor) ExecuteGeneratedCode(ctx context.Context, rawCode
 			"registryCall": registryCall,
 		},
 	}
+	for name, helper := range helpers {
+		symbols["main/main"][name] = helper
+	}
 
 	// Execute with the injected symbols
 	return e.ExecuteWithSymbols(ctx, processedCode, timeout, symbols)
@@ -296,8 +305,15 @@ func (e *InterpreterExecutor) executeWithCustomSymbols(ctx context.Context, sour
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Capture stdout and stderr. The interpreter binds fmt and os output to
+	// its streams when created, so they are passed in rather than swapped
+	// into os.Stdout and os.Stderr, which would also capture the host's output.
+	var stdout, stderr bytes.Buffer
+	stdoutR, stdoutW, _ := os.Pipe()
+	stderrR, stderrW, _ := os.Pipe()
+
 	// Create fresh interpreter (don't use pool for custom symbols)
-	i := interp.New(interp.Options{})
+	i := interp.New(interp.Options{Stdout: stdoutW, Stderr: stderrW})
 	i.Use(stdlib.Symbols)
 
 	// Inject custom symbols
@@ -312,17 +328,6 @@ func (e *InterpreterExecutor) executeWithCustomSymbols(ctx context.Context, sour
 		i.Use(reflectSymbols)
 	}
 
-	// Capture stdout and stderr
-	var stdout, stderr bytes.Buffer
-	originalStdout := os.Stdout
-	originalStderr := os.Stderr
-
-	stdoutR, stdoutW, _ := os.Pipe()
-	stderrR, stderrW, _ := os.Pipe()
-
-	os.Stdout = stdoutW
-	os.Stderr = stderrW
-
 	done := make(chan error, 1)
 
 	go func() {
@@ -336,10 +341,14 @@ func (e *InterpreterExecutor) executeWithCustomSymbols(ctx context.Context, sour
 		done <- err
 	}()
 
+	var copying sync.WaitGroup
+	copying.Add(2)
 	go func() {
+		defer copying.Done()
 		io.Copy(&stdout, stdoutR)
 	}()
 	go func() {
+		defer copying.Done()
 		io.Copy(&stderr, stderrR)
 	}()
 
@@ -350,12 +359,11 @@ func (e *InterpreterExecutor) executeWithCustomSymbols(ctx context.Context, sour
 		err = execCtx.Err()
 	}
 
+	// Closing the writers ends both copies once the pipes are drained, so the
+	// buffers are complete and no longer written to when they are read
 	stdoutW.Close()
 	stderrW.Close()
-	os.Stdout = originalStdout
-	os.Stderr = originalStderr
-
-	time.Sleep(10 * time.Millisecond)
+	copying.Wait()
 
 	stdoutR.Close()
 	stderrR.Close()
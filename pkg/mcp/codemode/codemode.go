@@ -0,0 +1,250 @@
+// Package codemode exposes code mode itself as MCP tools. Instead of one MCP
+// tool per registry tool, a client gets execute_go, which runs a Go program
+// calling the registry through registry.Call, and list_api, which documents
+// the tools the program can call:
+//
+//	provider, err := codemode.NewProvider(tools.NewRegistry(), codemode.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	srv := server.New(provider, server.Options{Name: "tools-code-mode"})
+//	log.Fatal(srv.ServeStdio(context.Background(), os.Stdin, os.Stdout))
+package codemode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imran31415/godemode/pkg/codegen"
+	"github.com/imran31415/godemode/pkg/executor"
+	"github.com/imran31415/godemode/pkg/mcp/server"
+	"github.com/imran31415/godemode/pkg/spec"
+	"github.com/imran31415/godemode/pkg/toolsearch"
+)
+
+// Tool names served by a Provider
+const (
+	ExecuteToolName = "execute_go"
+	ListAPIToolName = "list_api"
+)
+
+// defaultListLimit caps how many tools a list_api query documents
+const defaultListLimit = 10
+
+// Options configures a Provider
+type Options struct {
+	// Timeout bounds each execution (default 30s)
+	Timeout time.Duration
+	// DisableListAPI serves execute_go alone. Its description still lists
+	// the tool signatures.
+	DisableListAPI bool
+	// DocsTokenBudget bounds the signatures embedded in the execute_go
+	// description (default 1000 tokens)
+	DocsTokenBudget int
+}
+
+// Provider serves execute_go and list_api for a registry
+type Provider struct {
+	registry server.Caller
+	tools    []spec.ToolDefinition
+	index    *toolsearch.Index
+	executor *executor.InterpreterExecutor
+	opts     Options
+}
+
+// ExecutionOutput is the result of execute_go
+type ExecutionOutput struct {
+	Success    bool        `json:"success"`
+	Stdout     string      `json:"stdout"`
+	Stderr     string      `json:"stderr,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Result     interface{} `json:"result,omitempty"` // stdout decoded, when it is a single JSON value
+	Trace      []ToolCall  `json:"trace"`
+	DurationMs float64     `json:"durationMs"`
+}
+
+// ToolCall records one registry call made by the executed program
+type ToolCall struct {
+	Tool       string                 `json:"tool"`
+	Args       map[string]interface{} `json:"args"`
+	Result     interface{}            `json:"result,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMs float64                `json:"durationMs"`
+}
+
+// NewProvider creates a provider running code against registry. The tools
+// are read with spec.FromRegistry, so any generated registry works.
+func NewProvider(registry server.Caller, opts Options) (*Provider, error) {
+	tools, err := spec.FromRegistry(registry)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.DocsTokenBudget == 0 {
+		opts.DocsTokenBudget = 1000
+	}
+
+	return &Provider{
+		registry: registry,
+		tools:    tools,
+		index:    toolsearch.NewIndex(tools),
+		executor: executor.NewInterpreterExecutor(),
+		opts:     opts,
+	}, nil
+}
+
+// Tools describes execute_go, with the registry's tool signatures in its
+// description, and list_api unless disabled
+func (p *Provider) Tools() []spec.ToolDefinition {
+	var description strings.Builder
+	description.WriteString("Run a Go program (package main with a main function) that calls tools with ")
+	description.WriteString("registry.Call(name string, args map[string]interface{}) (interface{}, error). ")
+	description.WriteString("Returns stdout, stderr, the tool calls made and, when stdout is a single JSON value, that value as result. ")
+	description.WriteString("Long programs can call reportProgress(done, total float64, message string) and logMessage(level string, data interface{}). ")
+	if !p.opts.DisableListAPI {
+		description.WriteString(fmt.Sprintf("Call %s for parameter docs. ", ListAPIToolName))
+	}
+	description.WriteString("Available tools:\n")
+	description.WriteString(codegen.RenderAPIDocs(p.tools, codegen.DocOptions{
+		Verbosity:   codegen.VerbositySignatures,
+		TokenBudget: p.opts.DocsTokenBudget,
+	}))
+
+	tools := []spec.ToolDefinition{{
+		Name:        ExecuteToolName,
+		Description: description.String(),
+		Parameters: []spec.Parameter{
+			{Name: "code", Type: "string", Description: "Go source code of the program", Required: true},
+		},
+		Output: []spec.Parameter{
+			{Name: "success", Type: "boolean", Description: "Whether the program compiled and ran without error", Required: true},
+			{Name: "stdout", Type: "string", Required: true},
+			{Name: "stderr", Type: "string"},
+			{Name: "error", Type: "string", Description: "Why the program failed"},
+			{Name: "result", Description: "stdout decoded, when it is a single JSON value"},
+			{Name: "trace", Type: "array", Description: "The tool calls made, in order", Required: true},
+			{Name: "durationMs", Type: "number", Required: true},
+		},
+	}}
+	if !p.opts.DisableListAPI {
+		// list_api only reads the registry's docs
+		readOnly, closedWorld := true, false
+		tools = append(tools, spec.ToolDefinition{
+			Name:        ListAPIToolName,
+			Description: "Document the tools execute_go programs can call, all of them or those matching a search query",
+			Annotations: &spec.ToolAnnotations{ReadOnlyHint: &readOnly, OpenWorldHint: &closedWorld},
+			Parameters: []spec.Parameter{
+				{Name: "query", Type: "string", Description: "What the tools should do; omit to list every tool"},
+				{Name: "limit", Type: "integer", Description: "Maximum number of tools to return for a query", Default: defaultListLimit},
+			},
+		})
+	}
+	return tools
+}
+
+// CallTool runs execute_go or list_api
+func (p *Provider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch {
+	case name == ExecuteToolName:
+		code, _ := args["code"].(string)
+		if strings.TrimSpace(code) == "" {
+			return nil, fmt.Errorf("code is required")
+		}
+		return p.Execute(ctx, code), nil
+	case name == ListAPIToolName && !p.opts.DisableListAPI:
+		query, _ := args["query"].(string)
+		limit := defaultListLimit
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = int(l)
+		}
+		return p.ListAPI(query, limit), nil
+	}
+	return nil, fmt.Errorf("unknown tool: %s", name)
+}
+
+// Execute runs a program against the registry. Failures to compile or run
+// are reported in the output rather than as an error, so the caller still
+// sees the output and tool calls made before the failure. The program's
+// reportProgress and logMessage calls go to the server.Emitter of ctx, which
+// registries implementing server.ContextCaller also receive.
+func (p *Provider) Execute(ctx context.Context, code string) *ExecutionOutput {
+	var traceMu sync.Mutex
+	trace := []ToolCall{}
+	registryCall := func(name string, args map[string]interface{}) (interface{}, error) {
+		start := time.Now()
+		var result interface{}
+		var err error
+		if caller, ok := p.registry.(server.ContextCaller); ok {
+			result, err = caller.CallContext(ctx, name, args)
+		} else {
+			result, err = p.registry.Call(name, args)
+		}
+
+		call := ToolCall{Tool: name, Args: args, Result: result, DurationMs: milliseconds(time.Since(start))}
+		if err != nil {
+			call.Error = err.Error()
+		}
+		traceMu.Lock()
+		trace = append(trace, call)
+		traceMu.Unlock()
+		return result, err
+	}
+
+	emit := server.EmitterFromContext(ctx)
+	helpers := map[string]interface{}{
+		"reportProgress": func(done, total float64, message string) {
+			emit.Progress(done, total, message)
+		},
+		"logMessage": func(level string, data interface{}) {
+			emit.Log(level, ExecuteToolName, data)
+		},
+	}
+
+	start := time.Now()
+	result, err := p.executor.ExecuteGeneratedCodeWithHelpers(ctx, code, p.opts.Timeout, registryCall, helpers)
+	duration := time.Since(start)
+
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	output := &ExecutionOutput{
+		Success:    err == nil && result.Success,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		Error:      result.Error,
+		Trace:      trace,
+		DurationMs: milliseconds(duration),
+	}
+	if output.Error == "" && err != nil {
+		output.Error = err.Error()
+	}
+
+	var value interface{}
+	if stdout := strings.TrimSpace(result.Stdout); stdout != "" && json.Unmarshal([]byte(stdout), &value) == nil {
+		output.Result = value
+	}
+	return output
+}
+
+// ListAPI documents every tool, or the limit tools most relevant to query
+func (p *Provider) ListAPI(query string, limit int) string {
+	if query == "" {
+		return codegen.RenderAPIDocs(p.tools, codegen.DocOptions{Verbosity: codegen.VerbosityFull})
+	}
+
+	selected := p.index.Select(query, limit)
+	if len(selected) == 0 {
+		return fmt.Sprintf("No tools match %q.", query)
+	}
+	return codegen.RenderAPIDocs(selected, codegen.DocOptions{Verbosity: codegen.VerbosityFull})
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
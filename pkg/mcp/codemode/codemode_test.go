@@ -0,0 +1,178 @@
+package codemode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/mcp/server"
+)
+
+type testParamInfo struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+type testToolInfo struct {
+	Name        string
+	Description string
+	Parameters  []testParamInfo
+}
+
+type testRegistry struct{}
+
+func (testRegistry) ListTools() []*testToolInfo {
+	return []*testToolInfo{
+		{Name: "add", Description: "Add two numbers", Parameters: []testParamInfo{
+			{Name: "a", Type: "float64", Required: true},
+			{Name: "b", Type: "float64", Required: true},
+		}},
+		{Name: "sendEmail", Description: "Send an email to a recipient", Parameters: []testParamInfo{
+			{Name: "to", Type: "string", Required: true},
+		}},
+	}
+}
+
+func (testRegistry) Call(name string, args map[string]interface{}) (interface{}, error) {
+	if name != "add" {
+		return nil, fmt.Errorf("tool not found: %s", name)
+	}
+	return args["a"].(float64) + args["b"].(float64), nil
+}
+
+func TestTools(t *testing.T) {
+	provider, err := NewProvider(testRegistry{}, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	tools := provider.Tools()
+	if len(tools) != 2 || tools[0].Name != ExecuteToolName || tools[1].Name != ListAPIToolName {
+		t.Fatalf("Expected execute_go and list_api, got %v", tools)
+	}
+	if !strings.Contains(tools[0].Description, "add(a float64, b float64)") {
+		t.Errorf("Expected signatures in the execute_go description, got %q", tools[0].Description)
+	}
+
+	provider, _ = NewProvider(testRegistry{}, Options{DisableListAPI: true})
+	if tools := provider.Tools(); len(tools) != 1 {
+		t.Errorf("Expected only execute_go, got %v", tools)
+	}
+	if _, err := provider.CallTool(context.Background(), ListAPIToolName, nil); err == nil {
+		t.Error("Expected list_api to be unknown when disabled")
+	}
+}
+
+func TestExecute(t *testing.T) {
+	provider, err := NewProvider(testRegistry{}, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	output := provider.Execute(context.Background(), "```go\n"+`package main
+
+import "fmt"
+
+func main() {
+	sum, _ := registry.Call("add", map[string]interface{}{"a": 2.0, "b": 3.0})
+	_, err := registry.Call("missing", map[string]interface{}{})
+	fmt.Printf("{\"sum\": %v, \"failed\": %v}\n", sum, err != nil)
+}
+`+"```")
+
+	if !output.Success {
+		t.Fatalf("Expected success, got %+v", output)
+	}
+	result, ok := output.Result.(map[string]interface{})
+	if !ok || result["sum"] != float64(5) || result["failed"] != true {
+		t.Errorf("Expected stdout decoded as result, got %v", output.Result)
+	}
+	if len(output.Trace) != 2 || output.Trace[0].Tool != "add" || output.Trace[0].Result != float64(5) {
+		t.Errorf("Unexpected trace: %+v", output.Trace)
+	}
+	if !strings.Contains(output.Trace[1].Error, "tool not found") {
+		t.Errorf("Expected failed call in trace, got %+v", output.Trace[1])
+	}
+
+	output = provider.Execute(context.Background(), `fmt.Println("no main")`)
+	if output.Success || !strings.Contains(output.Error, "package main") {
+		t.Errorf("Expected validation failure, got %+v", output)
+	}
+
+	output = provider.Execute(context.Background(), "package main\n\nfunc main() {\n\tundefinedCall()\n}\n")
+	if output.Success || output.Error == "" {
+		t.Errorf("Expected compile failure, got %+v", output)
+	}
+}
+
+func TestListAPI(t *testing.T) {
+	provider, _ := NewProvider(testRegistry{}, Options{})
+
+	all := provider.ListAPI("", 0)
+	if !strings.Contains(all, "add(") || !strings.Contains(all, "sendEmail(") {
+		t.Errorf("Expected every tool, got %q", all)
+	}
+
+	email := provider.ListAPI("send an email", 1)
+	if !strings.Contains(email, "sendEmail(") || strings.Contains(email, "add(") {
+		t.Errorf("Expected only sendEmail, got %q", email)
+	}
+}
+
+func TestServeCodeMode(t *testing.T) {
+	provider, _ := NewProvider(testRegistry{}, Options{})
+	srv := server.New(provider, server.Options{})
+
+	resp := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"execute_go","arguments":{"code":"package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(42)\n}\n"}}}`))
+
+	var decoded struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &decoded); err != nil || len(decoded.Result.Content) == 0 {
+		t.Fatalf("Unexpected response %s: %v", resp, err)
+	}
+	var output ExecutionOutput
+	if err := json.Unmarshal([]byte(decoded.Result.Content[0].Text), &output); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if !output.Success || output.Result != float64(42) {
+		t.Errorf("Unexpected output: %+v", output)
+	}
+}
+
+func TestExecuteNotifications(t *testing.T) {
+	provider, _ := NewProvider(testRegistry{}, Options{})
+	srv := server.New(provider, server.Options{})
+
+	code := "package main\n\nfunc main() {\n\treportProgress(1, 2, \"first\")\n\tlogMessage(\"warning\", map[string]interface{}{\"step\": 1})\n\tlogMessage(\"debug\", \"filtered\")\n}\n"
+	args, _ := json.Marshal(map[string]interface{}{"code": code})
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1"}}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"execute_go","arguments":%s,"_meta":{"progressToken":"run"}}}`, args),
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	if err := srv.ServeStdio(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		`"method":"notifications/progress","params":{"progressToken":"run","progress":1,"total":2,"message":"first"}`,
+		`"method":"notifications/message","params":{"level":"warning","logger":"execute_go","data":{"step":1}}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %s in output:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "filtered") {
+		t.Errorf("Expected debug messages to be filtered at the default level:\n%s", got)
+	}
+}
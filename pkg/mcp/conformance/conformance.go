@@ -0,0 +1,431 @@
+// Package conformance checks that an MCP server follows the JSON-RPC and MCP
+// rules clients rely on. Run drives a server through initialize, ping,
+// tools/list and tools/call, and through the edge cases clients hit less
+// often: malformed and invalid messages, unknown methods and tools,
+// notifications, which must never be answered, and batches. Every rule
+// broken is reported as a violation:
+//
+//	conn, err := conformance.DialStdio("./my-mcp-server")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer conn.Close()
+//	report := conformance.Run(context.Background(), conn, conformance.Options{})
+//	fmt.Print(report)
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// Options configures a conformance run
+type Options struct {
+	ProtocolVersion string        // sent in initialize (default "2025-06-18")
+	Timeout         time.Duration // per check (default 10s)
+
+	// Call, when set, is a tool call expected to succeed. Without it only
+	// calls that must fail are made, so running checks has no side effects.
+	Call *ToolCall
+
+	// SkipBatches leaves out the batch checks, for servers speaking only
+	// MCP revisions without JSON-RPC batching
+	SkipBatches bool
+}
+
+// ToolCall is a tools/call request
+type ToolCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Check     string `json:"check"`
+	Violation string `json:"violation,omitempty"` // empty when the check passed
+}
+
+// Passed reports whether the server passed the check
+func (r Result) Passed() bool {
+	return r.Violation == ""
+}
+
+// Report is the result of a conformance run, in the order checks ran
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Violations returns the checks the server failed
+func (r Report) Violations() []Result {
+	var violations []Result
+	for _, result := range r.Results {
+		if !result.Passed() {
+			violations = append(violations, result)
+		}
+	}
+	return violations
+}
+
+// Passed reports whether the server passed every check
+func (r Report) Passed() bool {
+	return len(r.Violations()) == 0
+}
+
+// String renders the report for humans, one check per line
+func (r Report) String() string {
+	var sb strings.Builder
+	for _, result := range r.Results {
+		if result.Passed() {
+			sb.WriteString(fmt.Sprintf("PASS %s\n", result.Check))
+		} else {
+			sb.WriteString(fmt.Sprintf("FAIL %s: %s\n", result.Check, result.Violation))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("%d check(s), %d violation(s)\n", len(r.Results), len(r.Violations())))
+	return sb.String()
+}
+
+// check is one conformance check. run returns the violation, or "" when the
+// server conforms.
+type check struct {
+	name string
+	run  func(ctx context.Context, r *runner) string
+}
+
+// runner holds the state of a conformance run
+type runner struct {
+	conn Conn
+	opts Options
+}
+
+// reply is a JSON-RPC response as received
+type reply struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Run runs every check against the server behind conn. The first check
+// initializes the connection; when it fails, no other check is run.
+func Run(ctx context.Context, conn Conn, opts Options) Report {
+	if opts.ProtocolVersion == "" {
+		opts.ProtocolVersion = "2025-06-18"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	r := &runner{conn: conn, opts: opts}
+	var report Report
+	for _, c := range r.checks() {
+		checkCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		violation := c.run(checkCtx, r)
+		cancel()
+		report.Results = append(report.Results, Result{Check: c.name, Violation: violation})
+		if c.name == "initialize" && violation != "" {
+			break
+		}
+	}
+	return report
+}
+
+// checks lists the checks in the order they run
+func (r *runner) checks() []check {
+	checks := []check{
+		{"initialize", checkInitialize},
+		{"initialized notification is not answered", func(ctx context.Context, r *runner) string {
+			return r.expectNone(ctx, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+		}},
+		{"ping", func(ctx context.Context, r *runner) string {
+			result, violation := r.expectResult(ctx, request(`2`, "ping", nil), `2`)
+			if violation == "" && !isEmptyObject(result) {
+				violation = fmt.Sprintf("expected an empty result, got %s", result)
+			}
+			return violation
+		}},
+		{"string ids are echoed", func(ctx context.Context, r *runner) string {
+			_, violation := r.expectResult(ctx, request(`"conformance-ping"`, "ping", nil), `"conformance-ping"`)
+			return violation
+		}},
+		{"tools/list", checkToolsList},
+	}
+	if r.opts.Call != nil {
+		checks = append(checks, check{"tools/call", checkToolsCall})
+	}
+
+	checks = append(checks,
+		check{"unknown tool is invalid params", func(ctx context.Context, r *runner) string {
+			params := map[string]interface{}{"name": "conformance-unknown-tool", "arguments": map[string]interface{}{}}
+			return r.expectError(ctx, request(`10`, "tools/call", params), `10`, protocol.InvalidParams)
+		}},
+		check{"unknown method is method not found", func(ctx context.Context, r *runner) string {
+			return r.expectError(ctx, request(`11`, "conformance/unknown", nil), `11`, protocol.MethodNotFound)
+		}},
+		check{"malformed params are invalid params", func(ctx context.Context, r *runner) string {
+			return r.expectError(ctx, request(`12`, "tools/call", map[string]interface{}{"name": 42}), `12`, protocol.InvalidParams)
+		}},
+		check{"malformed JSON is a parse error", func(ctx context.Context, r *runner) string {
+			return r.expectError(ctx, `{"jsonrpc":"2.0","id":13,"method":"ping"`, `null`, protocol.ParseError)
+		}},
+		check{"non-string method is an invalid request", func(ctx context.Context, r *runner) string {
+			return r.expectError(ctx, `{"jsonrpc":"2.0","id":14,"method":42}`, `14`, protocol.InvalidRequest)
+		}},
+		check{"wrong jsonrpc version is an invalid request", func(ctx context.Context, r *runner) string {
+			return r.expectError(ctx, `{"jsonrpc":"1.0","id":15,"method":"ping"}`, `15`, protocol.InvalidRequest)
+		}},
+		check{"null id is an invalid request", func(ctx context.Context, r *runner) string {
+			return r.expectError(ctx, `{"jsonrpc":"2.0","id":null,"method":"ping"}`, `null`, protocol.InvalidRequest)
+		}},
+		check{"unknown notification is not answered", func(ctx context.Context, r *runner) string {
+			return r.expectNone(ctx, `{"jsonrpc":"2.0","method":"notifications/conformance"}`)
+		}},
+		check{"request without id is not answered", func(ctx context.Context, r *runner) string {
+			return r.expectNone(ctx, `{"jsonrpc":"2.0","method":"ping"}`)
+		}},
+	)
+
+	if !r.opts.SkipBatches {
+		checks = append(checks,
+			check{"batch", checkBatch},
+			check{"batch of notifications is not answered", func(ctx context.Context, r *runner) string {
+				return r.expectNone(ctx, `[{"jsonrpc":"2.0","method":"notifications/conformance"},{"jsonrpc":"2.0","method":"notifications/conformance"}]`)
+			}},
+			check{"empty batch is an invalid request", func(ctx context.Context, r *runner) string {
+				return r.expectError(ctx, `[]`, `null`, protocol.InvalidRequest)
+			}},
+		)
+	}
+	return checks
+}
+
+// checkInitialize initializes the connection and checks the result has
+// what clients read of it
+func checkInitialize(ctx context.Context, r *runner) string {
+	params := map[string]interface{}{
+		"protocolVersion": r.opts.ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "godemode-conformance", "version": "1.0.0"},
+	}
+	raw, violation := r.expectResult(ctx, request(`1`, "initialize", params), `1`)
+	if violation != "" {
+		return violation
+	}
+
+	var result struct {
+		ProtocolVersion string                     `json:"protocolVersion"`
+		Capabilities    map[string]json.RawMessage `json:"capabilities"`
+		ServerInfo      *protocol.ServerInfo       `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return fmt.Sprintf("malformed initialize result: %v", err)
+	}
+	switch {
+	case result.ProtocolVersion == "":
+		return "protocolVersion is missing"
+	case result.Capabilities == nil:
+		return "capabilities are missing"
+	case result.ServerInfo == nil || result.ServerInfo.Name == "":
+		return "serverInfo.name is missing"
+	}
+	if _, ok := result.Capabilities["tools"]; !ok {
+		return "the tools capability is missing"
+	}
+	return ""
+}
+
+// checkToolsList checks every listed tool has a name and an object schema
+func checkToolsList(ctx context.Context, r *runner) string {
+	raw, violation := r.expectResult(ctx, request(`3`, "tools/list", nil), `3`)
+	if violation != "" {
+		return violation
+	}
+
+	var result struct {
+		Tools []struct {
+			Name        string                 `json:"name"`
+			InputSchema map[string]interface{} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil || result.Tools == nil {
+		return fmt.Sprintf("expected a tools array, got %s", raw)
+	}
+	for i, tool := range result.Tools {
+		if tool.Name == "" {
+			return fmt.Sprintf("tool %d has no name", i)
+		}
+		if tool.InputSchema["type"] != "object" {
+			return fmt.Sprintf("tool %s: inputSchema must be an object schema", tool.Name)
+		}
+	}
+	return ""
+}
+
+// checkToolsCall makes Options.Call, which must return content without an
+// error
+func checkToolsCall(ctx context.Context, r *runner) string {
+	raw, violation := r.expectResult(ctx, request(`4`, "tools/call", r.opts.Call), `4`)
+	if violation != "" {
+		return violation
+	}
+
+	var result struct {
+		Content []map[string]interface{} `json:"content"`
+		IsError bool                     `json:"isError"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil || result.Content == nil {
+		return fmt.Sprintf("expected a content array, got %s", raw)
+	}
+	for i, content := range result.Content {
+		if content["type"] == nil {
+			return fmt.Sprintf("content %d has no type", i)
+		}
+	}
+	if result.IsError {
+		return fmt.Sprintf("the call of %s failed: %s", r.opts.Call.Name, raw)
+	}
+	return ""
+}
+
+// checkBatch sends two requests and a notification in one batch, which must
+// be answered with the two responses in an array
+func checkBatch(ctx context.Context, r *runner) string {
+	message := `[` + request(`20`, "ping", nil) + `,{"jsonrpc":"2.0","method":"notifications/conformance"},` + request(`21`, "ping", nil) + `]`
+	data, err := r.conn.Send(ctx, []byte(message), true)
+	if err != nil {
+		return err.Error()
+	}
+
+	var replies []reply
+	if err := json.Unmarshal(data, &replies); err != nil {
+		return fmt.Sprintf("expected an array of responses, got %s", data)
+	}
+	if len(replies) != 2 {
+		return fmt.Sprintf("expected 2 responses, got %d", len(replies))
+	}
+	seen := map[string]bool{}
+	for _, resp := range replies {
+		if violation := checkReply(resp); violation != "" {
+			return violation
+		}
+		if resp.Error != nil {
+			return fmt.Sprintf("ping %s failed: %s", resp.ID, resp.Error.Message)
+		}
+		seen[string(bytes.TrimSpace(resp.ID))] = true
+	}
+	if !seen["20"] || !seen["21"] {
+		return "expected responses to ids 20 and 21"
+	}
+	return ""
+}
+
+// expectResult sends a request that must succeed and returns its result
+func (r *runner) expectResult(ctx context.Context, message, id string) (json.RawMessage, string) {
+	resp, err := r.send(ctx, message, id)
+	if err != nil {
+		return nil, err.Error()
+	}
+	if resp.Error != nil {
+		return nil, fmt.Sprintf("expected a result, got error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+	return resp.Result, ""
+}
+
+// expectError sends a message that must be answered with the error code.
+// Over HTTP, messages that are no valid request may instead be rejected with
+// 400 Bad Request.
+func (r *runner) expectError(ctx context.Context, message, id string, code int) string {
+	resp, err := r.send(ctx, message, id)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Code == http.StatusBadRequest &&
+		(code == protocol.ParseError || code == protocol.InvalidRequest) {
+		return ""
+	}
+	if err != nil {
+		return err.Error()
+	}
+	if resp.Error == nil {
+		return fmt.Sprintf("expected error %d, got result %s", code, resp.Result)
+	}
+	if resp.Error.Code != code {
+		return fmt.Sprintf("expected error %d, got %d: %s", code, resp.Error.Code, resp.Error.Message)
+	}
+	return ""
+}
+
+// expectNone sends a message that must not be answered
+func (r *runner) expectNone(ctx context.Context, message string) string {
+	data, err := r.conn.Send(ctx, []byte(message), false)
+	if err != nil {
+		return err.Error()
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		return fmt.Sprintf("expected no response, got %s", data)
+	}
+	return ""
+}
+
+// send sends a message expecting one response with the id, and checks the
+// response's envelope. Transport errors are returned as they are.
+func (r *runner) send(ctx context.Context, message, id string) (reply, error) {
+	data, err := r.conn.Send(ctx, []byte(message), true)
+	if err != nil {
+		return reply{}, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return reply{}, errors.New("expected a response, got none")
+	}
+
+	var resp reply
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return reply{}, fmt.Errorf("expected a response object, got %s", data)
+	}
+	if violation := checkReply(resp); violation != "" {
+		return reply{}, errors.New(violation)
+	}
+	if got := string(bytes.TrimSpace(resp.ID)); got != id {
+		if got == "" {
+			got = "none"
+		}
+		return reply{}, fmt.Errorf("expected id %s, got %s", id, got)
+	}
+	return resp, nil
+}
+
+// checkReply checks the envelope of a response
+func checkReply(resp reply) string {
+	if resp.JSONRPC != "2.0" {
+		return fmt.Sprintf("expected jsonrpc \"2.0\", got %q", resp.JSONRPC)
+	}
+	if (resp.Result != nil) == (resp.Error != nil) {
+		return "a response must have exactly one of result and error"
+	}
+	return ""
+}
+
+// request encodes a request with the id, given as JSON
+func request(id, method string, params interface{}) string {
+	message := map[string]interface{}{"jsonrpc": "2.0", "id": json.RawMessage(id), "method": method}
+	if params != nil {
+		message["params"] = params
+	}
+	data, _ := json.Marshal(message)
+	return string(data)
+}
+
+// isEmptyObject reports whether data is {}
+func isEmptyObject(data json.RawMessage) bool {
+	var fields map[string]json.RawMessage
+	return json.Unmarshal(data, &fields) == nil && fields != nil && len(fields) == 0
+}
@@ -0,0 +1,103 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// sloppyHandler answers every message it can decode with an empty result,
+// notifications included, and everything else with a parse error carrying
+// no id
+func sloppyHandler(ctx context.Context, message []byte) []byte {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(message, &req); err != nil {
+		return []byte(`{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"}}`)
+	}
+	if req.ID == nil {
+		req.ID = json.RawMessage(`null`)
+	}
+	result := `{}`
+	if req.Method == "initialize" {
+		result = `{"protocolVersion":"2025-06-18","capabilities":{"tools":{}},"serverInfo":{"name":"sloppy","version":"1"}}`
+	} else if req.Method == "tools/list" {
+		result = `{"tools":[{"name":"echo","inputSchema":{"type":"object"}},{"name":"bare"}]}`
+	}
+	return []byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + result + `}`)
+}
+
+func TestRunReportsViolations(t *testing.T) {
+	report := Run(context.Background(), HandlerConn(sloppyHandler), Options{})
+
+	want := map[string]string{
+		"initialized notification is not answered": "expected no response",
+		"tools/list":                                  "tool bare: inputSchema",
+		"unknown tool is invalid params":              "expected error -32602, got result",
+		"unknown method is method not found":          "expected error -32601",
+		"malformed JSON is a parse error":             "expected id null, got none",
+		"non-string method is an invalid request":     "expected id 14, got none",
+		"wrong jsonrpc version is an invalid request": "expected error -32600",
+		"null id is an invalid request":               "expected error -32600",
+		"malformed params are invalid params":         "expected error -32602",
+		"unknown notification is not answered":        "expected no response",
+		"request without id is not answered":          "expected no response",
+		"batch of notifications is not answered":      "expected no response",
+		"batch":                                       "expected an array of responses",
+		"empty batch is an invalid request":           "expected id null, got none",
+	}
+	for _, result := range report.Results {
+		if fragment, ok := want[result.Check]; ok {
+			if !strings.Contains(result.Violation, fragment) {
+				t.Errorf("%s: expected a violation containing %q, got %q", result.Check, fragment, result.Violation)
+			}
+		} else if !result.Passed() {
+			t.Errorf("%s: unexpected violation %q", result.Check, result.Violation)
+		}
+	}
+	if report.Passed() || !strings.Contains(report.String(), "PASS ping\n") {
+		t.Errorf("Unexpected report:\n%s", report)
+	}
+}
+
+func TestRunStopsWhenInitializeFails(t *testing.T) {
+	report := Run(context.Background(), HandlerConn(func(ctx context.Context, message []byte) []byte {
+		return nil
+	}), Options{})
+	if len(report.Results) != 1 || report.Results[0].Violation != "expected a response, got none" {
+		t.Errorf("Expected only the failed initialize, got:\n%s", report)
+	}
+}
+
+func TestStreamConn(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	go func() {
+		// Answers notifications as well, and logs before every response
+		decoder := json.NewDecoder(inR)
+		for {
+			var message json.RawMessage
+			if decoder.Decode(&message) != nil {
+				outW.Close()
+				return
+			}
+			io.WriteString(outW, `{"jsonrpc":"2.0","method":"notifications/message","params":{}}`+"\n")
+			outW.Write(append(sloppyHandler(context.Background(), message), '\n'))
+		}
+	}()
+	conn := NewStreamConn(outR, inW)
+	defer conn.Close()
+
+	data, err := conn.Send(context.Background(), []byte(`{"jsonrpc":"2.0","id":7,"method":"ping"}`), true)
+	if err != nil || string(data) != `{"jsonrpc":"2.0","id":7,"result":{}}` {
+		t.Errorf("Expected the ping's response, got %s (%v)", data, err)
+	}
+	data, err = conn.Send(context.Background(), []byte(`{"jsonrpc":"2.0","method":"ping"}`), false)
+	if err != nil || string(data) != `{"jsonrpc":"2.0","id":null,"result":{}}` {
+		t.Errorf("Expected the spurious response, got %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,306 @@
+package conformance
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Conn carries the messages of a conformance run to the server under test
+type Conn interface {
+	// Send sends a JSON-RPC message or batch and returns the server's reply,
+	// or nil when it sent none. reply tells whether the check expects one;
+	// connections that cannot tell a missing reply from a late one use it to
+	// decide how to wait.
+	Send(ctx context.Context, message []byte, reply bool) ([]byte, error)
+	Close() error
+}
+
+// StatusError is returned by HTTP connections when the server rejects a
+// message with an HTTP error status and no JSON-RPC error
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("HTTP %d", e.Code)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.Code, e.Body)
+}
+
+// HandlerConn drives a server through a function handling one message or
+// batch, such as server.Server.HandleMessage
+func HandlerConn(handle func(ctx context.Context, message []byte) []byte) Conn {
+	return handlerConn(handle)
+}
+
+type handlerConn func(ctx context.Context, message []byte) []byte
+
+func (h handlerConn) Send(ctx context.Context, message []byte, reply bool) ([]byte, error) {
+	return h(ctx, message), nil
+}
+
+func (h handlerConn) Close() error {
+	return nil
+}
+
+// streamConn exchanges newline-delimited messages, as the stdio transport does
+type streamConn struct {
+	w      io.Writer
+	closer func() error
+
+	writeMu sync.Mutex
+	lines   chan []byte
+	readErr error // set before lines is closed
+	probes  int
+}
+
+// NewStreamConn drives a server that reads newline-delimited messages from
+// w and writes its own to r. Requests and notifications the server sends
+// are skipped. Whether a message was answered is learned by following it
+// with a ping: a reply arriving before the ping's response belongs to it.
+// Close closes w when it is an io.Closer.
+func NewStreamConn(r io.Reader, w io.Writer) Conn {
+	c := &streamConn{w: w, lines: make(chan []byte, 16)}
+	c.closer = func() error {
+		if closer, ok := w.(io.Closer); ok {
+			return closer.Close()
+		}
+		return nil
+	}
+	go c.read(r)
+	return c
+}
+
+// DialStdio starts a server command and drives it over stdio. Close stops
+// the server.
+func DialStdio(command string) (Conn, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("empty server command")
+	}
+	cmd := exec.Command(fields[0], fields[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", fields[0], err)
+	}
+
+	c := NewStreamConn(stdout, stdin).(*streamConn)
+	c.closer = func() error {
+		stdin.Close()
+		done := make(chan struct{})
+		go func() {
+			cmd.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			cmd.Process.Kill()
+			<-done
+		}
+		return nil
+	}
+	return c, nil
+}
+
+// read passes the lines of r on until it ends
+func (c *streamConn) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) > 0 {
+			c.lines <- append([]byte(nil), line...)
+		}
+	}
+	c.readErr = scanner.Err()
+	if c.readErr == nil {
+		c.readErr = io.EOF
+	}
+	close(c.lines)
+}
+
+func (c *streamConn) Send(ctx context.Context, message []byte, reply bool) ([]byte, error) {
+	if err := c.write(message); err != nil {
+		return nil, err
+	}
+	if reply {
+		return c.next(ctx)
+	}
+
+	c.probes++
+	probeID := fmt.Sprintf(`"conformance-probe-%d"`, c.probes)
+	if err := c.write([]byte(`{"jsonrpc":"2.0","id":` + probeID + `,"method":"ping"}`)); err != nil {
+		return nil, err
+	}
+	var got []byte
+	for {
+		line, err := c.next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var msg struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if json.Unmarshal(line, &msg) == nil && string(msg.ID) == probeID {
+			return got, nil
+		}
+		got = line
+	}
+}
+
+// next returns the next line that is no request or notification of the
+// server
+func (c *streamConn) next(ctx context.Context) ([]byte, error) {
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return nil, fmt.Errorf("server closed the connection: %w", c.readErr)
+			}
+			var msg struct {
+				Method *string `json:"method"`
+			}
+			if json.Unmarshal(line, &msg) == nil && msg.Method != nil && *msg.Method != "" {
+				continue
+			}
+			return line, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *streamConn) write(message []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.w.Write(append(append([]byte(nil), message...), '\n'))
+	return err
+}
+
+func (c *streamConn) Close() error {
+	return c.closer()
+}
+
+// httpConn posts messages to a Streamable HTTP endpoint
+type httpConn struct {
+	url     string
+	client  *http.Client
+	session string
+}
+
+// DialHTTP drives the server at a Streamable HTTP URL. The session id the
+// server returns is sent with later messages, and Close ends the session.
+func DialHTTP(url string) Conn {
+	return &httpConn{url: url, client: &http.Client{}}
+}
+
+func (c *httpConn) Send(ctx context.Context, message []byte, reply bool) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(message))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if c.session != "" {
+		req.Header.Set("Mcp-Session-Id", c.session)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		c.session = id
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent:
+		return nil, nil
+	case mediaType == "text/event-stream":
+		return readEvent(resp.Body)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Rejections may still carry a JSON-RPC error, which is the reply
+	if mediaType == "application/json" && json.Valid(body) {
+		return body, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, &StatusError{Code: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return nil, fmt.Errorf("unexpected Content-Type %q", resp.Header.Get("Content-Type"))
+}
+
+// readEvent returns the first message of an SSE stream that is no request or
+// notification of the server
+func readEvent(r io.Reader) ([]byte, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data:") {
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			continue
+		}
+		if line != "" || len(data) == 0 {
+			continue
+		}
+		message := []byte(strings.Join(data, "\n"))
+		data = nil
+		var msg struct {
+			Method string `json:"method"`
+		}
+		if json.Unmarshal(message, &msg) == nil && msg.Method != "" {
+			continue
+		}
+		return message, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("event stream ended without a response")
+}
+
+func (c *httpConn) Close() error {
+	if c.session == "" {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodDelete, c.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Mcp-Session-Id", c.session)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config lists the upstream servers of a gateway. As a file it is JSON:
+//
+//	{
+//	  "servers": [
+//	    {"name": "utility", "url": "http://localhost:8080/mcp"},
+//	    {"name": "db", "command": "./sqlite-mcp-server -db app.db", "autoRestart": true}
+//	  ]
+//	}
+type Config struct {
+	Servers []ServerConfig `json:"servers"`
+}
+
+// ServerConfig describes one upstream server. Exactly one of Command and
+// URL is set.
+type ServerConfig struct {
+	Name    string `json:"name"`
+	Command string `json:"command,omitempty"` // command line launching a stdio server
+	URL     string `json:"url,omitempty"`     // Streamable HTTP endpoint
+
+	// Prefix namespaces the server's tools, e.g. query becomes db.query
+	// (default Name)
+	Prefix string `json:"prefix,omitempty"`
+
+	// AutoRestart relaunches a stdio server that exits
+	AutoRestart bool `json:"autoRestart,omitempty"`
+}
+
+// namespace returns the prefix of the server's tools
+func (s ServerConfig) namespace() string {
+	if s.Prefix != "" {
+		return s.Prefix
+	}
+	return s.Name
+}
+
+// LoadConfig reads a gateway config file. Unknown fields are rejected, so a
+// misspelt option is not silently ignored.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gateway config: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse gateway config %s: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid gateway config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Validate checks that every server can be reached and that server names and
+// tool prefixes are unique
+func (c *Config) Validate() error {
+	if len(c.Servers) == 0 {
+		return fmt.Errorf("at least one server is required")
+	}
+
+	names := make(map[string]bool)
+	prefixes := make(map[string]bool)
+	for i, server := range c.Servers {
+		if server.Name == "" {
+			return fmt.Errorf("server %d: name is required", i+1)
+		}
+		if (server.Command == "") == (server.URL == "") {
+			return fmt.Errorf("server %s: exactly one of command or url is required", server.Name)
+		}
+		if server.URL != "" && server.AutoRestart {
+			return fmt.Errorf("server %s: autoRestart applies to command servers only", server.Name)
+		}
+
+		prefix := server.namespace()
+		if strings.ContainsAny(prefix, ". \t\n") {
+			return fmt.Errorf("server %s: invalid prefix %q: must not contain dots or whitespace", server.Name, prefix)
+		}
+		if names[server.Name] {
+			return fmt.Errorf("server %s is listed more than once", server.Name)
+		}
+		if prefixes[prefix] {
+			return fmt.Errorf("server %s: prefix %q is used by another server", server.Name, prefix)
+		}
+		names[server.Name] = true
+		prefixes[prefix] = true
+	}
+	return nil
+}
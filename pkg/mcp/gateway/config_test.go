@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.json")
+	os.WriteFile(path, []byte(`{"servers": [
+		{"name": "utility", "url": "http://localhost:8080/mcp"},
+		{"name": "sqlite", "command": "./sqlite-server -db app.db", "prefix": "db", "autoRestart": true}
+	]}`), 0644)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(config.Servers) != 2 || config.Servers[1].namespace() != "db" || config.Servers[0].namespace() != "utility" {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	os.WriteFile(path, []byte(`{"servers": [{"name": "utility", "url": "http://localhost", "prefx": "u"}]}`), 0644)
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "prefx") {
+		t.Errorf("Expected unknown fields to be rejected, got %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []ServerConfig
+		want    string
+	}{
+		{"no servers", nil, "at least one server"},
+		{"no name", []ServerConfig{{URL: "http://a"}}, "name is required"},
+		{"both", []ServerConfig{{Name: "a", URL: "http://a", Command: "a"}}, "exactly one of"},
+		{"neither", []ServerConfig{{Name: "a"}}, "exactly one of"},
+		{"restart url", []ServerConfig{{Name: "a", URL: "http://a", AutoRestart: true}}, "command servers only"},
+		{"dotted prefix", []ServerConfig{{Name: "a", URL: "http://a", Prefix: "a.b"}}, "must not contain dots"},
+		{"duplicate name", []ServerConfig{{Name: "a", URL: "http://a", Prefix: "x"}, {Name: "a", URL: "http://b"}}, "more than once"},
+		{"duplicate prefix", []ServerConfig{{Name: "a", URL: "http://a"}, {Name: "b", URL: "http://b", Prefix: "a"}}, "used by another server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Servers: tt.servers}
+			if err := config.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Package gateway aggregates several MCP servers behind one. A Gateway
+// connects to the upstream servers of a Config, stdio commands and HTTP
+// URLs alike, and serves their tools under a prefix per server, routing each
+// call to the server that defines the tool:
+//
+//	config, err := gateway.LoadConfig("gateway.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	gw, err := gateway.New(*config, gateway.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer gw.Close()
+//	srv := server.New(gw, server.Options{Name: "gateway"})
+//	log.Fatal(srv.ServeStdio(context.Background(), os.Stdin, os.Stdout))
+//
+// A Gateway is also a registry, so codemode.NewProvider runs programs
+// against every upstream server at once.
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// Options configures a Gateway
+type Options struct {
+	Logger *log.Logger // receives connection and refresh messages; nil discards them
+
+	// Client configures the clients of every upstream server. AutoRestart
+	// is taken from each server's config.
+	Client client.Options
+}
+
+// upstreamClient is what a gateway needs of the stdio and HTTP clients
+type upstreamClient interface {
+	client.ToolCaller
+	CallToolContext(ctx context.Context, name string, arguments map[string]interface{}) (*protocol.CallToolResult, error)
+	SetNotificationHandler(handler client.NotificationHandler)
+}
+
+// upstream is a connected upstream server
+type upstream struct {
+	config    ServerConfig
+	client    upstreamClient
+	refreshMu sync.Mutex // serializes listings, so the latest one is kept
+
+	tools []spec.ToolDefinition // as the server lists them; guarded by Gateway.mu
+}
+
+// route locates a gateway tool on its upstream server
+type route struct {
+	upstream *upstream
+	name     string // the tool's name on the upstream server
+}
+
+// Gateway serves the tools of several MCP servers as one ToolProvider and
+// one registry
+type Gateway struct {
+	logger *log.Logger
+
+	mu        sync.RWMutex
+	upstreams []*upstream
+	tools     []spec.ToolDefinition // prefixed, in config order
+	routes    map[string]route
+	watchers  []func()
+}
+
+// New connects to every server of config and lists their tools. Servers
+// that change their tools while connected are listed again when they send
+// notifications/tools/list_changed. If any server cannot be reached, those
+// already connected are closed and the error is returned.
+func New(config Config, opts Options) (*Gateway, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.New(io.Discard, "", 0)
+	}
+
+	g := &Gateway{logger: opts.Logger, routes: make(map[string]route)}
+	for _, server := range config.Servers {
+		u, err := g.connect(server, opts.Client)
+		if err != nil {
+			g.Close()
+			return nil, err
+		}
+		// Listings prompted by list changes may already read the upstreams
+		g.mu.Lock()
+		g.upstreams = append(g.upstreams, u)
+		g.mu.Unlock()
+	}
+
+	for _, u := range g.upstreams {
+		if err := g.refresh(u); err != nil {
+			g.Close()
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// connect initializes a client for an upstream server and subscribes to its
+// tool list changes
+func (g *Gateway) connect(server ServerConfig, opts client.Options) (*upstream, error) {
+	opts.AutoRestart = server.AutoRestart
+	c, err := client.ConnectWithOptions(opts, server.Command, server.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", server.Name, err)
+	}
+	u := &upstream{config: server, client: c.(upstreamClient)}
+
+	u.client.SetNotificationHandler(func(msg *protocol.JSONRPCMessage) {
+		if msg.Method != "notifications/tools/list_changed" {
+			return
+		}
+		// Handlers must not call the client, so the tools are listed on
+		// another goroutine
+		go func() {
+			if err := g.refresh(u); err != nil {
+				g.logger.Printf("Keeping the previous tools of %s: %v", server.Name, err)
+			}
+		}()
+	})
+
+	// HTTP servers send list changes on the stream opened by Listen. Not all
+	// servers offer one, and their tools are then listed once.
+	if httpClient, ok := c.(*client.HTTPMCPClient); ok {
+		if err := httpClient.Listen(); err != nil {
+			g.logger.Printf("Not watching %s for tool changes: %v", server.Name, err)
+		}
+	}
+
+	g.logger.Printf("Connected to %s (%s)", server.Name, c.ServerInfo().Name)
+	return u, nil
+}
+
+// refresh lists the tools of an upstream server and, when they changed,
+// tells the watchers
+func (g *Gateway) refresh(u *upstream) error {
+	u.refreshMu.Lock()
+	defer u.refreshMu.Unlock()
+
+	listed, err := u.client.ListTools()
+	if err != nil {
+		return fmt.Errorf("failed to list the tools of %s: %w", u.config.Name, err)
+	}
+	tools, err := client.ToolDefinitions(listed)
+	if err != nil {
+		return fmt.Errorf("failed to read the tools of %s: %w", u.config.Name, err)
+	}
+
+	g.mu.Lock()
+	changed := !reflect.DeepEqual(u.tools, tools)
+	previous := u.tools
+	u.tools = tools
+	if err := g.merge(); err != nil {
+		u.tools = previous
+		g.mu.Unlock()
+		return fmt.Errorf("%s: %w", u.config.Name, err)
+	}
+	watchers := append([]func(){}, g.watchers...)
+	g.mu.Unlock()
+
+	g.logger.Printf("Listed %d tools of %s", len(tools), u.config.Name)
+	if changed {
+		for _, watcher := range watchers {
+			watcher()
+		}
+	}
+	return nil
+}
+
+// merge rebuilds the prefixed tool list and the routes from the tools of
+// every upstream server. g.mu must be held.
+func (g *Gateway) merge() error {
+	sets := make([]spec.ToolSet, 0, len(g.upstreams))
+	routes := make(map[string]route)
+	for _, u := range g.upstreams {
+		prefix := u.config.namespace()
+		sets = append(sets, spec.ToolSet{Namespace: prefix, Source: u.config.Name, Tools: u.tools})
+		for _, tool := range u.tools {
+			routes[prefix+"."+tool.Name] = route{upstream: u, name: tool.Name}
+		}
+	}
+
+	// Prefixes are unique and free of dots, so only a server listing a
+	// name twice conflicts
+	tools, err := spec.MergeToolSets(sets)
+	if err != nil {
+		return err
+	}
+	g.tools, g.routes = tools, routes
+	return nil
+}
+
+// Tools lists the tools of every upstream server, named prefix.name
+func (g *Gateway) Tools() []spec.ToolDefinition {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]spec.ToolDefinition(nil), g.tools...)
+}
+
+// ListTools is Tools, for spec.FromRegistry and code mode
+func (g *Gateway) ListTools() []spec.ToolDefinition {
+	return g.Tools()
+}
+
+// WatchTools registers a function called after the tools of an upstream
+// server changed
+func (g *Gateway) WatchTools(changed func()) {
+	g.mu.Lock()
+	g.watchers = append(g.watchers, changed)
+	g.mu.Unlock()
+}
+
+// CallTool calls a tool on its upstream server and returns the server's
+// *protocol.CallToolResult as is, so error results stay error results.
+// JSON-RPC errors of the server are returned as errors wrapping the
+// *protocol.RPCError, which a Server relays to its client.
+func (g *Gateway) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	g.mu.RLock()
+	r, ok := g.routes[name]
+	g.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+
+	result, err := r.upstream.client.CallToolContext(ctx, r.name, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.upstream.config.Name, err)
+	}
+	return result, nil
+}
+
+// CallContext calls a tool like CallTool and unwraps its result with
+// client.ResultValue, as registries return plain values
+func (g *Gateway) CallContext(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	result, err := g.CallTool(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	return client.ResultValue(result.(*protocol.CallToolResult))
+}
+
+// Call is CallContext without a context
+func (g *Gateway) Call(name string, args map[string]interface{}) (interface{}, error) {
+	return g.CallContext(context.Background(), name, args)
+}
+
+// Close disconnects from every upstream server, stopping those launched by
+// the gateway
+func (g *Gateway) Close() error {
+	g.mu.RLock()
+	upstreams := g.upstreams
+	g.mu.RUnlock()
+
+	var failures []string
+	for _, u := range upstreams {
+		if err := u.client.Close(); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", u.config.Name, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to close upstream servers: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
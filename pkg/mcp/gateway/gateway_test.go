@@ -0,0 +1,200 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/mcp/server"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// upstreamEnv makes the test binary serve mathProvider over stdio, so tests
+// can configure it as a command server
+const upstreamEnv = "GODEMODE_TEST_GATEWAY_UPSTREAM"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(upstreamEnv) != "" {
+		server.New(mathProvider{}, server.Options{}).ServeStdio(context.Background(), os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// mathProvider has a tool that answers, one that fails and one that rejects
+// its arguments
+type mathProvider struct{}
+
+func (mathProvider) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{
+		{Name: "add", Parameters: []spec.Parameter{{Name: "a", Type: "number"}, {Name: "b", Type: "number"}}},
+		{Name: "fail"},
+		{Name: "reject"},
+	}
+}
+
+func (mathProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "fail":
+		return nil, errors.New("division by zero")
+	case "reject":
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: "b must not be zero"}
+	}
+	a, _ := args["a"].(float64)
+	b, _ := args["b"].(float64)
+	return map[string]interface{}{"sum": a + b}, nil
+}
+
+// notesProvider adds tools while served and tells the server it did
+type notesProvider struct {
+	mu      sync.Mutex
+	tools   []spec.ToolDefinition
+	changed func()
+}
+
+func (p *notesProvider) Tools() []spec.ToolDefinition {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]spec.ToolDefinition(nil), p.tools...)
+}
+
+func (p *notesProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	return "note " + name, nil
+}
+
+func (p *notesProvider) WatchTools(changed func()) {
+	p.changed = changed
+}
+
+func (p *notesProvider) add(tool spec.ToolDefinition) {
+	p.mu.Lock()
+	p.tools = append(p.tools, tool)
+	p.mu.Unlock()
+	p.changed()
+}
+
+// newGateway connects a gateway to mathProvider over stdio and to notes over
+// HTTP, with the prefix "n"
+func newGateway(t *testing.T, notes *notesProvider) *Gateway {
+	t.Helper()
+	t.Setenv(upstreamEnv, "1")
+	ts := httptest.NewServer(server.New(notes, server.Options{}))
+	t.Cleanup(ts.Close)
+
+	gw, err := New(Config{Servers: []ServerConfig{
+		{Name: "math", Command: os.Args[0]},
+		{Name: "notes", URL: ts.URL, Prefix: "n"},
+	}}, Options{Client: client.Options{CallTimeout: 5 * time.Second}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gw.Close() })
+	return gw
+}
+
+func TestGatewayRoutesCalls(t *testing.T) {
+	gw := newGateway(t, &notesProvider{tools: []spec.ToolDefinition{{Name: "list"}}})
+
+	var names []string
+	for _, tool := range gw.Tools() {
+		names = append(names, tool.Name)
+	}
+	if want := []string{"math.add", "math.fail", "math.reject", "n.list"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected prefixed tools %v, got %v", want, names)
+	}
+
+	ts := httptest.NewServer(server.New(gw, server.Options{}))
+	defer ts.Close()
+	c := client.NewHTTPMCPClient(ts.URL)
+	defer c.Close()
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if tools, err := c.ListTools(); err != nil || tools[0].InputSchema.Properties["a"].Type != "number" {
+		t.Errorf("Expected the upstream schemas, got %+v (%v)", tools, err)
+	}
+
+	result, err := c.CallTool("math.add", map[string]interface{}{"a": 1, "b": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := client.ResultValue(result); !reflect.DeepEqual(value, map[string]interface{}{"sum": float64(3)}) {
+		t.Errorf("Expected the stdio server's sum, got %v", value)
+	}
+	if result, err := c.CallTool("n.list", nil); err != nil || result.Content[0].Text != `"note list"` {
+		t.Errorf("Expected the HTTP server's answer, got %+v (%v)", result, err)
+	}
+
+	// Error results and JSON-RPC errors reach the client as the upstream
+	// server sent them
+	if result, err := c.CallTool("math.fail", nil); err != nil || !result.IsError {
+		t.Errorf("Expected an error result, got %+v (%v)", result, err)
+	}
+	var rpcErr *protocol.RPCError
+	if _, err := c.CallTool("math.reject", nil); !errors.As(err, &rpcErr) || rpcErr.Code != protocol.InvalidParams {
+		t.Errorf("Expected the upstream's invalid params error, got %v", err)
+	}
+	if _, err := c.CallTool("add", nil); !errors.As(err, &rpcErr) || rpcErr.Code != protocol.InvalidParams {
+		t.Errorf("Expected unprefixed names to be unknown, got %v", err)
+	}
+}
+
+func TestGatewayRegistry(t *testing.T) {
+	gw := newGateway(t, &notesProvider{})
+
+	tools, err := spec.FromRegistry(gw)
+	if err != nil || len(tools) != 3 {
+		t.Fatalf("Expected the gateway to read as a registry, got %v (%v)", tools, err)
+	}
+
+	value, err := gw.Call("math.add", map[string]interface{}{"a": 2, "b": 2})
+	if err != nil || !reflect.DeepEqual(value, map[string]interface{}{"sum": float64(4)}) {
+		t.Errorf("Expected a plain value, got %v (%v)", value, err)
+	}
+	if _, err := gw.Call("math.fail", nil); err == nil {
+		t.Error("Expected error results to be errors")
+	}
+}
+
+func TestGatewayToolsListChanged(t *testing.T) {
+	notes := &notesProvider{tools: []spec.ToolDefinition{{Name: "list"}}}
+	gw := newGateway(t, notes)
+
+	ts := httptest.NewServer(server.New(gw, server.Options{}))
+	defer ts.Close()
+	c := client.NewHTTPMCPClient(ts.URL)
+	defer c.Close()
+	notified := make(chan struct{}, 1)
+	c.SetNotificationHandler(func(msg *protocol.JSONRPCMessage) {
+		if msg.Method == "notifications/tools/list_changed" {
+			notified <- struct{}{}
+		}
+	})
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.ServerCapabilities().Tools.ListChanged {
+		t.Error("Expected the gateway to advertise tools.listChanged")
+	}
+	if err := c.Listen(); err != nil {
+		t.Fatal(err)
+	}
+
+	notes.add(spec.ToolDefinition{Name: "create"})
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the gateway to pass the change on")
+	}
+
+	if result, err := c.CallTool("n.create", nil); err != nil || result.IsError {
+		t.Errorf("Expected the new tool to be routed, got %+v (%v)", result, err)
+	}
+}
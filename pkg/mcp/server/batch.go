@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// batch is a JSON-RPC batch being handled. Its messages are admitted in
+// order, like messages sent one by one, and its requests then run
+// concurrently; the batch is answered once all of them finished.
+type batch struct {
+	responses []*response // by position in the batch; nil where none is sent
+	requests  []batchRequest
+}
+
+// batchRequest is an admitted request of a batch
+type batchRequest struct {
+	index  int
+	req    *request
+	ctx    context.Context
+	finish func() bool
+}
+
+// splitBatch reports whether data is a JSON-RPC batch, an array of
+// messages, and returns the messages. Malformed arrays are not batches, so
+// they are answered with a parse error like other malformed messages.
+func splitBatch(data []byte) ([]json.RawMessage, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		return nil, false
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, false
+	}
+	return messages, true
+}
+
+// admitBatch decodes and admits the messages of a batch, registering its
+// requests as in flight so they can be cancelled before they run. initialize
+// is rejected within a batch, as it must be answered before anything else is
+// sent.
+func (s *Server) admitBatch(ctx context.Context, sess *session, messages []json.RawMessage) *batch {
+	b := &batch{responses: make([]*response, len(messages))}
+	for i, message := range messages {
+		req, resp := parseMessage(message)
+		if req == nil {
+			b.responses[i] = resp
+			continue
+		}
+		if req.Method == "initialize" && !req.isNotification() {
+			b.responses[i] = errorResponse(req.ID, protocol.InvalidRequest, "Invalid request: initialize must not be part of a batch")
+			continue
+		}
+		if resp, ok := s.admit(sess, req); !ok {
+			b.responses[i] = resp
+			continue
+		}
+		reqCtx, finish := sess.begin(ctx, req.ID)
+		b.requests = append(b.requests, batchRequest{index: i, req: req, ctx: reqCtx, finish: finish})
+	}
+	return b
+}
+
+// hasRequests reports whether any message of the batch expects a response
+func (b *batch) hasRequests() bool {
+	if len(b.responses) == 0 || len(b.requests) > 0 {
+		return true
+	}
+	for _, resp := range b.responses {
+		if resp != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// runBatch dispatches the admitted requests of a batch concurrently, each
+// taking one of slots when it is set, and returns the encoded array of
+// responses, or nil when no message of the batch is answered. An empty
+// batch is answered with a single invalid request error, as JSON-RPC asks.
+func (s *Server) runBatch(b *batch, sess *session, slots chan struct{}) []byte {
+	if len(b.responses) == 0 {
+		return encodeResponse(errorResponse(nil, protocol.InvalidRequest, "Invalid request: empty batch"))
+	}
+
+	var wg sync.WaitGroup
+	for _, br := range b.requests {
+		wg.Add(1)
+		go func(br batchRequest) {
+			defer wg.Done()
+			var resp *response
+			if slots == nil || acquire(br.ctx, slots) {
+				resp = s.dispatch(br.ctx, sess, br.req)
+				if slots != nil {
+					<-slots
+				}
+			}
+			// Each request writes its own element, so no lock is needed
+			if !br.finish() {
+				b.responses[br.index] = resp
+			}
+		}(br)
+	}
+	wg.Wait()
+
+	var encoded []json.RawMessage
+	for _, resp := range b.responses {
+		if resp != nil {
+			encoded = append(encoded, encodeResponse(resp))
+		}
+	}
+	if len(encoded) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(encoded)
+	return data
+}
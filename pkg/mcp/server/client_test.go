@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// serverEnv makes the test binary serve crashProvider over stdio, so client
+// tests can launch it as a server process
+const serverEnv = "GODEMODE_TEST_MCP_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(serverEnv) != "" {
+		New(crashProvider{}, Options{}).ServeStdio(context.Background(), os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// crashProvider has tools that answer, block until cancelled, report
+// progress, or kill the server process
+type crashProvider struct{}
+
+func (crashProvider) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{{Name: "echo"}, {Name: "wait"}, {Name: "report"}, {Name: "crash"}}
+}
+
+func (crashProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "wait":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case "report":
+		return reportProvider{}.CallTool(ctx, name, args)
+	case "crash":
+		os.Exit(3)
+	}
+	return args, nil
+}
+
+func TestStdioClientSupervision(t *testing.T) {
+	t.Setenv(serverEnv, "1")
+	c, err := client.NewMCPClientWithOptions(client.Options{
+		AutoRestart:    true,
+		RestartBackoff: 10 * time.Millisecond,
+		CallTimeout:    5 * time.Second,
+	}, os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A call past its deadline fails without waiting for the server
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.CallToolContext(ctx, "wait", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	// A crash fails the pending call instead of hanging it
+	if _, err := c.CallTool("crash", nil); !errors.Is(err, client.ErrServerExited) {
+		t.Errorf("Expected ErrServerExited, got %v", err)
+	}
+
+	// Calls made while the server restarts wait for it to be initialized again
+	result, err := c.CallTool("echo", map[string]interface{}{"x": "y"})
+	if err != nil || !strings.Contains(result.Content[0].Text, `"x":"y"`) {
+		t.Errorf("Expected call after restart to succeed, got %+v (%v)", result, err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if err := c.Ping(context.Background()); !errors.Is(err, client.ErrClosed) {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestStdioClientWithoutRestart(t *testing.T) {
+	t.Setenv(serverEnv, "1")
+	c, err := client.NewMCPClientWithOptions(client.Options{CallTimeout: 5 * time.Second}, os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CallTool("crash", nil); !errors.Is(err, client.ErrServerExited) {
+		t.Errorf("Expected ErrServerExited, got %v", err)
+	}
+	if _, err := c.CallTool("echo", nil); !errors.Is(err, client.ErrServerExited) {
+		t.Errorf("Expected calls to a dead server to fail, got %v", err)
+	}
+}
+
+func TestStdioClientCallbacks(t *testing.T) {
+	t.Setenv(serverEnv, "1")
+	var mu sync.Mutex
+	var progress []protocol.ProgressNotification
+	var levels []string
+	c, err := client.NewMCPClientWithOptions(client.Options{
+		CallTimeout: 5 * time.Second,
+		OnProgress: func(p protocol.ProgressNotification) {
+			mu.Lock()
+			progress = append(progress, p)
+			mu.Unlock()
+		},
+		OnLog: func(m protocol.LoggingMessageNotification) {
+			mu.Lock()
+			levels = append(levels, m.Level)
+			mu.Unlock()
+		},
+	}, os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CallTool("report", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Notifications are written before the response, so they have been
+	// handled by the time the call returns
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) != 2 || progress[0].ProgressToken == nil || progress[1].Progress != 2 {
+		t.Errorf("Unexpected progress notifications %+v", progress)
+	}
+	if !reflect.DeepEqual(levels, []string{"warning"}) {
+		t.Errorf("Expected only the warning at the default level, got %v", levels)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/mcp/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	opts := conformance.Options{Call: &conformance.ToolCall{Name: "add", Arguments: map[string]interface{}{"a": 1, "b": 2}}}
+
+	transports := map[string]func(t *testing.T, srv *Server) conformance.Conn{
+		"HandleMessage": func(t *testing.T, srv *Server) conformance.Conn {
+			return conformance.HandlerConn(srv.HandleMessage)
+		},
+		"stdio": func(t *testing.T, srv *Server) conformance.Conn {
+			inR, inW := io.Pipe()
+			outR, outW := io.Pipe()
+			go func() {
+				srv.ServeStdio(context.Background(), inR, outW)
+				outW.Close()
+			}()
+			return conformance.NewStreamConn(outR, inW)
+		},
+		"HTTP": func(t *testing.T, srv *Server) conformance.Conn {
+			ts := httptest.NewServer(srv)
+			t.Cleanup(ts.Close)
+			return conformance.DialHTTP(ts.URL)
+		},
+	}
+
+	for name, dial := range transports {
+		t.Run(name, func(t *testing.T) {
+			conn := dial(t, New(testProvider{}, Options{}))
+			defer conn.Close()
+			report := conformance.Run(context.Background(), conn, opts)
+			if !report.Passed() {
+				t.Errorf("Expected no violations, got:\n%s", report)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// structuredVersion is the first revision with structuredContent and
+// resource links in tool results
+const structuredVersion = "2025-06-18"
+
+// Image is a tool result sent as image content. MimeType is detected from
+// Data when empty.
+type Image struct {
+	Data     []byte
+	MimeType string
+}
+
+// Audio is a tool result sent as audio content. MimeType is detected from
+// Data when empty.
+type Audio struct {
+	Data     []byte
+	MimeType string
+}
+
+// toolResult converts what a tool returned into a tools/call result:
+//
+//   - protocol.CallToolResult is sent as is
+//   - protocol.Content and []protocol.Content are the result's content
+//   - Image and Audio, and []byte holding an image or audio, are sent as
+//     image or audio content
+//   - protocol.ResourceContents is sent as an embedded resource and
+//     protocol.Resource as a resource link
+//   - anything else is sent as JSON text, and JSON objects also as
+//     structuredContent when structured is set
+func toolResult(result interface{}, structured bool) (*protocol.CallToolResult, error) {
+	switch v := result.(type) {
+	case protocol.CallToolResult:
+		return &v, nil
+	case *protocol.CallToolResult:
+		if v != nil {
+			return v, nil
+		}
+	case protocol.Content:
+		return &protocol.CallToolResult{Content: []protocol.Content{v}}, nil
+	case []protocol.Content:
+		return &protocol.CallToolResult{Content: v}, nil
+	case Image:
+		return contentResult(mediaContent(protocol.ContentImage, v.Data, v.MimeType)), nil
+	case *Image:
+		if v != nil {
+			return contentResult(mediaContent(protocol.ContentImage, v.Data, v.MimeType)), nil
+		}
+	case Audio:
+		return contentResult(mediaContent(protocol.ContentAudio, v.Data, v.MimeType)), nil
+	case *Audio:
+		if v != nil {
+			return contentResult(mediaContent(protocol.ContentAudio, v.Data, v.MimeType)), nil
+		}
+	case []byte:
+		mimeType := http.DetectContentType(v)
+		if strings.HasPrefix(mimeType, "image/") {
+			return contentResult(mediaContent(protocol.ContentImage, v, mimeType)), nil
+		}
+		if strings.HasPrefix(mimeType, "audio/") {
+			return contentResult(mediaContent(protocol.ContentAudio, v, mimeType)), nil
+		}
+	case protocol.ResourceContents:
+		return contentResult(protocol.Content{Type: protocol.ContentResource, Resource: &v}), nil
+	case protocol.Resource:
+		return contentResult(protocol.Content{
+			Type:        protocol.ContentResourceLink,
+			URI:         v.URI,
+			Name:        v.Name,
+			Description: v.Description,
+			MimeType:    v.MimeType,
+		}), nil
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	callResult := contentResult(protocol.Content{Type: protocol.ContentText, Text: string(resultJSON), MimeType: "application/json"})
+	if structured && bytes.HasPrefix(resultJSON, []byte("{")) {
+		// The text stays for clients that ignore structuredContent
+		callResult.StructuredContent = json.RawMessage(resultJSON)
+	}
+	return callResult, nil
+}
+
+// contentResult is a result holding one content item
+func contentResult(content protocol.Content) *protocol.CallToolResult {
+	return &protocol.CallToolResult{Content: []protocol.Content{content}}
+}
+
+// mediaContent encodes image or audio data, detecting its type when the
+// tool did not give one
+func mediaContent(contentType string, data []byte, mimeType string) protocol.Content {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return protocol.Content{
+		Type:     contentType,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestToolResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     interface{}
+		structured bool
+		want       protocol.CallToolResult
+	}{
+		{
+			name:       "object",
+			result:     map[string]interface{}{"sum": 3},
+			structured: true,
+			want: protocol.CallToolResult{
+				Content:           []protocol.Content{{Type: "text", Text: `{"sum":3}`, MimeType: "application/json"}},
+				StructuredContent: json.RawMessage(`{"sum":3}`),
+			},
+		},
+		{
+			name:   "object before 2025-06-18",
+			result: map[string]interface{}{"sum": 3},
+			want:   protocol.CallToolResult{Content: []protocol.Content{{Type: "text", Text: `{"sum":3}`, MimeType: "application/json"}}},
+		},
+		{
+			name:       "string",
+			result:     "hi",
+			structured: true,
+			want:       protocol.CallToolResult{Content: []protocol.Content{{Type: "text", Text: `"hi"`, MimeType: "application/json"}}},
+		},
+		{
+			name:   "image",
+			result: Image{Data: pngHeader},
+			want:   protocol.CallToolResult{Content: []protocol.Content{{Type: "image", Data: "iVBORw0KGgoAAAANSUhEUg==", MimeType: "image/png"}}},
+		},
+		{
+			name:   "sniffed image",
+			result: pngHeader,
+			want:   protocol.CallToolResult{Content: []protocol.Content{{Type: "image", Data: "iVBORw0KGgoAAAANSUhEUg==", MimeType: "image/png"}}},
+		},
+		{
+			name:   "audio",
+			result: &Audio{Data: []byte("RIFF"), MimeType: "audio/wav"},
+			want:   protocol.CallToolResult{Content: []protocol.Content{{Type: "audio", Data: "UklGRg==", MimeType: "audio/wav"}}},
+		},
+		{
+			name:   "bytes",
+			result: []byte("plain"),
+			want:   protocol.CallToolResult{Content: []protocol.Content{{Type: "text", Text: `"cGxhaW4="`, MimeType: "application/json"}}},
+		},
+		{
+			name:   "embedded resource",
+			result: protocol.ResourceContents{URI: "file:///a.csv", MimeType: "text/csv", Text: "a,b"},
+			want: protocol.CallToolResult{Content: []protocol.Content{{Type: "resource", Resource: &protocol.ResourceContents{
+				URI: "file:///a.csv", MimeType: "text/csv", Text: "a,b",
+			}}}},
+		},
+		{
+			name:   "resource link",
+			result: protocol.Resource{URI: "file:///report.xlsx", Name: "report", MimeType: "application/vnd.ms-excel"},
+			want: protocol.CallToolResult{Content: []protocol.Content{{
+				Type: "resource_link", URI: "file:///report.xlsx", Name: "report", MimeType: "application/vnd.ms-excel",
+			}}},
+		},
+		{
+			name:   "content",
+			result: []protocol.Content{{Type: "text", Text: "one"}, {Type: "text", Text: "two"}},
+			want:   protocol.CallToolResult{Content: []protocol.Content{{Type: "text", Text: "one"}, {Type: "text", Text: "two"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toolResult(tt.result, tt.structured)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Compare the wire form, as structuredContent is raw JSON
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("Expected %s, got %s", wantJSON, gotJSON)
+			}
+		})
+	}
+}
+
+func TestStructuredContentNegotiation(t *testing.T) {
+	call := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"add","arguments":{"a":1,"b":2}}}`
+
+	old := byID(serve(t, New(testProvider{}, Options{}), initializeLine, call))
+	if result := old["2"]["result"].(map[string]interface{}); result["structuredContent"] != nil {
+		t.Errorf("Did not expect structuredContent for 2024-11-05, got %v", result)
+	}
+
+	current := byID(serve(t, New(testProvider{}, Options{}),
+		strings.Replace(initializeLine, "2024-11-05", "2025-06-18", 1), call))
+	result := current["2"]["result"].(map[string]interface{})
+	if !reflect.DeepEqual(result["structuredContent"], map[string]interface{}{"sum": float64(3)}) {
+		t.Errorf("Expected structuredContent for 2025-06-18, got %v", result)
+	}
+}
+
+// mediaProvider returns each kind of content
+type mediaProvider struct{}
+
+func (mediaProvider) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{
+		{Name: "screenshot"},
+		{Name: "export"},
+		{Name: "stats", Output: []spec.Parameter{{Name: "rows", Type: "integer", Required: true}}},
+	}
+}
+
+func (mediaProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "screenshot":
+		return Image{Data: pngHeader}, nil
+	case "export":
+		return protocol.Resource{URI: "file:///report.xlsx", Name: "report"}, nil
+	}
+	return map[string]interface{}{"rows": 42}, nil
+}
+
+func TestHTTPClientContent(t *testing.T) {
+	ts := httptest.NewServer(New(mediaProvider{}, Options{}))
+	defer ts.Close()
+
+	c := client.NewHTTPMCPClient(ts.URL)
+	defer c.Close()
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	tools, err := c.ListTools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema := tools[2].OutputSchema; schema == nil || schema.Properties["rows"].Type != "integer" {
+		t.Errorf("Expected the output schema of stats, got %+v", schema)
+	}
+
+	values := map[string]interface{}{}
+	for _, name := range []string{"screenshot", "export", "stats"} {
+		result, err := c.CallTool(name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values[name], err = client.ResultValue(result); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]interface{}{
+		"screenshot": client.Media{Type: "image", MimeType: "image/png", Data: pngHeader},
+		"export":     protocol.Resource{URI: "file:///report.xlsx", Name: "report"},
+		"stats":      map[string]interface{}{"rows": float64(42)},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Expected decoded values %+v, got %+v", want, values)
+	}
+}
+
+func TestHTTPProtocolHeader(t *testing.T) {
+	ts := httptest.NewServer(New(testProvider{}, Options{}))
+	defer ts.Close()
+
+	sessionID := initializeHTTP(t, ts.URL)
+	for version, status := range map[string]int{"2025-06-18": http.StatusOK, "1999-01-01": http.StatusBadRequest} {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SessionHeader, sessionID)
+		req.Header.Set(ProtocolHeader, version)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("Expected %d for version %s, got %d", status, version, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// defaultLogLevel is the least severe level sent before the client calls
+// logging/setLevel
+const defaultLogLevel = "info"
+
+// Emitter sends notifications about a tool call to the client that made it.
+// Tools get one from their context with EmitterFromContext:
+//
+//	emit := server.EmitterFromContext(ctx)
+//	for i, item := range items {
+//		emit.Progress(float64(i), float64(len(items)), "processing "+item)
+//		emit.Log("debug", "importer", map[string]string{"item": item})
+//	}
+//
+// Progress is sent only when the client asked for it with a progressToken,
+// and log messages only at or above the level set with logging/setLevel.
+// Emitting is safe without a client, so tools need not check for one.
+type Emitter struct {
+	send          func(data []byte) // nil discards notifications
+	sess          *session
+	progressToken interface{}
+}
+
+type emitterKey struct{}
+
+// senderKey carries the function delivering notifications about the request
+// in a context, for transports that send them somewhere other than the
+// session's stream
+type senderKey struct{}
+
+// EmitterFromContext returns the emitter of the tool call running in ctx, or
+// one that discards everything when ctx is not a tool call's
+func EmitterFromContext(ctx context.Context) *Emitter {
+	if e, ok := ctx.Value(emitterKey{}).(*Emitter); ok {
+		return e
+	}
+	return &Emitter{}
+}
+
+// withEmitter returns a context for a tools/call request, whose
+// notifications go to the request's sender or else the session
+func withEmitter(ctx context.Context, sess *session, progressToken interface{}) context.Context {
+	e := &Emitter{sess: sess, progressToken: progressToken}
+	if send, ok := ctx.Value(senderKey{}).(func([]byte)); ok {
+		e.send = send
+	} else if sess != nil {
+		e.send = sess.send
+	}
+	return context.WithValue(ctx, emitterKey{}, e)
+}
+
+// withSender routes the notifications of requests handled in ctx
+func withSender(ctx context.Context, send func([]byte)) context.Context {
+	return context.WithValue(ctx, senderKey{}, send)
+}
+
+// Progress reports how far the call has got, as progress out of total (0
+// when unknown), with an optional message
+func (e *Emitter) Progress(progress, total float64, message string) {
+	if e.progressToken == nil {
+		return
+	}
+	e.notify("notifications/progress", protocol.ProgressNotification{
+		ProgressToken: e.progressToken,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+}
+
+// Log sends a log message at one of protocol.LoggingLevels. logger names the
+// source and may be empty; data is any JSON-serializable value.
+func (e *Emitter) Log(level, logger string, data interface{}) {
+	if e.sess == nil || logLevelIndex(level) < e.sess.minLogLevel() {
+		return
+	}
+	e.notify("notifications/message", protocol.LoggingMessageNotification{
+		Level:  level,
+		Logger: logger,
+		Data:   data,
+	})
+}
+
+// notify encodes and sends a notification
+func (e *Emitter) notify(method string, params interface{}) {
+	if e.send == nil {
+		return
+	}
+	data, err := json.Marshal(notification{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return
+	}
+	e.send(data)
+}
+
+// minLogLevel is the index of the least severe level the client wants
+func (sess *session) minLogLevel() int {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.logLevel == "" {
+		return logLevelIndex(defaultLogLevel)
+	}
+	return logLevelIndex(sess.logLevel)
+}
+
+// logLevelIndex orders levels by severity, returning -1 for unknown ones
+func logLevelIndex(level string) int {
+	for i, l := range protocol.LoggingLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
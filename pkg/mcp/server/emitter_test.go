@@ -0,0 +1,166 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// reportProvider has a tool that reports progress and logs at two levels
+type reportProvider struct{}
+
+func (reportProvider) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{{Name: "report"}}
+}
+
+func (reportProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	emit := EmitterFromContext(ctx)
+	emit.Progress(1, 2, "half way")
+	emit.Log("debug", "report", "detail")
+	emit.Log("warning", "report", map[string]interface{}{"disk": "low"})
+	emit.Progress(2, 2, "")
+	return "done", nil
+}
+
+func TestEmitterStdio(t *testing.T) {
+	// Calls run concurrently with the lines after them, so the level is
+	// lowered in a session of its own
+	srv := New(reportProvider{}, Options{})
+	messages := serve(t, srv,
+		initializeLine,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"report","_meta":{"progressToken":"tok"}}}`,
+	)
+	messages = append(messages, serve(t, srv,
+		initializeLine,
+		`{"jsonrpc":"2.0","id":3,"method":"logging/setLevel","params":{"level":"debug"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"report"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"logging/setLevel","params":{"level":"loud"}}`,
+	)...)
+
+	counts := map[string]int{}
+	var responses []map[string]interface{}
+	for _, msg := range messages {
+		method, _ := msg["method"].(string)
+		if method == "" {
+			responses = append(responses, msg)
+			continue
+		}
+		params := msg["params"].(map[string]interface{})
+		switch method {
+		case "notifications/progress":
+			if params["progressToken"] != "tok" {
+				t.Errorf("Expected the request's progress token, got %v", params)
+			}
+			counts[fmt.Sprint(params["progress"])]++
+		case "notifications/message":
+			counts[params["level"].(string)]++
+		default:
+			t.Errorf("Unexpected notification %s", method)
+		}
+	}
+
+	// Only the call with a token reports progress, and debug messages are
+	// sent only after the level is lowered
+	want := map[string]int{"1": 1, "2": 1, "warning": 2, "debug": 1}
+	for key, n := range want {
+		if counts[key] != n {
+			t.Errorf("Expected %d %q notifications, got %d (%v)", n, key, counts[key], counts)
+		}
+	}
+
+	indexed := byID(responses)
+	if indexed["3"]["error"] != nil {
+		t.Errorf("Expected logging/setLevel to succeed, got %v", indexed["3"])
+	}
+	rpcErr, _ := indexed["5"]["error"].(map[string]interface{})
+	if rpcErr == nil || rpcErr["code"] != float64(protocol.InvalidParams) {
+		t.Errorf("Expected an unknown level to be rejected, got %v", indexed["5"])
+	}
+}
+
+func TestEmitterWithoutCall(t *testing.T) {
+	// Tools called outside a server can emit without checking for a client
+	emit := EmitterFromContext(context.Background())
+	emit.Progress(1, 1, "")
+	emit.Log("error", "", "nobody listening")
+}
+
+func TestEmitterHTTPStream(t *testing.T) {
+	ts := httptest.NewServer(New(reportProvider{}, Options{}))
+	defer ts.Close()
+
+	sessionID := initializeHTTP(t, ts.URL)
+	resp := send(t, http.MethodPost, ts.URL, sessionID, "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"report","_meta":{"progressToken":7}}}`)
+	defer resp.Body.Close()
+
+	// Notifications about the call arrive on its stream ahead of the response
+	r := bufio.NewReader(resp.Body)
+	var notifications []string
+	for {
+		event := readEvent(t, r)
+		if !strings.Contains(event.data, `"method"`) {
+			if !strings.Contains(event.data, `"id":2`) {
+				t.Errorf("Expected the call's response, got %s", event.data)
+			}
+			break
+		}
+		if strings.Contains(event.data, "notifications/progress") && !strings.Contains(event.data, `"progressToken":7`) {
+			t.Errorf("Expected the request's progress token, got %s", event.data)
+		}
+		notifications = append(notifications, event.data)
+	}
+	if len(notifications) != 3 {
+		t.Errorf("Expected 2 progress and 1 log notification, got %v", notifications)
+	}
+}
+
+func TestHTTPClientCallbacks(t *testing.T) {
+	ts := httptest.NewServer(New(reportProvider{}, Options{}))
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var progress []protocol.ProgressNotification
+	var logs []protocol.LoggingMessageNotification
+	c := client.NewHTTPMCPClientWithOptions(client.Options{
+		OnProgress: func(p protocol.ProgressNotification) {
+			mu.Lock()
+			progress = append(progress, p)
+			mu.Unlock()
+		},
+		OnLog: func(m protocol.LoggingMessageNotification) {
+			mu.Lock()
+			logs = append(logs, m)
+			mu.Unlock()
+		},
+	}, ts.URL)
+	defer c.Close()
+
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetLogLevel(context.Background(), "debug"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CallTool("report", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) != 2 || progress[0].Message != "half way" || progress[1].Progress != 2 || progress[1].Total != 2 {
+		t.Errorf("Unexpected progress notifications %+v", progress)
+	}
+	if len(logs) != 2 || logs[0].Level != "debug" || logs[1].Logger != "report" {
+		t.Errorf("Unexpected log messages %+v", logs)
+	}
+}
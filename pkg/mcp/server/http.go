@@ -0,0 +1,419 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// SessionHeader carries the session id of the Streamable HTTP transport
+const SessionHeader = "Mcp-Session-Id"
+
+// ProtocolHeader carries the negotiated revision on requests after
+// initialize, since 2025-06-18
+const ProtocolHeader = "Mcp-Protocol-Version"
+
+// maxRequestBytes bounds the size of a JSON-RPC message accepted over HTTP
+const maxRequestBytes = 10 << 20
+
+// httpSession is a session of the Streamable HTTP transport. It lives from
+// the initialize request that created it until the client deletes it or it
+// stays idle for longer than the server's SessionTimeout.
+type httpSession struct {
+	*session
+	id     string
+	ctx    context.Context // cancelled when the session ends
+	cancel context.CancelFunc
+
+	// guarded by session.mu
+	lastSeen   time.Time
+	active     int // open streams; a session with one never expires
+	streams    map[int]*eventStream
+	nextStream int
+	listener   int // generation of the GET stream's current writer
+}
+
+// activity records a stream opening (+1) or closing (-1)
+func (hs *httpSession) activity(delta int) {
+	hs.mu.Lock()
+	hs.active += delta
+	hs.lastSeen = time.Now()
+	hs.mu.Unlock()
+}
+
+// publish adds a message to a stream
+func (hs *httpSession) publish(id int, data []byte) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if stream := hs.streams[id]; stream != nil && !stream.closed {
+		backlog := 0
+		if id == listenStream {
+			backlog = streamBacklog
+		}
+		stream.append(data, backlog)
+	}
+}
+
+// ServeHTTP implements the Streamable HTTP transport. POST carries one
+// JSON-RPC message or a batch of them; an initialize request creates a
+// session whose id is returned in the Mcp-Session-Id header and must
+// accompany later requests. Bodies that are no JSON-RPC message are
+// rejected with 400 Bad Request, and batches are answered with a JSON array.
+// tools/call responses are streamed as server-sent events when the client
+// accepts them, other responses are plain JSON and notifications are
+// acknowledged with 202 Accepted. GET opens a stream of server-initiated
+// messages, and either kind of stream is resumed by a GET carrying
+// Last-Event-ID. Progress and log notifications of a streamed call go on its
+// stream, those of other calls on the GET stream. DELETE ends the session.
+// Requests naming a revision the server does not speak in the
+// Mcp-Protocol-Version header are rejected with 400 Bad Request.
+//
+// Requests without a session id are handled without one, so clients that
+// POST each request on its own need not call initialize.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if version := r.Header.Get(ProtocolHeader); version != "" && !supportedVersion(version) {
+		http.Error(w, "Unsupported "+ProtocolHeader+": "+version, http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.handlePost(w, r)
+	case http.MethodGet:
+		s.handleGet(w, r)
+	case http.MethodDelete:
+		s.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// supportedVersion reports whether version is one of ProtocolVersions
+func supportedVersion(version string) bool {
+	for _, supported := range ProtocolVersions {
+		if supported == version {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePost handles one JSON-RPC message or batch
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		rejectMessage(w, errorResponse(nil, protocol.ParseError, "Failed to read request"))
+		return
+	}
+
+	var hs *httpSession
+	if id := r.Header.Get(SessionHeader); id != "" {
+		if hs = s.lookupSession(id); hs == nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	if messages, ok := splitBatch(body); ok {
+		s.handleBatchPost(w, r, hs, messages)
+		return
+	}
+
+	req, resp := parseMessage(body)
+	if req == nil {
+		rejectMessage(w, resp)
+		return
+	}
+
+	if hs == nil && req.Method == "initialize" && !req.isNotification() {
+		s.initializeSession(w, r, req)
+		return
+	}
+
+	var sess *session
+	ctx := r.Context()
+	if hs != nil {
+		sess = hs.session
+	}
+	if req.isNotification() || req.isResponse() {
+		s.handle(ctx, sess, req)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if hs != nil && req.Method == "tools/call" && accepts(r, "text/event-stream") {
+		s.streamResponse(w, r, hs, req)
+		return
+	}
+
+	resp = s.handle(ctx, sess, req)
+	if hs != nil {
+		w.Header().Set(SessionHeader, hs.id)
+	}
+	if resp == nil {
+		// The client cancelled the request and expects no response
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, encodeResponse(resp))
+}
+
+// handleBatchPost answers a batch with the JSON array of its responses, or
+// with 202 Accepted when it holds only notifications and responses. Progress
+// and log notifications of its calls go on the session's GET stream.
+func (s *Server) handleBatchPost(w http.ResponseWriter, r *http.Request, hs *httpSession, messages []json.RawMessage) {
+	var sess *session
+	if hs != nil {
+		sess = hs.session
+		w.Header().Set(SessionHeader, hs.id)
+	}
+
+	b := s.admitBatch(r.Context(), sess, messages)
+	if !b.hasRequests() {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	data := s.runBatch(b, sess, nil)
+	if data == nil {
+		// The client cancelled every request and expects no response
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, data)
+}
+
+// initializeSession answers an initialize request, creating a session when
+// it succeeds
+func (s *Server) initializeSession(w http.ResponseWriter, r *http.Request, req *request) {
+	sess := &session{}
+	resp := s.handle(r.Context(), sess, req)
+	if resp.Error == nil {
+		hs := s.newSession(sess)
+		w.Header().Set(SessionHeader, hs.id)
+		s.logger.Printf("Created session %s", hs.id)
+	}
+	writeJSON(w, encodeResponse(resp))
+}
+
+// streamResponse answers a request with an SSE stream ending in its
+// response, or ending without one when the client cancels the request. The request runs in the session's context rather than the HTTP
+// request's, so a client that loses the connection can resume the stream
+// and still receive the response.
+func (s *Server) streamResponse(w http.ResponseWriter, r *http.Request, hs *httpSession, req *request) {
+	hs.mu.Lock()
+	hs.nextStream++
+	id := hs.nextStream
+	hs.streams[id] = newEventStream()
+	hs.mu.Unlock()
+
+	go func() {
+		// Notifications about the request go on its stream
+		ctx := withSender(hs.ctx, func(data []byte) { hs.publish(id, data) })
+		if resp := s.handle(ctx, hs.session, req); resp != nil {
+			hs.publish(id, encodeResponse(resp))
+		}
+		hs.mu.Lock()
+		if stream := hs.streams[id]; stream != nil {
+			stream.closed = true
+			stream.notify()
+		}
+		hs.mu.Unlock()
+	}()
+
+	startStream(w, hs)
+	hs.writeEvents(w, r, id, 0, 0)
+}
+
+// handleGet opens the session's stream of server-initiated messages, or
+// resumes a stream after the event named by Last-Event-ID
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !accepts(r, "text/event-stream") {
+		http.Error(w, "GET requires Accept: text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+	hs := s.sessionFromHeader(w, r)
+	if hs == nil {
+		return
+	}
+
+	id, seq := listenStream, -1
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		stream, last, ok := parseEventID(lastEventID)
+		if !ok {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		id, seq = stream, last+1
+	}
+
+	hs.mu.Lock()
+	stream := hs.streams[id]
+	listener := 0
+	if stream != nil && id == listenStream {
+		// A new GET takes the stream over from any earlier one, so each
+		// message is delivered on one connection only
+		hs.listener++
+		listener = hs.listener
+		stream.notify()
+		if seq < 0 {
+			seq = stream.next()
+		}
+	}
+	hs.mu.Unlock()
+
+	startStream(w, hs)
+	if stream == nil {
+		// The stream ended and was delivered, so there is nothing to resume
+		return
+	}
+	hs.writeEvents(w, r, id, seq, listener)
+}
+
+// handleDelete ends a session
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	hs := s.sessionFromHeader(w, r)
+	if hs == nil {
+		return
+	}
+	s.endSession(hs)
+	s.logger.Printf("Deleted session %s", hs.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sessionFromHeader looks up the session named by the request, writing an
+// error response and returning nil when there is none
+func (s *Server) sessionFromHeader(w http.ResponseWriter, r *http.Request) *httpSession {
+	id := r.Header.Get(SessionHeader)
+	if id == "" {
+		http.Error(w, "Missing "+SessionHeader+" header", http.StatusBadRequest)
+		return nil
+	}
+	hs := s.lookupSession(id)
+	if hs == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+	}
+	return hs
+}
+
+// newSession registers an initialized session. Expired sessions are swept
+// at the same time, so abandoned ones do not accumulate.
+func (s *Server) newSession(sess *session) *httpSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	hs := &httpSession{
+		session:  sess,
+		id:       newSessionID(),
+		ctx:      ctx,
+		cancel:   cancel,
+		lastSeen: time.Now(),
+		streams:  map[int]*eventStream{listenStream: newEventStream()},
+	}
+	sess.send = func(data []byte) { hs.publish(listenStream, data) }
+
+	s.mu.Lock()
+	var expired []*httpSession
+	for _, other := range s.sessions {
+		if s.expired(other) {
+			expired = append(expired, other)
+		}
+	}
+	s.sessions[hs.id] = hs
+	s.mu.Unlock()
+
+	for _, other := range expired {
+		s.endSession(other)
+	}
+	s.connect(sess)
+	return hs
+}
+
+// lookupSession returns a live session and marks it as used, or nil when
+// the id is unknown or the session has expired
+func (s *Server) lookupSession(id string) *httpSession {
+	s.mu.Lock()
+	hs := s.sessions[id]
+	s.mu.Unlock()
+	if hs == nil {
+		return nil
+	}
+	if s.expired(hs) {
+		s.endSession(hs)
+		return nil
+	}
+
+	hs.mu.Lock()
+	hs.lastSeen = time.Now()
+	hs.mu.Unlock()
+	return hs
+}
+
+// expired reports whether a session has been idle for too long
+func (s *Server) expired(hs *httpSession) bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.active == 0 && time.Since(hs.lastSeen) > s.sessionTimeout
+}
+
+// endSession removes a session and cancels its requests and streams
+func (s *Server) endSession(hs *httpSession) {
+	s.mu.Lock()
+	delete(s.sessions, hs.id)
+	s.mu.Unlock()
+	s.disconnect(hs.session)
+	hs.cancel()
+}
+
+// startStream writes the headers of an SSE response
+func startStream(w http.ResponseWriter, hs *httpSession) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set(SessionHeader, hs.id)
+	w.WriteHeader(http.StatusOK)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// accepts reports whether the request's Accept header lists a media type
+func accepts(r *http.Request, mediaType string) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		accepted, _, _ = strings.Cut(accepted, ";")
+		if strings.TrimSpace(accepted) == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// newSessionID returns a random, unguessable session id
+func newSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// rejectMessage answers a POST whose body is no JSON-RPC message with 400
+// Bad Request, carrying the JSON-RPC error when there is one
+func rejectMessage(w http.ResponseWriter, resp *response) {
+	if resp == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(encodeResponse(resp))
+}
+
+// writeJSON writes an encoded response. JSON-RPC errors are reported with
+// status 200 like results.
+func writeJSON(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imran31415/godemode/benchmark/mcp/client"
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+)
+
+// send issues an HTTP request to the test server, optionally within a session
+func send(t *testing.T, method, url, sessionID, accept, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if sessionID != "" {
+		req.Header.Set(SessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// initializeHTTP runs initialize and notifications/initialized over HTTP
+// and returns the new session's id
+func initializeHTTP(t *testing.T, url string) string {
+	t.Helper()
+	resp := send(t, http.MethodPost, url, "", "application/json", initializeLine)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(SessionHeader)
+	if sessionID == "" {
+		t.Fatal("Expected initialize to return a session id")
+	}
+	resp = send(t, http.MethodPost, url, sessionID, "", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+	return sessionID
+}
+
+// testEvent is an event read from a test stream
+type testEvent struct {
+	id   string
+	data string
+}
+
+// readEvent reads the next event from an SSE stream, skipping comments
+func readEvent(t *testing.T, r *bufio.Reader) testEvent {
+	t.Helper()
+	var event testEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Stream ended early: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.data != "":
+			return event
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestHTTPSessions(t *testing.T) {
+	ts := httptest.NewServer(New(testProvider{}, Options{}))
+	defer ts.Close()
+
+	sessionID := initializeHTTP(t, ts.URL)
+
+	resp := send(t, http.MethodPost, ts.URL, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(SessionHeader) != sessionID {
+		t.Errorf("Expected tools/list within the session, got %d %q", resp.StatusCode, resp.Header.Get(SessionHeader))
+	}
+
+	resp = send(t, http.MethodPost, ts.URL, "unknown", "application/json", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d", resp.StatusCode)
+	}
+
+	resp = send(t, http.MethodGet, ts.URL, "", "text/event-stream", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for GET without a session, got %d", resp.StatusCode)
+	}
+
+	resp = send(t, http.MethodGet, ts.URL, sessionID, "application/json", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("Expected 406 for GET without SSE, got %d", resp.StatusCode)
+	}
+
+	resp = send(t, http.MethodDelete, ts.URL, sessionID, "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204 for DELETE, got %d", resp.StatusCode)
+	}
+
+	resp = send(t, http.MethodPost, ts.URL, sessionID, "application/json", `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 after DELETE, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPBatch(t *testing.T) {
+	ts := httptest.NewServer(New(testProvider{}, Options{}))
+	defer ts.Close()
+
+	sessionID := initializeHTTP(t, ts.URL)
+
+	resp := send(t, http.MethodPost, ts.URL, sessionID, "application/json, text/event-stream",
+		`[{"jsonrpc":"2.0","id":2,"method":"tools/list"},{"jsonrpc":"2.0","method":"notifications/progress"},{"jsonrpc":"2.0","id":3,"method":"ping"}]`)
+	var responses []map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&responses)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(SessionHeader) != sessionID || len(responses) != 2 {
+		t.Errorf("Expected the batch's two responses as JSON, got %d %v", resp.StatusCode, responses)
+	}
+
+	resp = send(t, http.MethodPost, ts.URL, sessionID, "application/json", `[{"jsonrpc":"2.0","method":"notifications/progress"}]`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for a batch of notifications, got %d", resp.StatusCode)
+	}
+
+	// Bodies that are no JSON-RPC message are rejected
+	resp = send(t, http.MethodPost, ts.URL, sessionID, "application/json", `{not json`)
+	var parseError map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&parseError)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(fmt.Sprint(parseError["error"]), "-32700") {
+		t.Errorf("Expected 400 with a parse error, got %d %v", resp.StatusCode, parseError)
+	}
+
+	resp = send(t, http.MethodPost, ts.URL, sessionID, "application/json", `{"jsonrpc":"2.0","method":42}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid notification, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPSessionExpiry(t *testing.T) {
+	ts := httptest.NewServer(New(testProvider{}, Options{SessionTimeout: 20 * time.Millisecond}))
+	defer ts.Close()
+
+	sessionID := initializeHTTP(t, ts.URL)
+	time.Sleep(50 * time.Millisecond)
+
+	resp := send(t, http.MethodPost, ts.URL, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an expired session, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPStreamedCall(t *testing.T) {
+	ts := httptest.NewServer(New(testProvider{}, Options{}))
+	defer ts.Close()
+
+	sessionID := initializeHTTP(t, ts.URL)
+	resp := send(t, http.MethodPost, ts.URL, sessionID, "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"add","arguments":{"a":2,"b":3}}}`)
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected an SSE response, got %q", ct)
+	}
+
+	event := readEvent(t, bufio.NewReader(resp.Body))
+	if event.id != "1-0" {
+		t.Errorf("Expected event id 1-0, got %q", event.id)
+	}
+	var msg protocol.JSONRPCMessage
+	if err := json.Unmarshal([]byte(event.data), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID != float64(2) || msg.Error != nil || !strings.Contains(event.data, `\"sum\":5`) {
+		t.Errorf("Unexpected streamed response: %s", event.data)
+	}
+}
+
+func TestHTTPListenAndResume(t *testing.T) {
+	srv := New(testProvider{}, Options{})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	sessionID := initializeHTTP(t, ts.URL)
+	resp := send(t, http.MethodGet, ts.URL, sessionID, "text/event-stream", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected GET stream, got %d", resp.StatusCode)
+	}
+
+	if err := srv.Notify("notifications/tools/list_changed", nil); err != nil {
+		t.Fatal(err)
+	}
+	first := readEvent(t, bufio.NewReader(resp.Body))
+	resp.Body.Close()
+	if !strings.Contains(first.data, "notifications/tools/list_changed") {
+		t.Errorf("Expected list_changed notification, got %s", first.data)
+	}
+
+	// Notifications sent while disconnected are replayed after Last-Event-ID
+	srv.Notify("notifications/resources/list_changed", nil)
+	srv.Notify("notifications/prompts/list_changed", nil)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(SessionHeader, sessionID)
+	req.Header.Set("Last-Event-ID", first.id)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	r := bufio.NewReader(resp.Body)
+	second, third := readEvent(t, r), readEvent(t, r)
+	if !strings.Contains(second.data, "resources/list_changed") || !strings.Contains(third.data, "prompts/list_changed") {
+		t.Errorf("Expected missed notifications in order, got %s and %s", second.data, third.data)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	srv := New(testProvider{}, Options{})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c := client.NewHTTPMCPClient(ts.URL)
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if c.SessionID() == "" {
+		t.Fatal("Expected the client to keep the session id")
+	}
+
+	tools, err := c.ListTools()
+	if err != nil || len(tools) != 3 {
+		t.Fatalf("Expected 3 tools, got %d (%v)", len(tools), err)
+	}
+
+	result, err := c.CallTool("add", map[string]interface{}{"a": 1, "b": 2})
+	if err != nil || result.IsError || !strings.Contains(result.Content[0].Text, `"sum":3`) {
+		t.Fatalf("Unexpected streamed call result %+v (%v)", result, err)
+	}
+
+	received := make(chan string, 1)
+	c.SetNotificationHandler(func(msg *protocol.JSONRPCMessage) {
+		received <- msg.Method
+	})
+	if err := c.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Notify("notifications/tools/list_changed", nil)
+	select {
+	case method := <-received:
+		if method != "notifications/tools/list_changed" {
+			t.Errorf("Unexpected notification %s", method)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a notification")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	srv.mu.Lock()
+	remaining := len(srv.sessions)
+	srv.mu.Unlock()
+	if remaining != 0 {
+		t.Errorf("Expected Close to end the session, %d remain", remaining)
+	}
+}
+
+func TestHTTPClientSessionExpiry(t *testing.T) {
+	ts := httptest.NewServer(New(testProvider{}, Options{SessionTimeout: 20 * time.Millisecond}))
+	defer ts.Close()
+
+	c := client.NewHTTPMCPClient(ts.URL)
+	if err := c.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	expired := c.SessionID()
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := c.ListTools(); err != nil {
+		t.Fatalf("Expected the client to start a new session, got %v", err)
+	}
+	if c.SessionID() == expired || c.SessionID() == "" {
+		t.Errorf("Expected a new session id, got %q", c.SessionID())
+	}
+	c.Close()
+}
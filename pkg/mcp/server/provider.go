@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// ToolProvider is a set of tools a Server exposes. Tools lists them with
+// their parameters, from which the input schemas of tools/list are derived.
+type ToolProvider interface {
+	Tools() []spec.ToolDefinition
+	CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error)
+}
+
+// ToolWatcher is implemented by providers whose tools change while they are
+// served. The server advertises tools.listChanged for them, and WatchTools
+// receives the function sending notifications/tools/list_changed to clients.
+type ToolWatcher interface {
+	WatchTools(changed func())
+}
+
+// ResourceProvider is implemented by providers that also expose resources.
+// The resources capability is advertised only for such providers.
+type ResourceProvider interface {
+	Resources() []protocol.Resource
+	ResourceTemplates() []protocol.ResourceTemplate
+	ReadResource(ctx context.Context, uri string) (*protocol.ReadResourceResult, error)
+}
+
+// PromptProvider is implemented by providers that also expose prompts. The
+// prompts capability is advertised only for such providers.
+type PromptProvider interface {
+	Prompts() []protocol.Prompt
+	GetPrompt(ctx context.Context, name string, args map[string]string) (*protocol.GetPromptResult, error)
+}
+
+// Caller is the Call method of generated registries
+type Caller interface {
+	Call(name string, args map[string]interface{}) (interface{}, error)
+}
+
+// ContextCaller is implemented by registries whose tools accept a context.
+// FromRegistry prefers CallContext, so tools see requests being cancelled.
+type ContextCaller interface {
+	CallContext(ctx context.Context, name string, args map[string]interface{}) (interface{}, error)
+}
+
+// FromRegistry adapts a generated registry (or any registry spec.FromRegistry
+// can read) to a ToolProvider. Tool definitions are read once, so tools
+// registered afterwards are not served. Registries generated with resources
+// or prompts also serve those, through their ListResources, ReadResource,
+// ListPrompts and GetPrompt methods.
+func FromRegistry(registry Caller) (ToolProvider, error) {
+	tools, err := spec.FromRegistry(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &registryProvider{registry: registry, tools: tools}
+	value := reflect.ValueOf(registry)
+	for _, method := range []string{"ListResources", "ReadResource", "ListPrompts", "GetPrompt"} {
+		if !value.MethodByName(method).IsValid() {
+			return provider, nil
+		}
+	}
+	return &registryContextProvider{registryProvider: provider, value: value}, nil
+}
+
+// registryProvider serves the tools of a registry
+type registryProvider struct {
+	registry Caller
+	tools    []spec.ToolDefinition
+}
+
+func (p *registryProvider) Tools() []spec.ToolDefinition {
+	return p.tools
+}
+
+func (p *registryProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	if caller, ok := p.registry.(ContextCaller); ok {
+		return caller.CallContext(ctx, name, args)
+	}
+	return p.registry.Call(name, args)
+}
+
+// registryContextProvider also serves the resources and prompts of a
+// registry generated with a context.go. Their types are declared by each
+// generated package, so they are read by field name.
+type registryContextProvider struct {
+	*registryProvider
+	value reflect.Value
+}
+
+// listResources reads ListResources, splitting fixed resources from templates
+func (p *registryContextProvider) listResources() ([]protocol.Resource, []protocol.ResourceTemplate) {
+	var resources []protocol.Resource
+	var templates []protocol.ResourceTemplate
+	list, ok := p.call("ListResources")
+	if !ok {
+		return nil, nil
+	}
+	for i := 0; i < list.Len(); i++ {
+		info := reflect.Indirect(list.Index(i))
+		name, description := stringField(info, "Name"), stringField(info, "Description")
+		uri, mimeType := stringField(info, "URI"), stringField(info, "MimeType")
+		if variables := info.FieldByName("Variables"); variables.IsValid() && variables.Kind() == reflect.Slice && variables.Len() > 0 {
+			templates = append(templates, protocol.ResourceTemplate{URITemplate: uri, Name: name, Description: description, MimeType: mimeType})
+			continue
+		}
+		resources = append(resources, protocol.Resource{URI: uri, Name: name, Description: description, MimeType: mimeType})
+	}
+	return resources, templates
+}
+
+func (p *registryContextProvider) Resources() []protocol.Resource {
+	resources, _ := p.listResources()
+	return resources
+}
+
+func (p *registryContextProvider) ResourceTemplates() []protocol.ResourceTemplate {
+	_, templates := p.listResources()
+	return templates
+}
+
+func (p *registryContextProvider) ReadResource(ctx context.Context, uri string) (*protocol.ReadResourceResult, error) {
+	reader, ok := p.registry.(interface {
+		ReadResource(uri string) (string, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("%T.ReadResource has an unexpected signature", p.registry)
+	}
+	text, err := reader.ReadResource(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := protocol.ResourceContents{URI: uri, Text: text}
+	for _, resource := range p.Resources() {
+		if resource.URI == uri {
+			contents.MimeType = resource.MimeType
+		}
+	}
+	return &protocol.ReadResourceResult{Contents: []protocol.ResourceContents{contents}}, nil
+}
+
+func (p *registryContextProvider) Prompts() []protocol.Prompt {
+	list, ok := p.call("ListPrompts")
+	if !ok {
+		return nil
+	}
+	prompts := make([]protocol.Prompt, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		info := reflect.Indirect(list.Index(i))
+		prompt := protocol.Prompt{Name: stringField(info, "Name"), Description: stringField(info, "Description")}
+		if args := info.FieldByName("Arguments"); args.IsValid() && args.Kind() == reflect.Slice {
+			for j := 0; j < args.Len(); j++ {
+				arg := reflect.Indirect(args.Index(j))
+				required := arg.FieldByName("Required")
+				prompt.Arguments = append(prompt.Arguments, protocol.PromptArgument{
+					Name:        stringField(arg, "Name"),
+					Description: stringField(arg, "Description"),
+					Required:    required.IsValid() && required.Kind() == reflect.Bool && required.Bool(),
+				})
+			}
+		}
+		prompts = append(prompts, prompt)
+	}
+	return prompts
+}
+
+func (p *registryContextProvider) GetPrompt(ctx context.Context, name string, args map[string]string) (*protocol.GetPromptResult, error) {
+	method := p.value.MethodByName("GetPrompt")
+	if t := method.Type(); t.NumIn() != 2 || t.In(0).Kind() != reflect.String || t.In(1) != reflect.TypeOf(args) ||
+		t.NumOut() != 2 || t.Out(0).Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%T.GetPrompt has an unexpected signature", p.registry)
+	}
+	if args == nil {
+		args = map[string]string{}
+	}
+
+	out := method.Call([]reflect.Value{reflect.ValueOf(name), reflect.ValueOf(args)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, err
+	}
+
+	result := &protocol.GetPromptResult{Messages: []protocol.PromptMessage{}}
+	for _, prompt := range p.Prompts() {
+		if prompt.Name == name {
+			result.Description = prompt.Description
+		}
+	}
+	for i := 0; i < out[0].Len(); i++ {
+		message := reflect.Indirect(out[0].Index(i))
+		result.Messages = append(result.Messages, protocol.PromptMessage{
+			Role:    stringField(message, "Role"),
+			Content: protocol.Content{Type: "text", Text: stringField(message, "Text")},
+		})
+	}
+	return result, nil
+}
+
+// call invokes a no-argument registry method returning a slice
+func (p *registryContextProvider) call(name string) (reflect.Value, bool) {
+	method := p.value.MethodByName(name)
+	if method.Type().NumIn() != 0 || method.Type().NumOut() != 1 || method.Type().Out(0).Kind() != reflect.Slice {
+		return reflect.Value{}, false
+	}
+	return method.Call(nil)[0], true
+}
+
+// stringField returns a string field of a struct, or "" when it is missing
+func stringField(v reflect.Value, name string) string {
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	field := v.FieldByName(name)
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
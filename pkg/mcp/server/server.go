@@ -0,0 +1,654 @@
+// Package server serves tool registries over the Model Context Protocol.
+//
+// A Server exposes any ToolProvider; providers that also implement
+// ResourceProvider or PromptProvider advertise and serve resources and
+// prompts. FromRegistry adapts registries generated by spec-to-godemode, so a
+// generated package becomes an MCP server with a few lines:
+//
+//	provider, err := server.FromRegistry(tools.NewRegistry())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	srv := server.New(provider, server.Options{Name: "tools", Version: "1.0.0"})
+//	log.Fatal(srv.ServeStdio(context.Background(), os.Stdin, os.Stdout))
+//
+// Server is also an http.Handler implementing the Streamable HTTP transport.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// ProtocolVersions lists the MCP revisions the server speaks, newest first.
+// A client requesting another revision is answered with the newest one and
+// decides whether to continue.
+var ProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// Options configures a Server
+type Options struct {
+	Name    string      // reported as serverInfo.name (default "godemode-mcp-server")
+	Version string      // reported as serverInfo.version (default "1.0.0")
+	Logger  *log.Logger // receives a line per request; nil discards them
+
+	// SessionTimeout is how long an HTTP session may stay idle before it
+	// expires (default 30 minutes). Sessions with an open stream stay alive.
+	SessionTimeout time.Duration
+
+	// MaxConcurrentRequests bounds the requests of one stdio client handled
+	// at once (default 16). Further requests wait for a free worker.
+	MaxConcurrentRequests int
+}
+
+// Server dispatches MCP requests to a ToolProvider
+type Server struct {
+	provider       ToolProvider
+	info           protocol.ServerInfo
+	logger         *log.Logger
+	sessionTimeout time.Duration
+	maxConcurrent  int
+
+	mu       sync.Mutex
+	clients  map[*session]struct{}   // connected clients, for Notify
+	sessions map[string]*httpSession // HTTP sessions by id
+}
+
+// New creates a server exposing the tools of provider
+func New(provider ToolProvider, opts Options) *Server {
+	if opts.Name == "" {
+		opts.Name = "godemode-mcp-server"
+	}
+	if opts.Version == "" {
+		opts.Version = "1.0.0"
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.New(io.Discard, "", 0)
+	}
+	if opts.SessionTimeout == 0 {
+		opts.SessionTimeout = 30 * time.Minute
+	}
+	if opts.MaxConcurrentRequests <= 0 {
+		opts.MaxConcurrentRequests = 16
+	}
+
+	s := &Server{
+		provider:       provider,
+		info:           protocol.ServerInfo{Name: opts.Name, Version: opts.Version},
+		logger:         opts.Logger,
+		sessionTimeout: opts.SessionTimeout,
+		maxConcurrent:  opts.MaxConcurrentRequests,
+		clients:        make(map[*session]struct{}),
+		sessions:       make(map[string]*httpSession),
+	}
+	if watcher, ok := provider.(ToolWatcher); ok {
+		watcher.WatchTools(func() {
+			s.Notify("notifications/tools/list_changed", nil)
+		})
+	}
+	return s
+}
+
+// request is an incoming JSON-RPC message. ID is kept raw so responses echo
+// it exactly; it is empty for notifications.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+
+	// Result and Error are set when the message is a response rather than a
+	// request
+	Result json.RawMessage  `json:"result,omitempty"`
+	Error  *json.RawMessage `json:"error,omitempty"`
+}
+
+// isNotification reports whether the sender expects no response
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+// parseMessage decodes one JSON-RPC message. Data that is not JSON is
+// answered with a parse error, and JSON that is no request, notification or
+// response with an invalid request error, carrying the message's id when it
+// is a valid one. MCP ids are strings or numbers, never null.
+func parseMessage(data []byte) (*request, *response) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		if !json.Valid(data) {
+			return nil, errorResponse(nil, protocol.ParseError, "Parse error")
+		}
+		return nil, errorResponse(nil, protocol.InvalidRequest, "Invalid request: not a JSON object")
+	}
+
+	// Responses of a client that could not read the id it answers carry a
+	// null id, and are dropped like other responses
+	id, hasID := fields["id"]
+	if _, hasMethod := fields["method"]; hasMethod && hasID && !validID(id) {
+		return nil, errorResponse(nil, protocol.InvalidRequest, "Invalid request: id must be a string or a number")
+	}
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		if !hasID {
+			// An invalid notification gets no response
+			return nil, nil
+		}
+		return nil, errorResponse(id, protocol.InvalidRequest, "Invalid request")
+	}
+	return &req, nil
+}
+
+// validID reports whether a raw id is a string or a number
+func validID(id json.RawMessage) bool {
+	id = bytes.TrimSpace(id)
+	return len(id) > 0 && (id[0] == '"' || id[0] == '-' || (id[0] >= '0' && id[0] <= '9'))
+}
+
+// isResponse reports whether the message answers a request of the server.
+// The server sends none, so responses are acknowledged and dropped.
+func (r *request) isResponse() bool {
+	return r.Method == "" && len(r.ID) > 0 && (r.Result != nil || r.Error != nil)
+}
+
+// response is an outgoing JSON-RPC response. Unlike protocol.JSONRPCMessage
+// it always carries an id, which is null when the request could not be read.
+type response struct {
+	JSONRPC string             `json:"jsonrpc"`
+	ID      json.RawMessage    `json:"id"`
+	Result  interface{}        `json:"result,omitempty"`
+	Error   *protocol.RPCError `json:"error,omitempty"`
+}
+
+// notification is an outgoing JSON-RPC notification
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// session is the state of one client connection. Requests other than
+// initialize and ping are rejected until initialize has been answered.
+type session struct {
+	mu              sync.Mutex
+	initialized     bool
+	protocolVersion string
+	logLevel        string // set by logging/setLevel
+
+	// send delivers a server-initiated message to the client
+	send func(data []byte)
+
+	// requests in flight by id, so notifications/cancelled can stop them
+	requests map[string]*inflight
+}
+
+// inflight is a request being handled within a session
+type inflight struct {
+	cancel    context.CancelFunc
+	cancelled bool // by the client, which then expects no response
+}
+
+// begin registers a request as in flight and returns its context, which is
+// cancelled when the client cancels the request. finish unregisters it and
+// reports whether it was cancelled, in which case no response is sent.
+func (sess *session) begin(ctx context.Context, id json.RawMessage) (context.Context, func() bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	if sess == nil {
+		return ctx, func() bool { cancel(); return false }
+	}
+
+	key := requestKey(id)
+	req := &inflight{cancel: cancel}
+	sess.mu.Lock()
+	if sess.requests == nil {
+		sess.requests = make(map[string]*inflight)
+	}
+	sess.requests[key] = req
+	sess.mu.Unlock()
+
+	return ctx, func() bool {
+		cancel()
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		if sess.requests[key] == req {
+			delete(sess.requests, key)
+		}
+		return req.cancelled
+	}
+}
+
+// cancel cancels an in-flight request, reporting whether there was one
+func (sess *session) cancel(id json.RawMessage) bool {
+	sess.mu.Lock()
+	req := sess.requests[requestKey(id)]
+	if req != nil {
+		req.cancelled = true
+	}
+	sess.mu.Unlock()
+	if req == nil {
+		return false
+	}
+	req.cancel()
+	return true
+}
+
+// supports reports whether the session negotiated version or a later
+// revision. Requests without a session are answered as the newest revision.
+func (sess *session) supports(version string) bool {
+	if sess == nil {
+		return true
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	// Revisions are dates, so they order as strings
+	return sess.protocolVersion >= version
+}
+
+// requestKey normalizes a request id, so an id echoed by
+// notifications/cancelled matches however it was spaced
+func requestKey(id json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, id); err != nil {
+		return string(id)
+	}
+	return buf.String()
+}
+
+// connect registers a session to receive notifications until disconnect
+func (s *Server) connect(sess *session) {
+	s.mu.Lock()
+	s.clients[sess] = struct{}{}
+	s.mu.Unlock()
+}
+
+// disconnect stops sending notifications to a session
+func (s *Server) disconnect(sess *session) {
+	s.mu.Lock()
+	delete(s.clients, sess)
+	s.mu.Unlock()
+}
+
+// Notify sends a notification, such as notifications/tools/list_changed, to
+// every initialized client: stdio clients and HTTP sessions. HTTP sessions
+// receive it on their GET stream, or when they resume it.
+func (s *Server) Notify(method string, params interface{}) error {
+	data, err := json.Marshal(notification{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	s.mu.Lock()
+	var clients []*session
+	for sess := range s.clients {
+		clients = append(clients, sess)
+	}
+	s.mu.Unlock()
+
+	for _, sess := range clients {
+		sess.mu.Lock()
+		initialized := sess.initialized
+		sess.mu.Unlock()
+		if initialized && sess.send != nil {
+			sess.send(data)
+		}
+	}
+	return nil
+}
+
+// HandleMessage handles a JSON-RPC message or batch outside of any session,
+// as the HTTP transport does without a session id, and returns the encoded
+// response, or nil when there is none to send: for notifications, responses
+// and batches of them. It lets other transports and tests drive the server.
+func (s *Server) HandleMessage(ctx context.Context, data []byte) []byte {
+	return s.handleMessage(ctx, nil, data)
+}
+
+// handleMessage decodes, dispatches and encodes one message or batch
+func (s *Server) handleMessage(ctx context.Context, sess *session, data []byte) []byte {
+	if messages, ok := splitBatch(data); ok {
+		return s.runBatch(s.admitBatch(ctx, sess, messages), sess, nil)
+	}
+
+	req, resp := parseMessage(data)
+	if req != nil {
+		resp = s.handle(ctx, sess, req)
+	}
+	if resp == nil {
+		return nil
+	}
+	return encodeResponse(resp)
+}
+
+// encodeResponse marshals a response. Results are plain data, so failures
+// are reported to the client instead of dropping the response.
+func encodeResponse(resp *response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse(resp.ID, protocol.InternalError, fmt.Sprintf("Failed to marshal result: %v", err)))
+	}
+	return data
+}
+
+// handle dispatches a request and returns its response, or nil when the
+// request is a notification or the client cancelled it
+func (s *Server) handle(ctx context.Context, sess *session, req *request) *response {
+	if resp, ok := s.admit(sess, req); !ok {
+		return resp
+	}
+	ctx, finish := sess.begin(ctx, req.ID)
+	resp := s.dispatch(ctx, sess, req)
+	if finish() {
+		return nil
+	}
+	return resp
+}
+
+// admit checks a message before it is dispatched. It acts on notifications
+// and reports false, with the response to send if any, for messages that
+// are not to be dispatched. Transports call it in the order messages
+// arrive, so a request is never handled ahead of an earlier initialize.
+func (s *Server) admit(sess *session, req *request) (*response, bool) {
+	if req.JSONRPC == "2.0" && req.isResponse() {
+		return nil, false
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.isNotification() {
+			return nil, false
+		}
+		return errorResponse(req.ID, protocol.InvalidRequest, "Invalid request"), false
+	}
+
+	s.logger.Printf("Received method: %s", req.Method)
+
+	if req.isNotification() {
+		if req.Method == "notifications/cancelled" && sess != nil {
+			var params struct {
+				RequestID json.RawMessage `json:"requestId"`
+				Reason    string          `json:"reason"`
+			}
+			if json.Unmarshal(req.Params, &params) == nil && len(params.RequestID) > 0 && sess.cancel(params.RequestID) {
+				s.logger.Printf("Cancelled request %s: %s", params.RequestID, params.Reason)
+			}
+		}
+		return nil, false
+	}
+
+	if req.Method != "initialize" && req.Method != "ping" && sess != nil {
+		sess.mu.Lock()
+		initialized := sess.initialized
+		sess.mu.Unlock()
+		if !initialized {
+			return errorResponse(req.ID, protocol.InvalidRequest, "Server not initialized"), false
+		}
+	}
+	return nil, true
+}
+
+// dispatch runs an admitted request and returns its response
+func (s *Server) dispatch(ctx context.Context, sess *session, req *request) *response {
+	var result interface{}
+	var rpcErr *protocol.RPCError
+	switch req.Method {
+	case "initialize":
+		result, rpcErr = s.initialize(sess, req.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = s.listTools()
+	case "tools/call":
+		result, rpcErr = s.callTool(ctx, sess, req.Params)
+	case "logging/setLevel":
+		result, rpcErr = s.setLogLevel(sess, req.Params)
+	case "resources/list", "resources/templates/list", "resources/read":
+		resources, ok := s.provider.(ResourceProvider)
+		if !ok {
+			return errorResponse(req.ID, protocol.MethodNotFound, fmt.Sprintf("Method not found: %s", req.Method))
+		}
+		result, rpcErr = s.handleResources(ctx, resources, req)
+	case "prompts/list", "prompts/get":
+		prompts, ok := s.provider.(PromptProvider)
+		if !ok {
+			return errorResponse(req.ID, protocol.MethodNotFound, fmt.Sprintf("Method not found: %s", req.Method))
+		}
+		result, rpcErr = s.handlePrompts(ctx, prompts, req)
+	default:
+		return errorResponse(req.ID, protocol.MethodNotFound, fmt.Sprintf("Method not found: %s", req.Method))
+	}
+
+	if rpcErr != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// initialize negotiates the protocol version and reports the capabilities of
+// the provider
+func (s *Server) initialize(sess *session, params json.RawMessage) (interface{}, *protocol.RPCError) {
+	var req protocol.InitializeRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+	if req.ProtocolVersion == "" {
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: "protocolVersion is required"}
+	}
+
+	version := ProtocolVersions[0]
+	for _, supported := range ProtocolVersions {
+		if supported == req.ProtocolVersion {
+			version = supported
+			break
+		}
+	}
+
+	capabilities := protocol.ServerCapabilities{
+		Tools:   &protocol.ToolsCapability{Supported: true},
+		Logging: &protocol.LoggingCapability{Supported: true},
+	}
+	if _, ok := s.provider.(ToolWatcher); ok {
+		capabilities.Tools.ListChanged = true
+	}
+	if _, ok := s.provider.(ResourceProvider); ok {
+		capabilities.Resources = &protocol.ResourcesCapability{Supported: true}
+	}
+	if _, ok := s.provider.(PromptProvider); ok {
+		capabilities.Prompts = &protocol.PromptsCapability{Supported: true}
+	}
+
+	if sess != nil {
+		sess.mu.Lock()
+		sess.initialized = true
+		sess.protocolVersion = version
+		sess.mu.Unlock()
+	}
+	s.logger.Printf("Initialized %s %s with protocol %s", req.ClientInfo.Name, req.ClientInfo.Version, version)
+
+	return protocol.InitializeResult{
+		ProtocolVersion: version,
+		Capabilities:    capabilities,
+		ServerInfo:      s.info,
+	}, nil
+}
+
+// listTools describes every tool with the JSON schema of its arguments, and
+// of its results and its annotations when known
+func (s *Server) listTools() interface{} {
+	definitions := s.provider.Tools()
+	tools := make([]spec.MCPTool, 0, len(definitions))
+	for _, tool := range definitions {
+		tools = append(tools, spec.MCPTool{
+			Name:         tool.Name,
+			Description:  tool.Description,
+			InputSchema:  spec.ToolInputSchema(tool),
+			OutputSchema: spec.ToolOutputSchema(tool),
+			Annotations:  tool.Annotations,
+		})
+	}
+	return struct {
+		Tools []spec.MCPTool `json:"tools"`
+	}{tools}
+}
+
+// setLogLevel sets the least severe level of the log messages tools send to
+// the session
+func (s *Server) setLogLevel(sess *session, params json.RawMessage) (interface{}, *protocol.RPCError) {
+	var req protocol.SetLevelRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+	if logLevelIndex(req.Level) < 0 {
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: fmt.Sprintf("Unknown log level: %q", req.Level)}
+	}
+	if sess != nil {
+		sess.mu.Lock()
+		sess.logLevel = req.Level
+		sess.mu.Unlock()
+	}
+	return struct{}{}, nil
+}
+
+// callTool runs a tool, sending its result as the content matching its type
+// (see toolResult). Failures of the tool itself are returned as an error
+// result so the model can react to them; only malformed requests, unknown
+// tools and tools failing with a *protocol.RPCError, such as one relayed
+// from another server, are JSON-RPC errors. The tool's context carries an Emitter for its
+// progress and log notifications.
+func (s *Server) callTool(ctx context.Context, sess *session, params json.RawMessage) (interface{}, *protocol.RPCError) {
+	var req protocol.CallToolRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: "Tool name is required"}
+	}
+	if !s.hasTool(req.Name) {
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: fmt.Sprintf("Tool not found: %s", req.Name)}
+	}
+	if req.Arguments == nil {
+		req.Arguments = make(map[string]interface{})
+	}
+
+	var progressToken interface{}
+	if req.Meta != nil {
+		progressToken = req.Meta.ProgressToken
+	}
+	ctx = withEmitter(ctx, sess, progressToken)
+
+	s.logger.Printf("Calling tool: %s", req.Name)
+	result, err := s.invoke(ctx, req.Name, req.Arguments)
+	var rpcErr *protocol.RPCError
+	if errors.As(err, &rpcErr) {
+		return nil, rpcErr
+	}
+	if err != nil {
+		return protocol.CallToolResult{
+			Content: []protocol.Content{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+
+	callResult, err := toolResult(result, sess.supports(structuredVersion))
+	if err != nil {
+		return nil, &protocol.RPCError{Code: protocol.InternalError, Message: fmt.Sprintf("Failed to marshal result: %v", err)}
+	}
+	return callResult, nil
+}
+
+// hasTool reports whether the provider lists a tool
+func (s *Server) hasTool(name string) bool {
+	for _, tool := range s.provider.Tools() {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// invoke calls a tool, turning a panic into an error so one faulty tool
+// cannot take the server down
+func (s *Server) invoke(ctx context.Context, name string, args map[string]interface{}) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tool %s panicked: %v", name, r)
+		}
+	}()
+	return s.provider.CallTool(ctx, name, args)
+}
+
+// handleResources serves the resources/* methods
+func (s *Server) handleResources(ctx context.Context, provider ResourceProvider, req *request) (interface{}, *protocol.RPCError) {
+	switch req.Method {
+	case "resources/list":
+		return protocol.ListResourcesResult{Resources: nonNil(provider.Resources())}, nil
+	case "resources/templates/list":
+		return protocol.ListResourceTemplatesResult{ResourceTemplates: nonNil(provider.ResourceTemplates())}, nil
+	}
+
+	var read protocol.ReadResourceRequest
+	if err := decodeParams(req.Params, &read); err != nil {
+		return nil, err
+	}
+	if read.URI == "" {
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: "Resource uri is required"}
+	}
+	result, err := provider.ReadResource(ctx, read.URI)
+	if err != nil {
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: err.Error()}
+	}
+	return result, nil
+}
+
+// handlePrompts serves the prompts/* methods
+func (s *Server) handlePrompts(ctx context.Context, provider PromptProvider, req *request) (interface{}, *protocol.RPCError) {
+	if req.Method == "prompts/list" {
+		return protocol.ListPromptsResult{Prompts: nonNil(provider.Prompts())}, nil
+	}
+
+	var get protocol.GetPromptRequest
+	if err := decodeParams(req.Params, &get); err != nil {
+		return nil, err
+	}
+	if get.Name == "" {
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: "Prompt name is required"}
+	}
+	result, err := provider.GetPrompt(ctx, get.Name, get.Arguments)
+	if err != nil {
+		return nil, &protocol.RPCError{Code: protocol.InvalidParams, Message: err.Error()}
+	}
+	return result, nil
+}
+
+// decodeParams decodes request params, treating absent params as empty
+func decodeParams(params json.RawMessage, v interface{}) *protocol.RPCError {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &protocol.RPCError{Code: protocol.InvalidParams, Message: fmt.Sprintf("Invalid params: %v", err)}
+	}
+	return nil
+}
+
+// errorResponse builds a JSON-RPC error response
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	return &response{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &protocol.RPCError{Code: code, Message: message},
+	}
+}
+
+// nonNil returns an empty slice for nil so lists encode as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
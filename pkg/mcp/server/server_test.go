@@ -0,0 +1,529 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/benchmark/mcp/protocol"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+type testProvider struct{}
+
+func (testProvider) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{
+		{Name: "add", Description: "Add two numbers", Parameters: []spec.Parameter{
+			{Name: "a", Type: "number", Required: true},
+			{Name: "b", Type: "number", Required: true},
+		}},
+		{Name: "fail", Description: "Always fails"},
+		{Name: "panic", Description: "Always panics"},
+	}
+}
+
+func (testProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "add":
+		a, _ := args["a"].(float64)
+		b, _ := args["b"].(float64)
+		return map[string]interface{}{"sum": a + b}, nil
+	case "fail":
+		return nil, errors.New("boom")
+	}
+	panic("unexpected call")
+}
+
+// serve runs a stdio session over the given lines and decodes the responses
+func serve(t *testing.T, srv *Server, lines ...string) []map[string]interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	if err := srv.ServeStdio(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	var responses []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("Invalid response %q: %v", line, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// byID indexes responses by id, since stdio requests complete in any order
+func byID(responses []map[string]interface{}) map[string]map[string]interface{} {
+	indexed := make(map[string]map[string]interface{}, len(responses))
+	for _, resp := range responses {
+		indexed[fmt.Sprint(resp["id"])] = resp
+	}
+	return indexed
+}
+
+const initializeLine = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1"}}}`
+
+func TestInitialize(t *testing.T) {
+	srv := New(testProvider{}, Options{Name: "calc", Version: "2.0.0"})
+	responses := serve(t, srv,
+		`{"jsonrpc":"2.0","id":0,"method":"tools/list"}`,
+		initializeLine,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+	)
+
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses (none for the notification), got %d: %v", len(responses), responses)
+	}
+	if errObj, ok := responses[0]["error"].(map[string]interface{}); !ok || !strings.Contains(errObj["message"].(string), "not initialized") {
+		t.Errorf("Expected tools/list before initialize to fail, got %v", responses[0])
+	}
+
+	result := responses[1]["result"].(map[string]interface{})
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("Expected requested version to be accepted, got %v", result["protocolVersion"])
+	}
+	info := result["serverInfo"].(map[string]interface{})
+	if info["name"] != "calc" || info["version"] != "2.0.0" {
+		t.Errorf("Unexpected serverInfo: %v", info)
+	}
+	capabilities := result["capabilities"].(map[string]interface{})
+	if _, ok := capabilities["tools"]; !ok {
+		t.Errorf("Expected tools capability, got %v", capabilities)
+	}
+	if _, ok := capabilities["resources"]; ok {
+		t.Errorf("Did not expect resources capability, got %v", capabilities)
+	}
+
+	if version := responses[2]["result"].(map[string]interface{})["protocolVersion"]; version != ProtocolVersions[0] {
+		t.Errorf("Expected fallback to %s, got %v", ProtocolVersions[0], version)
+	}
+}
+
+func TestToolsListAndCall(t *testing.T) {
+	srv := New(testProvider{}, Options{})
+	responses := serve(t, srv,
+		initializeLine,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"add","arguments":{"a":2,"b":3}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"fail"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"missing"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"panic"}}`,
+		`{"jsonrpc":"2.0","id":"seven","method":"unknown/method"}`,
+	)
+	if len(responses) != 7 {
+		t.Fatalf("Expected 7 responses, got %d", len(responses))
+	}
+	if responses[0]["id"] != float64(1) {
+		t.Errorf("Expected initialize to be answered first, got %v", responses[0])
+	}
+	indexed := byID(responses)
+
+	tools := indexed["2"]["result"].(map[string]interface{})["tools"].([]interface{})
+	if len(tools) != 3 {
+		t.Fatalf("Expected 3 tools, got %v", tools)
+	}
+	schema := tools[0].(map[string]interface{})["inputSchema"].(map[string]interface{})
+	if !reflect.DeepEqual(schema["required"], []interface{}{"a", "b"}) {
+		t.Errorf("Expected required a and b, got %v", schema["required"])
+	}
+
+	call := indexed["3"]["result"].(map[string]interface{})
+	text := call["content"].([]interface{})[0].(map[string]interface{})["text"]
+	if text != `{"sum":5}` || call["isError"] == true {
+		t.Errorf("Unexpected add result: %v", call)
+	}
+
+	failed := indexed["4"]["result"].(map[string]interface{})
+	if failed["isError"] != true || !strings.Contains(fmt.Sprint(failed["content"]), "boom") {
+		t.Errorf("Expected tool error as an error result, got %v", failed)
+	}
+
+	if code := indexed["5"]["error"].(map[string]interface{})["code"]; code != float64(-32602) {
+		t.Errorf("Expected InvalidParams for unknown tool, got %v", indexed["5"])
+	}
+	if indexed["6"]["result"].(map[string]interface{})["isError"] != true {
+		t.Errorf("Expected panicking tool to return an error result, got %v", indexed["6"])
+	}
+	if seven, ok := indexed["seven"]; !ok || seven["error"].(map[string]interface{})["code"] != float64(-32601) {
+		t.Errorf("Expected MethodNotFound echoing the id, got %v", responses)
+	}
+}
+
+func TestHandleMessageErrors(t *testing.T) {
+	srv := New(testProvider{}, Options{})
+
+	resp := srv.HandleMessage(context.Background(), []byte(`{not json`))
+	if !strings.Contains(string(resp), `"id":null`) || !strings.Contains(string(resp), "-32700") {
+		t.Errorf("Expected parse error with null id, got %s", resp)
+	}
+
+	resp = srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"1.0","id":1,"method":"ping"}`))
+	if !strings.Contains(string(resp), "-32600") {
+		t.Errorf("Expected invalid request, got %s", resp)
+	}
+
+	if resp := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled"}`)); resp != nil {
+		t.Errorf("Expected no response to a notification, got %s", resp)
+	}
+
+	// Invalid notifications are not answered either
+	if resp := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","method":42}`)); resp != nil {
+		t.Errorf("Expected no response to an invalid notification, got %s", resp)
+	}
+
+	resp = srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":null,"method":"ping"}`))
+	if !strings.Contains(string(resp), `"id":null`) || !strings.Contains(string(resp), "-32600") {
+		t.Errorf("Expected a null id to be an invalid request, got %s", resp)
+	}
+
+	resp = srv.HandleMessage(context.Background(), []byte(`"ping"`))
+	if !strings.Contains(string(resp), "-32600") {
+		t.Errorf("Expected a message that is no object to be an invalid request, got %s", resp)
+	}
+}
+
+func TestHandleMessageBatch(t *testing.T) {
+	srv := New(testProvider{}, Options{})
+
+	resp := srv.HandleMessage(context.Background(), []byte(`[
+		{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"add","arguments":{"a":1,"b":2}}},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":"two","method":"nope"},
+		{"jsonrpc":"2.0","id":3,"method":"initialize","params":{"protocolVersion":"2025-06-18"}},
+		42
+	]`))
+	var responses []map[string]interface{}
+	if err := json.Unmarshal(resp, &responses); err != nil || len(responses) != 4 {
+		t.Fatalf("Expected 4 responses in an array, got %s", resp)
+	}
+	indexed := byID(responses)
+	if !strings.Contains(fmt.Sprint(indexed["1"]["result"]), "sum") {
+		t.Errorf("Expected the call's result, got %v", indexed["1"])
+	}
+	if !strings.Contains(fmt.Sprint(indexed["two"]["error"]), "-32601") {
+		t.Errorf("Expected method not found, got %v", indexed["two"])
+	}
+	if !strings.Contains(fmt.Sprint(indexed["3"]["error"]), "batch") {
+		t.Errorf("Expected initialize to be rejected in a batch, got %v", indexed["3"])
+	}
+	if !strings.Contains(fmt.Sprint(indexed["<nil>"]["error"]), "-32600") {
+		t.Errorf("Expected the number to be an invalid request, got %v", indexed["<nil>"])
+	}
+
+	if resp := srv.HandleMessage(context.Background(), []byte(`[{"jsonrpc":"2.0","method":"notifications/initialized"}]`)); resp != nil {
+		t.Errorf("Expected no response to a batch of notifications, got %s", resp)
+	}
+	resp = srv.HandleMessage(context.Background(), []byte(`[]`))
+	if !strings.HasPrefix(string(resp), "{") || !strings.Contains(string(resp), "-32600") {
+		t.Errorf("Expected a single invalid request error for an empty batch, got %s", resp)
+	}
+
+	// stdio answers a batch on one line
+	var out bytes.Buffer
+	in := initializeLine + "\n" + `[{"jsonrpc":"2.0","id":2,"method":"ping"},{"jsonrpc":"2.0","id":3,"method":"ping"}]` + "\n"
+	if err := srv.ServeStdio(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "[") || strings.Count(lines[1], `"result":{}`) != 2 {
+		t.Errorf("Expected the batch's responses on one line, got %q", out.String())
+	}
+}
+
+// blockingProvider has a tool that runs until its context is cancelled
+type blockingProvider struct {
+	started chan struct{}
+}
+
+func (blockingProvider) Tools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{{Name: "wait"}, {Name: "quick"}}
+}
+
+func (p blockingProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	if name == "quick" {
+		return "done", nil
+	}
+	p.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestServeStdioConcurrency(t *testing.T) {
+	provider := blockingProvider{started: make(chan struct{})}
+	srv := New(provider, Options{})
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- srv.ServeStdio(context.Background(), inR, outW) }()
+
+	out := bufio.NewReader(outR)
+	exchange := func(line string) map[string]interface{} {
+		t.Helper()
+		if line != "" {
+			fmt.Fprintln(inW, line)
+		}
+		data, err := out.ReadBytes('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp map[string]interface{}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	exchange(initializeLine)
+	fmt.Fprintln(inW, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"wait"}}`)
+	<-provider.started
+
+	// A slow call does not hold up others
+	if resp := exchange(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"quick"}}`); resp["id"] != float64(3) {
+		t.Fatalf("Expected quick call to finish first, got %v", resp)
+	}
+
+	// A cancelled call gets no response
+	fmt.Fprintln(inW, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"test"}}`)
+	if resp := exchange(`{"jsonrpc":"2.0","id":4,"method":"ping"}`); resp["id"] != float64(4) {
+		t.Errorf("Expected no response to the cancelled call, got %v", resp)
+	}
+
+	// Disconnecting cancels calls in flight, which still answer
+	fmt.Fprintln(inW, `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"wait"}}`)
+	<-provider.started
+	inW.Close()
+	resp := exchange("")
+	if resp["id"] != float64(5) || !strings.Contains(fmt.Sprint(resp["result"]), "context canceled") {
+		t.Errorf("Expected call cancelled by disconnect, got %v", resp)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("ServeStdio failed: %v", err)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	ts := httptest.NewServer(New(testProvider{}, Options{}))
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, ts.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for PUT, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(ts.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for a notification, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(ts.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Result struct {
+			Tools []spec.MCPTool `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Result.Tools) != 3 {
+		t.Errorf("Expected sessionless tools/list to succeed, got %v", body)
+	}
+}
+
+type testParamInfo struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+type testToolInfo struct {
+	Name        string
+	Description string
+	Parameters  []testParamInfo
+}
+
+type testResourceInfo struct {
+	Name        string
+	Description string
+	MimeType    string
+	URI         string
+	Variables   []testParamInfo
+}
+
+type testPromptInfo struct {
+	Name        string
+	Description string
+	Arguments   []testParamInfo
+}
+
+type testPromptMessage struct {
+	Role string
+	Text string
+}
+
+type testRegistry struct{}
+
+func (testRegistry) ListTools() []*testToolInfo {
+	return []*testToolInfo{{Name: "echo", Parameters: []testParamInfo{{Name: "text", Type: "string", Required: true}}}}
+}
+
+func (testRegistry) Call(name string, args map[string]interface{}) (interface{}, error) {
+	return args["text"], nil
+}
+
+type testContextRegistry struct{ testRegistry }
+
+func (testContextRegistry) ListResources() []testResourceInfo {
+	return []testResourceInfo{
+		{Name: "readme", URI: "docs://readme", MimeType: "text/markdown"},
+		{Name: "user", URI: "users://{id}", Variables: []testParamInfo{{Name: "id"}}},
+	}
+}
+
+func (testContextRegistry) ReadResource(uri string) (string, error) {
+	if uri != "docs://readme" {
+		return "", fmt.Errorf("unknown resource: %s", uri)
+	}
+	return "# Readme", nil
+}
+
+func (testContextRegistry) ListPrompts() []testPromptInfo {
+	return []testPromptInfo{{Name: "greet", Description: "Greet someone", Arguments: []testParamInfo{{Name: "who", Required: true}}}}
+}
+
+func (testContextRegistry) GetPrompt(name string, args map[string]string) ([]testPromptMessage, error) {
+	return []testPromptMessage{{Role: "user", Text: "Hello " + args["who"]}}, nil
+}
+
+func TestFromRegistry(t *testing.T) {
+	provider, err := FromRegistry(testRegistry{})
+	if err != nil {
+		t.Fatalf("Failed to adapt registry: %v", err)
+	}
+	if _, ok := provider.(ResourceProvider); ok {
+		t.Error("Did not expect a registry without resources to provide them")
+	}
+	if tools := provider.Tools(); len(tools) != 1 || tools[0].Name != "echo" || !tools[0].Parameters[0].Required {
+		t.Errorf("Unexpected tools: %v", tools)
+	}
+	if result, err := provider.CallTool(context.Background(), "echo", map[string]interface{}{"text": "hi"}); err != nil || result != "hi" {
+		t.Errorf("Unexpected call result: %v, %v", result, err)
+	}
+
+	provider, err = FromRegistry(testContextRegistry{})
+	if err != nil {
+		t.Fatalf("Failed to adapt registry: %v", err)
+	}
+	resources, ok := provider.(ResourceProvider)
+	if !ok {
+		t.Fatal("Expected registry with resources to provide them")
+	}
+	if got := resources.Resources(); len(got) != 1 || got[0].URI != "docs://readme" {
+		t.Errorf("Unexpected resources: %v", got)
+	}
+	if got := resources.ResourceTemplates(); len(got) != 1 || got[0].URITemplate != "users://{id}" {
+		t.Errorf("Unexpected resource templates: %v", got)
+	}
+	read, err := resources.ReadResource(context.Background(), "docs://readme")
+	if err != nil || read.Contents[0].Text != "# Readme" || read.Contents[0].MimeType != "text/markdown" {
+		t.Errorf("Unexpected resource contents: %v, %v", read, err)
+	}
+
+	prompts := provider.(PromptProvider)
+	if got := prompts.Prompts(); len(got) != 1 || !got[0].Arguments[0].Required {
+		t.Errorf("Unexpected prompts: %v", got)
+	}
+	prompt, err := prompts.GetPrompt(context.Background(), "greet", map[string]string{"who": "Ada"})
+	if err != nil || prompt.Description != "Greet someone" || prompt.Messages[0].Content.Text != "Hello Ada" {
+		t.Errorf("Unexpected prompt: %v, %v", prompt, err)
+	}
+
+	srv := New(provider, Options{})
+	resp := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"docs://missing"}}`))
+	if !strings.Contains(string(resp), "unknown resource") {
+		t.Errorf("Expected read error, got %s", resp)
+	}
+}
+
+// schemaProvider has a tool whose schema uses more than types and enums
+type schemaProvider struct{}
+
+func (schemaProvider) Tools() []spec.ToolDefinition {
+	readOnly := true
+	return []spec.ToolDefinition{{
+		Name: "find",
+		Parameters: []spec.Parameter{
+			{Name: "limit", Type: "int", Default: float64(10), Schema: map[string]interface{}{"minimum": float64(1), "x-unit": "rows"}},
+			{Name: "where", Type: "map[string]interface{}", Required: true, Properties: []spec.Parameter{
+				{Name: "tags", Type: "[]string", Required: true},
+			}},
+		},
+		Annotations: &spec.ToolAnnotations{Title: "Find rows", ReadOnlyHint: &readOnly},
+	}}
+}
+
+func (schemaProvider) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func TestToolsListSchemas(t *testing.T) {
+	responses := byID(serve(t, New(schemaProvider{}, Options{}),
+		initializeLine, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`))
+
+	data, _ := json.Marshal(responses["2"]["result"])
+	var result protocol.ListToolsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Failed to decode tools/list: %v", err)
+	}
+	tool := result.Tools[0]
+
+	if tool.Annotations == nil || tool.Annotations.Title != "Find rows" || tool.Annotations.ReadOnlyHint == nil {
+		t.Errorf("Expected the tool's annotations, got %+v", tool.Annotations)
+	}
+	limit := tool.InputSchema.Properties["limit"]
+	if limit.Minimum == nil || *limit.Minimum != 1 || limit.Default != float64(10) || limit.Extra["x-unit"] != "rows" {
+		t.Errorf("Expected the limit's bounds, default and extra keywords, got %+v", limit)
+	}
+	where := tool.InputSchema.Properties["where"]
+	if tags := where.Properties["tags"]; tags.Type != "array" || tags.Items == nil || tags.Items.Type != "string" {
+		t.Errorf("Expected nested array properties, got %+v", where)
+	}
+	if !reflect.DeepEqual(where.Required, []string{"tags"}) {
+		t.Errorf("Expected nested required fields, got %v", where.Required)
+	}
+
+	// The schema is sent back unchanged by clients that re-encode it
+	again, _ := json.Marshal(result)
+	var want, got interface{}
+	json.Unmarshal(data, &want)
+	json.Unmarshal(again, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the result to survive a round trip\n got: %s\nwant: %s", again, data)
+	}
+}
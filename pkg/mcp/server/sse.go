@@ -0,0 +1,149 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listenStream is the id of a session's GET stream, which carries
+// server-initiated messages. Streams answering a POST are numbered from 1.
+const listenStream = 0
+
+// streamBacklog bounds the events kept on the GET stream for clients
+// resuming it with Last-Event-ID
+const streamBacklog = 100
+
+// keepAliveInterval is how often an idle stream gets an SSE comment, so
+// proxies do not close it while a long call runs
+const keepAliveInterval = 15 * time.Second
+
+// eventStream holds the events of one SSE stream so a client that loses the
+// connection can resume it. Its fields are guarded by the session's mutex.
+type eventStream struct {
+	events []sseEvent
+	first  int           // sequence number of events[0]; older events were dropped
+	closed bool          // no events will be added after the last one
+	wake   chan struct{} // closed and replaced whenever the stream changes
+}
+
+// sseEvent is one event of a stream
+type sseEvent struct {
+	seq  int
+	data []byte
+}
+
+func newEventStream() *eventStream {
+	return &eventStream{wake: make(chan struct{})}
+}
+
+// next is the sequence number of the next event
+func (st *eventStream) next() int {
+	return st.first + len(st.events)
+}
+
+// append adds an event and wakes the writers waiting on the stream
+func (st *eventStream) append(data []byte, backlog int) {
+	st.events = append(st.events, sseEvent{seq: st.next(), data: data})
+	if backlog > 0 && len(st.events) > backlog {
+		dropped := len(st.events) - backlog
+		st.events = append([]sseEvent(nil), st.events[dropped:]...)
+		st.first += dropped
+	}
+	st.notify()
+}
+
+// notify wakes the writers waiting on the stream
+func (st *eventStream) notify() {
+	close(st.wake)
+	st.wake = make(chan struct{})
+}
+
+// since returns the events from sequence number seq on
+func (st *eventStream) since(seq int) []sseEvent {
+	if seq < st.first {
+		seq = st.first
+	}
+	if seq >= st.next() {
+		return nil
+	}
+	return append([]sseEvent(nil), st.events[seq-st.first:]...)
+}
+
+// eventID formats the SSE id of an event. It names the stream so that a
+// Last-Event-ID resumes the right one.
+func eventID(stream, seq int) string {
+	return fmt.Sprintf("%d-%d", stream, seq)
+}
+
+// parseEventID splits an SSE id written by eventID
+func parseEventID(id string) (stream, seq int, ok bool) {
+	streamPart, seqPart, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	stream, err1 := strconv.Atoi(streamPart)
+	seq, err2 := strconv.Atoi(seqPart)
+	return stream, seq, err1 == nil && err2 == nil && stream >= 0 && seq >= 0
+}
+
+// writeEvents writes the events of a stream from sequence number seq on as
+// they arrive. It returns when the stream is closed and fully written, when
+// the client goes away or the session ends, or, for the GET stream, when
+// another GET takes it over. A request stream written to the end is dropped,
+// since its response has been delivered.
+func (hs *httpSession) writeEvents(w http.ResponseWriter, r *http.Request, id, seq, listener int) {
+	flusher, _ := w.(http.Flusher)
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	hs.activity(1)
+	defer hs.activity(-1)
+
+	for {
+		hs.mu.Lock()
+		stream := hs.streams[id]
+		if stream == nil || (id == listenStream && hs.listener != listener) {
+			hs.mu.Unlock()
+			return
+		}
+		events := stream.since(seq)
+		closed := stream.closed
+		wake := stream.wake
+		hs.mu.Unlock()
+
+		for _, event := range events {
+			if _, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", eventID(id, event.seq), event.data); err != nil {
+				return
+			}
+			seq = event.seq + 1
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if closed && len(events) == 0 {
+			hs.mu.Lock()
+			delete(hs.streams, id)
+			hs.mu.Unlock()
+			return
+		}
+		if len(events) > 0 {
+			continue
+		}
+
+		select {
+		case <-wake:
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-hs.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ServeStdio serves one client speaking newline-delimited JSON-RPC, as MCP
+// clients do with servers they launch, until in is exhausted or ctx is done.
+//
+// Requests are handled concurrently, up to Options.MaxConcurrentRequests at
+// a time, so responses may arrive in a different order than the requests;
+// initialize and logging/setLevel are handled before anything read after
+// them, and a batch is answered on one line once all its requests finished.
+// Each request gets a context that is cancelled by notifications/cancelled,
+// after which its response is dropped, and when the client disconnects by
+// closing in or ctx is done. ServeStdio waits for the requests in flight
+// before returning. Messages are written whole and flushed immediately, and
+// notifications sent with Notify or a tool's Emitter are written between
+// responses.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, disconnect := context.WithCancel(ctx)
+	defer disconnect()
+
+	writer := bufio.NewWriter(out)
+	var writeMu sync.Mutex
+	var writeErr error
+	write := func(data []byte) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if writeErr != nil {
+			return
+		}
+		_, err := fmt.Fprintf(writer, "%s\n", data)
+		if err == nil {
+			// Flush every message, or the client waits on a buffered reply
+			err = writer.Flush()
+		}
+		if err != nil {
+			// The client stopped reading, so nothing in flight can reach it
+			s.logger.Printf("Failed to write message: %v", err)
+			writeErr = err
+			disconnect()
+		}
+	}
+
+	sess := &session{send: write}
+	s.connect(sess)
+	defer s.disconnect(sess)
+
+	var workers sync.WaitGroup
+	slots := make(chan struct{}, s.maxConcurrent)
+	serve := func(line []byte) {
+		if messages, ok := splitBatch(line); ok {
+			b := s.admitBatch(ctx, sess, messages)
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				if data := s.runBatch(b, sess, slots); data != nil {
+					write(data)
+				}
+			}()
+			return
+		}
+
+		req, resp := parseMessage(line)
+		if req == nil {
+			if resp != nil {
+				write(encodeResponse(resp))
+			}
+			return
+		}
+		if resp, ok := s.admit(sess, req); !ok {
+			if resp != nil {
+				write(encodeResponse(resp))
+			}
+			return
+		}
+		if req.Method == "initialize" || req.Method == "logging/setLevel" {
+			write(encodeResponse(s.dispatch(ctx, sess, req)))
+			return
+		}
+
+		// Register the request before it waits for a worker, so it can be
+		// cancelled while queued
+		reqCtx, finish := sess.begin(ctx, req.ID)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			var resp *response
+			if acquire(reqCtx, slots) {
+				resp = s.dispatch(reqCtx, sess, req)
+				<-slots
+			}
+			if !finish() && resp != nil {
+				write(encodeResponse(resp))
+			}
+		}()
+	}
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	var err error
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		case readFailure := <-readErr:
+			if readFailure != io.EOF {
+				err = fmt.Errorf("failed to read message: %w", readFailure)
+			}
+			break loop
+		case line := <-lines:
+			serve(line)
+		}
+	}
+
+	disconnect()
+	workers.Wait()
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if writeErr != nil {
+		return fmt.Errorf("failed to write response: %w", writeErr)
+	}
+	return err
+}
+
+// acquire takes a worker slot, preferring a free one over a done context so
+// requests read before a disconnect are still handled. It reports false
+// when ctx is done before a slot frees up.
+func acquire(ctx context.Context, slots chan struct{}) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
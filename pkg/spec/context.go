@@ -0,0 +1,90 @@
+package spec
+
+import "regexp"
+
+// ResourceDefinition represents a normalized resource a server exposes for
+// reading, either at a fixed URI or at a URI template with variables
+type ResourceDefinition struct {
+	Name        string
+	Description string
+	MimeType    string
+	URI         string   // fixed URI, or the template when Variables is set
+	Variables   []string // template variables in order of appearance
+}
+
+// Templated reports whether the resource is addressed by a URI template
+func (r ResourceDefinition) Templated() bool {
+	return len(r.Variables) > 0
+}
+
+// PromptDefinition represents a normalized prompt template and its arguments.
+// Prompt arguments are always strings.
+type PromptDefinition struct {
+	Name        string
+	Description string
+	Arguments   []Parameter
+}
+
+// uriTemplateVariable matches a simple RFC 6570 expression such as {id}
+var uriTemplateVariable = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// URITemplateVariables returns the variable names of a URI template in order
+// of first appearance
+func URITemplateVariables(template string) []string {
+	var vars []string
+	seen := make(map[string]bool)
+	for _, match := range uriTemplateVariable.FindAllStringSubmatch(template, -1) {
+		if name := match[1]; !seen[name] {
+			seen[name] = true
+			vars = append(vars, name)
+		}
+	}
+	return vars
+}
+
+// ToResourceDefinitions converts MCP resources to ResourceDefinitions
+func (s *MCPSpec) ToResourceDefinitions() []ResourceDefinition {
+	resources := make([]ResourceDefinition, len(s.Resources))
+
+	for i, resource := range s.Resources {
+		uri := resource.URI
+		if resource.URITemplate != "" {
+			uri = resource.URITemplate
+		}
+		resources[i] = ResourceDefinition{
+			Name:        resource.Name,
+			Description: resource.Description,
+			MimeType:    resource.MimeType,
+			URI:         uri,
+			Variables:   URITemplateVariables(uri),
+		}
+	}
+
+	return resources
+}
+
+// ToPromptDefinitions converts MCP prompts to PromptDefinitions
+func (s *MCPSpec) ToPromptDefinitions() []PromptDefinition {
+	prompts := make([]PromptDefinition, len(s.Prompts))
+
+	for i, prompt := range s.Prompts {
+		args := make([]Parameter, len(prompt.Arguments))
+		for j, arg := range prompt.Arguments {
+			args[j] = Parameter{
+				Name:        arg.Name,
+				Type:        "string",
+				Description: arg.Description,
+				Required:    arg.Required,
+			}
+		}
+		sortParameters(args)
+
+		prompts[i] = PromptDefinition{
+			Name:        prompt.Name,
+			Description: prompt.Description,
+			Arguments:   args,
+		}
+	}
+
+	return prompts
+}
@@ -0,0 +1,73 @@
+package spec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestURITemplateVariables(t *testing.T) {
+	got := URITemplateVariables("repo://{owner}/{repo}/issues/{number}?owner={owner}")
+	want := []string{"owner", "repo", "number"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if vars := URITemplateVariables("config://app"); len(vars) != 0 {
+		t.Errorf("Expected no variables, got %v", vars)
+	}
+}
+
+func TestMCPResourcesAndPrompts(t *testing.T) {
+	data := []byte(`{
+		"name": "docs",
+		"tools": [{"name": "search", "description": "Search docs", "inputSchema": {"type": "object"}}],
+		"resources": [
+			{"uri": "config://app", "name": "app_config", "mimeType": "application/json"},
+			{"uriTemplate": "users://{userId}/profile", "name": "user-profile"}
+		],
+		"prompts": [
+			{"name": "code_review", "arguments": [
+				{"name": "language"},
+				{"name": "code", "required": true}
+			]}
+		]
+	}`)
+
+	mcpSpec, err := ParseMCPSpecFromBytes(data)
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	if err := mcpSpec.Validate(); err != nil {
+		t.Fatalf("Spec should be valid: %v", err)
+	}
+
+	resources := mcpSpec.ToResourceDefinitions()
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(resources))
+	}
+	if resources[0].Templated() || resources[0].URI != "config://app" {
+		t.Errorf("Expected a fixed config resource, got %+v", resources[0])
+	}
+	if !resources[1].Templated() || resources[1].Variables[0] != "userId" {
+		t.Errorf("Expected a templated profile resource, got %+v", resources[1])
+	}
+
+	prompts := mcpSpec.ToPromptDefinitions()
+	if len(prompts) != 1 || prompts[0].Arguments[0].Name != "code" || !prompts[0].Arguments[0].Required {
+		t.Errorf("Expected the required code argument first, got %+v", prompts)
+	}
+}
+
+func TestMCPValidateResourceNeedsOneURI(t *testing.T) {
+	mcpSpec := &MCPSpec{
+		Name:      "bad",
+		Tools:     []MCPTool{{Name: "t", Description: "d"}},
+		Resources: []MCPResource{{Name: "both", URI: "a://x", URITemplate: "a://{x}"}},
+	}
+
+	err := mcpSpec.Validate()
+	if err == nil || !strings.Contains(err.Error(), "exactly one of uri or uriTemplate") {
+		t.Errorf("Expected a uri validation error, got %v", err)
+	}
+}
@@ -0,0 +1,358 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ExportInfo describes the API that exported specs are generated for
+type ExportInfo struct {
+	Name        string
+	Version     string
+	Description string
+	ServerURL   string // OpenAPI and OpenRPC only: base URL the tools are served from
+}
+
+// ExportMCPSpec describes tools as an MCP spec, so the JSON schemas served by
+// tools/list are derived from the Go registry instead of maintained by hand
+func ExportMCPSpec(info ExportInfo, tools []ToolDefinition) *MCPSpec {
+	mcpSpec := &MCPSpec{
+		Name:        info.Name,
+		Version:     info.Version,
+		Description: info.Description,
+		Tools:       make([]MCPTool, 0, len(tools)),
+	}
+
+	for _, tool := range tools {
+		mcpSpec.Tools = append(mcpSpec.Tools, MCPTool{
+			Name:         tool.Name,
+			Description:  tool.Description,
+			InputSchema:  ToolInputSchema(tool),
+			OutputSchema: ToolOutputSchema(tool),
+			Annotations:  tool.Annotations,
+		})
+	}
+
+	return mcpSpec
+}
+
+// ToolInputSchema builds the JSON schema of a tool's arguments
+func ToolInputSchema(tool ToolDefinition) MCPSchema {
+	return objectSchema(tool.Parameters)
+}
+
+// ToolOutputSchema builds the JSON schema of the object a tool returns, or
+// nil when its fields are not known
+func ToolOutputSchema(tool ToolDefinition) *MCPSchema {
+	if len(tool.Output) == 0 {
+		return nil
+	}
+	schema := objectSchema(tool.Output)
+	return &schema
+}
+
+// objectSchema builds the JSON schema of an object with the given fields
+func objectSchema(fields []Parameter) MCPSchema {
+	schema := MCPSchema{
+		Type:       "object",
+		Properties: make(map[string]MCPProperty, len(fields)),
+	}
+
+	for _, field := range fields {
+		schema.Properties[field.Name] = mcpProperty(field)
+		if field.Required {
+			schema.Required = append(schema.Required, field.Name)
+		}
+	}
+
+	return schema
+}
+
+// mcpProperty builds the JSON schema of one parameter, including the
+// properties of object parameters
+func mcpProperty(param Parameter) MCPProperty {
+	jsonType, format := jsonSchemaType(param.Type)
+	if param.Format != "" {
+		format = param.Format
+	}
+
+	prop := MCPProperty{
+		Type:        jsonType,
+		Description: param.Description,
+		Format:      format,
+		Enum:        param.Enum,
+		Default:     param.Default,
+		Extra:       copyKeywords(param.Schema),
+	}
+	if itemType, ok := arrayItemType(param.Type); ok {
+		itemJSONType, _ := jsonSchemaType(itemType)
+		if itemJSONType != "" {
+			prop.Items = &MCPSchema{Type: itemJSONType}
+		}
+	}
+	if len(param.Properties) > 0 {
+		prop.Properties = make(map[string]MCPProperty, len(param.Properties))
+		for _, inner := range param.Properties {
+			prop.Properties[inner.Name] = mcpProperty(inner)
+			if inner.Required {
+				prop.Required = append(prop.Required, inner.Name)
+			}
+		}
+	}
+	return prop
+}
+
+// ExportOpenAPI describes tools as an OpenAPI 3.1 document with one
+// POST /tools/{name} operation per tool taking its arguments as a JSON body
+func ExportOpenAPI(info ExportInfo, tools []ToolDefinition) *OpenAPISpec {
+	doc := &OpenAPISpec{
+		OpenAPI: "3.1.0",
+		Info: OpenAPIInfo{
+			Title:       info.Name,
+			Description: info.Description,
+			Version:     info.Version,
+		},
+		Paths: make(map[string]OpenAPIPathItem, len(tools)),
+	}
+	if info.ServerURL != "" {
+		doc.Servers = []OpenAPIServer{{URL: info.ServerURL}}
+	}
+
+	for _, tool := range tools {
+		body := &OpenAPISchema{
+			Type:       "object",
+			Properties: make(map[string]*OpenAPISchema, len(tool.Parameters)),
+		}
+		for _, param := range tool.Parameters {
+			body.Properties[param.Name] = openAPIParamSchema(param)
+			if param.Required {
+				body.Required = append(body.Required, param.Name)
+			}
+		}
+
+		op := &OpenAPIOperation{
+			OperationID: tool.Name,
+			Summary:     tool.Description,
+			RequestBody: &OpenAPIRequestBody{
+				Required: len(body.Required) > 0,
+				Content:  map[string]OpenAPIMediaType{"application/json": {Schema: body}},
+			},
+			Responses: map[string]OpenAPIResponse{
+				"200": {
+					Description: "Tool result",
+					Content:     map[string]OpenAPIMediaType{"application/json": {Schema: &OpenAPISchema{}}},
+				},
+				"400": {Description: "Invalid arguments"},
+			},
+		}
+		doc.Paths["/tools/"+tool.Name] = OpenAPIPathItem{Post: op}
+	}
+
+	return doc
+}
+
+// openAPIParamSchema builds the request body schema of one parameter,
+// including the properties of object parameters
+func openAPIParamSchema(param Parameter) *OpenAPISchema {
+	jsonType, format := jsonSchemaType(param.Type)
+	if param.Format != "" {
+		format = param.Format
+	}
+
+	schema := &OpenAPISchema{
+		Type:        jsonType,
+		Format:      format,
+		Description: param.Description,
+		Enum:        param.Enum,
+		Default:     param.Default,
+		Extra:       copyKeywords(param.Schema),
+	}
+	if itemType, ok := arrayItemType(param.Type); ok {
+		itemJSONType, itemFormat := jsonSchemaType(itemType)
+		if itemJSONType != "" {
+			schema.Items = &OpenAPISchema{Type: itemJSONType, Format: itemFormat}
+		}
+	}
+	if len(param.Properties) > 0 {
+		schema.Properties = make(map[string]*OpenAPISchema, len(param.Properties))
+		for _, inner := range param.Properties {
+			schema.Properties[inner.Name] = openAPIParamSchema(inner)
+			if inner.Required {
+				schema.Required = append(schema.Required, inner.Name)
+			}
+		}
+	}
+	return schema
+}
+
+// ExportFormats lists the formats EncodeSpec can write
+var ExportFormats = []SpecFormat{FormatMCP, FormatOpenAPI, FormatOpenRPC, FormatOpenAI, FormatAnthropic}
+
+// ExportRegistry reads the tools of a registry (see FromRegistry) and encodes
+// them with EncodeSpec
+func ExportRegistry(registry interface{}, info ExportInfo, format SpecFormat) ([]byte, error) {
+	tools, err := FromRegistry(registry)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeSpec(info, tools, format)
+}
+
+// EncodeSpec encodes tools as an indented MCP spec, OpenAPI or OpenRPC
+// document, or as a bare list of OpenAI or Anthropic tools
+func EncodeSpec(info ExportInfo, tools []ToolDefinition, format SpecFormat) ([]byte, error) {
+	var doc interface{}
+	switch format {
+	case FormatMCP:
+		doc = ExportMCPSpec(info, tools)
+	case FormatOpenAPI:
+		doc = ExportOpenAPI(info, tools)
+	case FormatOpenRPC:
+		doc = ExportOpenRPC(info, tools)
+	case FormatOpenAI:
+		doc = ExportOpenAITools(tools)
+	case FormatAnthropic:
+		doc = ExportAnthropicTools(tools)
+	default:
+		return nil, fmt.Errorf("cannot export to %s format", format)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s spec: %w", format, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ParametersFromStruct derives tool parameters from the exported fields of a
+// struct (or pointer to one). Names come from json tags, descriptions from
+// `description` tags and allowed values from comma-separated `enum` tags.
+// Fields are required unless they are pointers or tagged omitempty; embedded
+// structs are flattened.
+func ParametersFromStruct(v interface{}) ([]Parameter, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T is not a struct", v)
+	}
+
+	params := structParameters(t)
+	sortParameters(params)
+	return params, nil
+}
+
+// structParameters collects the parameters of a struct type's fields
+func structParameters(t reflect.Type) []Parameter {
+	var params []Parameter
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				params = append(params, structParameters(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		p := Parameter{
+			Name:        name,
+			Type:        goTypeName(field.Type),
+			Description: field.Tag.Get("description"),
+			Required:    field.Type.Kind() != reflect.Ptr && !strings.Contains(","+opts+",", ",omitempty,"),
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			for _, value := range strings.Split(enum, ",") {
+				p.Enum = append(p.Enum, strings.TrimSpace(value))
+			}
+		}
+		params = append(params, p)
+	}
+
+	return params
+}
+
+// goTypeName names a field type the way parsed specs do: basic Go types are
+// kept, slices become []T and other composite types map[string]interface{}
+func goTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return "time.Time"
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t.Kind().String()
+	case reflect.Slice, reflect.Array:
+		return "[]" + goTypeName(t.Elem())
+	case reflect.Map, reflect.Struct:
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+// jsonSchemaType maps a parameter type to a JSON schema type and format. Go
+// types from parsed specs and registries are accepted, as are JSON schema type
+// names and the loose names used by hand-written registries ("str", "list").
+// Types that cannot be described return an empty type, which accepts anything.
+func jsonSchemaType(paramType string) (jsonType, format string) {
+	switch t := strings.ToLower(strings.TrimSpace(paramType)); {
+	case t == "time.time":
+		return "string", "date-time"
+	case t == "string" || t == "str":
+		return "string", ""
+	case t == "int32":
+		return "integer", "int32"
+	case t == "int64":
+		return "integer", "int64"
+	case t == "integer" || t == "int" || t == "int8" || t == "int16" ||
+		t == "uint" || t == "uint8" || t == "uint16" || t == "uint32" || t == "uint64":
+		return "integer", ""
+	case t == "float32":
+		return "number", "float"
+	case t == "number" || t == "float" || t == "float64":
+		return "number", ""
+	case t == "bool" || t == "boolean":
+		return "boolean", ""
+	case t == "array" || t == "list" || strings.HasPrefix(t, "[]"):
+		return "array", ""
+	case t == "object" || t == "map" || t == "struct" || strings.HasPrefix(t, "map["):
+		return "object", ""
+	default:
+		return "", ""
+	}
+}
+
+// arrayItemType returns the element type of a "[]T" parameter type
+func arrayItemType(paramType string) (string, bool) {
+	if !strings.HasPrefix(paramType, "[]") {
+		return "", false
+	}
+	return strings.TrimPrefix(paramType, "[]"), true
+}
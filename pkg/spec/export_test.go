@@ -0,0 +1,159 @@
+package spec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var exportTools = []ToolDefinition{
+	{
+		Name:        "sendEmail",
+		Description: "Send an email",
+		Parameters: []Parameter{
+			{Name: "to", Type: "string", Description: "Recipient", Required: true},
+			{Name: "cc", Type: "[]interface{}", Description: "Copied recipients"},
+			{Name: "priority", Type: "int", Description: "Priority", Enum: []interface{}{float64(1), float64(2)}, Default: float64(1)},
+		},
+	},
+}
+
+func TestExportMCPSpecRoundTrip(t *testing.T) {
+	data, err := json.Marshal(ExportMCPSpec(ExportInfo{Name: "mail", Version: "1.0.0"}, exportTools))
+	if err != nil {
+		t.Fatalf("Failed to marshal spec: %v", err)
+	}
+
+	if report := Lint(data); report.HasErrors() || len(report.Diagnostics) > 0 {
+		t.Errorf("Expected exported spec to lint clean, got:\n%s", report)
+	}
+
+	mcpSpec, err := ParseMCPSpecFromBytes(data)
+	if err != nil {
+		t.Fatalf("Failed to parse exported spec: %v", err)
+	}
+	tools := mcpSpec.ToToolDefinitions()
+	if !reflect.DeepEqual(tools, exportTools) {
+		t.Errorf("Expected round trip to preserve tools\n got: %+v\nwant: %+v", tools, exportTools)
+	}
+
+	withOutput := []ToolDefinition{{
+		Name:        "sendEmail",
+		Description: "Send an email",
+		Output:      []Parameter{{Name: "id", Type: "string", Description: "Message id", Required: true}},
+	}}
+	data, _ = json.Marshal(ExportMCPSpec(ExportInfo{Name: "mail"}, withOutput))
+	mcpSpec, _ = ParseMCPSpecFromBytes(data)
+	if tools := mcpSpec.ToToolDefinitions(); !reflect.DeepEqual(tools[0].Output, withOutput[0].Output) {
+		t.Errorf("Expected round trip to preserve the output, got %+v", tools[0].Output)
+	}
+	if ToolOutputSchema(exportTools[0]) != nil {
+		t.Error("Expected no output schema for a tool without known output")
+	}
+
+	schema := ToolInputSchema(ToolDefinition{Parameters: []Parameter{{Name: "cc", Type: "[]string"}}})
+	if cc := schema.Properties["cc"]; cc.Type != "array" || cc.Items == nil || cc.Items.Type != "string" {
+		t.Errorf("Expected cc to be an array of strings, got %+v", cc)
+	}
+}
+
+func TestExportOpenAPIRoundTrip(t *testing.T) {
+	data, err := json.Marshal(ExportOpenAPI(ExportInfo{Name: "mail", Version: "1.0.0", ServerURL: "http://localhost:8080"}, exportTools))
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+
+	if DetectSpecFormat(data) != FormatOpenAPI {
+		t.Fatal("Expected exported document to be detected as OpenAPI")
+	}
+	if report := Lint(data); report.HasErrors() || len(report.Diagnostics) > 0 {
+		t.Errorf("Expected exported document to lint clean, got:\n%s", report)
+	}
+
+	doc, err := ParseOpenAPISpecFromBytes(data)
+	if err != nil {
+		t.Fatalf("Failed to parse exported document: %v", err)
+	}
+	if doc.OpenAPI != "3.1.0" || doc.Paths["/tools/sendEmail"].Post == nil {
+		t.Fatalf("Expected an OpenAPI 3.1 POST /tools/sendEmail operation, got %+v", doc)
+	}
+
+	tools := doc.ToToolDefinitions()
+	if len(tools) != 1 || tools[0].Name != "sendEmail" || tools[0].Description != "Send an email" {
+		t.Fatalf("Unexpected tools: %+v", tools)
+	}
+	params := tools[0].Parameters
+	if params[0].Name != "to" || !params[0].Required || params[0].Description != "Recipient" {
+		t.Errorf("Expected required to parameter with description, got %+v", params[0])
+	}
+}
+
+type searchArgs struct {
+	pagination
+	Query   string     `json:"query" description:"Search terms"`
+	Sort    string     `json:"sort,omitempty" enum:"newest,oldest"`
+	Since   *time.Time `json:"since"`
+	Labels  []string   `json:"labels,omitempty"`
+	Ignored string     `json:"-"`
+	secret  string
+}
+
+type pagination struct {
+	Page int `json:"page,omitempty"`
+}
+
+func TestParametersFromStruct(t *testing.T) {
+	params, err := ParametersFromStruct(&searchArgs{})
+	if err != nil {
+		t.Fatalf("Failed to read struct: %v", err)
+	}
+
+	want := []Parameter{
+		{Name: "query", Type: "string", Description: "Search terms", Required: true},
+		{Name: "page", Type: "int"},
+		{Name: "sort", Type: "string", Enum: []interface{}{"newest", "oldest"}},
+		{Name: "since", Type: "time.Time"},
+		{Name: "labels", Type: "[]string"},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Unexpected parameters\n got: %+v\nwant: %+v", params, want)
+	}
+
+	if _, err := ParametersFromStruct("not a struct"); err == nil {
+		t.Error("Expected an error for a non-struct value")
+	}
+}
+
+type handWrittenTool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{}
+	Input       interface{}
+}
+
+type handWrittenRegistry struct{ tools []*handWrittenTool }
+
+func (r *handWrittenRegistry) List() []*handWrittenTool { return r.tools }
+
+func TestFromRegistryHandWrittenShapes(t *testing.T) {
+	registry := &handWrittenRegistry{tools: []*handWrittenTool{
+		{Name: "calculateShipping", Parameters: map[string]interface{}{"weight": "number (pounds)", "destination": "object with city, state, zip"}},
+		{Name: "search", Input: searchArgs{}},
+	}}
+
+	tools, err := FromRegistry(registry)
+	if err != nil {
+		t.Fatalf("Failed to read registry: %v", err)
+	}
+
+	shipping := tools[0].Parameters
+	if shipping[0].Name != "destination" || shipping[0].Type != "map[string]interface{}" ||
+		shipping[1].Type != "float64" || shipping[1].Description != "number (pounds)" {
+		t.Errorf("Expected type hints to be parsed, got %+v", shipping)
+	}
+
+	if len(tools[1].Parameters) != 5 || tools[1].Parameters[0].Name != "query" {
+		t.Errorf("Expected parameters from the Input struct, got %+v", tools[1].Parameters)
+	}
+}
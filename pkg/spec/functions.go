@@ -0,0 +1,233 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// OpenAITool is an entry of the tools list of the OpenAI Chat Completions API
+type OpenAITool struct {
+	Type     string         `json:"type"`
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction declares a function the model may call
+type OpenAIFunction struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Parameters  MCPSchema `json:"parameters"`
+	Strict      bool      `json:"strict,omitempty"`
+}
+
+// AnthropicTool is an entry of the tools list of the Anthropic Messages API
+type AnthropicTool struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	InputSchema MCPSchema `json:"input_schema"`
+}
+
+// FunctionSpec is a set of function-calling tool declarations in the OpenAI
+// or Anthropic format
+type FunctionSpec struct {
+	Format    SpecFormat // FormatOpenAI or FormatAnthropic
+	Functions []OpenAIFunction
+}
+
+// functionEntry accepts every shape a tool declaration takes in either API:
+// {"type": "function", "function": {...}} (OpenAI Chat Completions),
+// {"type": "function", "name": ..., "parameters": ...} (OpenAI Responses),
+// {"name": ..., "parameters": ...} (legacy OpenAI functions) and
+// {"name": ..., "input_schema": ...} (Anthropic)
+type functionEntry struct {
+	Type        string          `json:"type"`
+	Function    *OpenAIFunction `json:"function"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  *MCPSchema      `json:"parameters"`
+	InputSchema *MCPSchema      `json:"input_schema"`
+}
+
+// format reports which API an entry was written for. flat is true where the
+// legacy {"name", "parameters"} shape is expected, which an MCP tools list
+// with misnamed input schemas must not be mistaken for.
+func (e functionEntry) format(flat bool) SpecFormat {
+	switch {
+	case e.InputSchema != nil:
+		return FormatAnthropic
+	case e.Function != nil && e.Type == "function":
+		return FormatOpenAI
+	case e.Name != "" && (e.Type == "function" || (flat && e.Type == "" && e.Parameters != nil)):
+		return FormatOpenAI
+	default:
+		return FormatUnknown
+	}
+}
+
+// function returns the declaration of an entry in the OpenAI shape
+func (e functionEntry) function() OpenAIFunction {
+	if e.Function != nil {
+		return *e.Function
+	}
+	fn := OpenAIFunction{Name: e.Name, Description: e.Description}
+	switch {
+	case e.InputSchema != nil:
+		fn.Parameters = *e.InputSchema
+	case e.Parameters != nil:
+		fn.Parameters = *e.Parameters
+	}
+	return fn
+}
+
+// decodeFunctionEntries reads the tool declarations of a document: a bare
+// list, or an object with a tools or functions list. flat reports whether
+// entries may use the legacy {"name", "parameters"} shape.
+func decodeFunctionEntries(data []byte) (entries []functionEntry, flat bool, err error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		err = json.Unmarshal(data, &entries)
+		return entries, true, err
+	}
+
+	var doc struct {
+		Tools     []functionEntry `json:"tools"`
+		Functions []functionEntry `json:"functions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	if len(doc.Functions) > 0 {
+		return doc.Functions, true, nil
+	}
+	return doc.Tools, false, nil
+}
+
+// detectFunctionFormat reports whether data declares OpenAI functions or
+// Anthropic tools. Built-in tools without a schema, such as web search, are
+// ignored; every other entry must be written for the same API.
+func detectFunctionFormat(data []byte) SpecFormat {
+	entries, flat, err := decodeFunctionEntries(data)
+	if err != nil {
+		return FormatUnknown
+	}
+
+	format := FormatUnknown
+	for _, entry := range entries {
+		entryFormat := entry.format(flat)
+		if entryFormat == FormatUnknown {
+			if entry.Type != "" && entry.Type != "function" && entry.Type != "custom" {
+				continue
+			}
+			return FormatUnknown
+		}
+		if format != FormatUnknown && entryFormat != format {
+			return FormatUnknown
+		}
+		format = entryFormat
+	}
+	return format
+}
+
+// ParseFunctionSpec parses OpenAI functions or Anthropic tools from a file
+func ParseFunctionSpec(filePath string) (*FunctionSpec, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function spec file: %w", err)
+	}
+	return ParseFunctionSpecFromBytes(data)
+}
+
+// ParseFunctionSpecFromBytes parses OpenAI functions or Anthropic tools, as
+// sent in the tools (or legacy functions) field of a request or as a bare list
+func ParseFunctionSpecFromBytes(data []byte) (*FunctionSpec, error) {
+	format := detectFunctionFormat(data)
+	if format == FormatUnknown {
+		return nil, fmt.Errorf("failed to parse function spec: expected a list of OpenAI functions or Anthropic tools")
+	}
+
+	entries, flat, err := decodeFunctionEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse function spec: %w", err)
+	}
+
+	spec := &FunctionSpec{Format: format}
+	for _, entry := range entries {
+		if entry.format(flat) == FormatUnknown {
+			continue
+		}
+		spec.Functions = append(spec.Functions, entry.function())
+	}
+	return spec, nil
+}
+
+// Validate validates the function spec
+func (s *FunctionSpec) Validate() error {
+	if len(s.Functions) == 0 {
+		return fmt.Errorf("function spec must declare at least one function")
+	}
+	for i, fn := range s.Functions {
+		if fn.Name == "" {
+			return fmt.Errorf("function at index %d must have a name", i)
+		}
+	}
+	return nil
+}
+
+// ToToolDefinitions converts the declared functions to a common ToolDefinition format
+func (s *FunctionSpec) ToToolDefinitions() []ToolDefinition {
+	tools := make([]ToolDefinition, len(s.Functions))
+	for i, fn := range s.Functions {
+		tools[i] = ToolDefinition{
+			Name:        fn.Name,
+			Description: fn.Description,
+			Parameters:  extractParameters(fn.Parameters),
+		}
+	}
+	return tools
+}
+
+// ExportOpenAITools describes tools as an OpenAI Chat Completions tools list.
+// Both APIs restrict names to letters, digits, underscores and hyphens, so
+// namespaced names such as "mail.send" are written as "mail_send".
+func ExportOpenAITools(tools []ToolDefinition) []OpenAITool {
+	exported := make([]OpenAITool, 0, len(tools))
+	for _, tool := range tools {
+		exported = append(exported, OpenAITool{
+			Type: "function",
+			Function: OpenAIFunction{
+				Name:        functionName(tool.Name),
+				Description: tool.Description,
+				Parameters:  ToolInputSchema(tool),
+			},
+		})
+	}
+	return exported
+}
+
+// ExportAnthropicTools describes tools as an Anthropic Messages API tools
+// list, naming them like ExportOpenAITools
+func ExportAnthropicTools(tools []ToolDefinition) []AnthropicTool {
+	exported := make([]AnthropicTool, 0, len(tools))
+	for _, tool := range tools {
+		exported = append(exported, AnthropicTool{
+			Name:        functionName(tool.Name),
+			Description: tool.Description,
+			InputSchema: ToolInputSchema(tool),
+		})
+	}
+	return exported
+}
+
+// functionName replaces the characters function-calling APIs reject in tool names
+func functionName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
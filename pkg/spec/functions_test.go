@@ -0,0 +1,160 @@
+package spec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testOpenAITools = `{
+	"model": "gpt-4o",
+	"tools": [
+		{"type": "function", "function": {
+			"name": "get_weather",
+			"description": "Get the current weather",
+			"parameters": {
+				"type": "object",
+				"properties": {
+					"city": {"type": "string", "description": "City name"},
+					"unit": {"type": "string", "enum": ["celsius", "fahrenheit"]},
+					"days": {"type": "integer"}
+				},
+				"required": ["city"]
+			},
+			"strict": true
+		}},
+		{"type": "file_search", "vector_store_ids": ["vs_1"]}
+	]
+}`
+
+const testAnthropicTools = `[
+	{"name": "get_weather", "description": "Get the current weather", "input_schema": {
+		"type": "object",
+		"properties": {"city": {"type": "string", "description": "City name"}},
+		"required": ["city"]
+	}},
+	{"type": "web_search_20250305", "name": "web_search", "max_uses": 5}
+]`
+
+func TestDetectFunctionFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want SpecFormat
+	}{
+		{"openai tools", testOpenAITools, FormatOpenAI},
+		{"openai functions", `{"functions": [{"name": "f", "parameters": {"type": "object"}}]}`, FormatOpenAI},
+		{"openai responses", `[{"type": "function", "name": "f", "parameters": {"type": "object"}}]`, FormatOpenAI},
+		{"anthropic list", testAnthropicTools, FormatAnthropic},
+		{"anthropic request", `{"tools": [{"name": "f", "input_schema": {"type": "object"}}]}`, FormatAnthropic},
+		{"mcp", `{"name": "s", "tools": [{"name": "f", "inputSchema": {"type": "object"}}]}`, FormatMCP},
+		{"mcp with misnamed schema", `{"name": "s", "tools": [{"name": "f", "parameters": {"type": "object"}}]}`, FormatMCP},
+		{"openrpc", `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": []}`, FormatOpenRPC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectSpecFormat([]byte(tt.data)); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseFunctionSpec(t *testing.T) {
+	openAI, err := ParseFunctionSpecFromBytes([]byte(testOpenAITools))
+	if err != nil {
+		t.Fatalf("Failed to parse OpenAI tools: %v", err)
+	}
+	anthropic, err := ParseFunctionSpecFromBytes([]byte(testAnthropicTools))
+	if err != nil {
+		t.Fatalf("Failed to parse Anthropic tools: %v", err)
+	}
+
+	// Built-in tools are skipped
+	tools := openAI.ToToolDefinitions()
+	if len(tools) != 1 || len(anthropic.Functions) != 1 {
+		t.Fatalf("Expected a single function in each spec, got %+v and %+v", tools, anthropic.Functions)
+	}
+
+	weather := tools[0]
+	if weather.Name != "get_weather" || weather.Description != "Get the current weather" {
+		t.Errorf("Unexpected tool %+v", weather)
+	}
+	if city := findParam(weather.Parameters, "city"); !city.Required || city.Type != "string" || city.Description != "City name" {
+		t.Errorf("Unexpected city parameter %+v", city)
+	}
+	if unit := findParam(weather.Parameters, "unit"); !reflect.DeepEqual(unit.Enum, []interface{}{"celsius", "fahrenheit"}) {
+		t.Errorf("Unexpected unit enum %v", unit.Enum)
+	}
+	if days := findParam(weather.Parameters, "days"); days.Type != "int" {
+		t.Errorf("Expected int days, got %+v", days)
+	}
+
+	if _, err := ParseFunctionSpecFromBytes([]byte(`{"tools": [{"name": "f", "inputSchema": {}}]}`)); err == nil {
+		t.Error("Expected error for an MCP tools list")
+	}
+}
+
+func TestExportFunctionTools(t *testing.T) {
+	tools := []ToolDefinition{exportTools[0]}
+	tools[0].Name = "mail.sendEmail"
+
+	for _, format := range []SpecFormat{FormatOpenAI, FormatAnthropic} {
+		data, err := EncodeSpec(ExportInfo{}, tools, format)
+		if err != nil {
+			t.Fatalf("EncodeSpec(%s) failed: %v", format, err)
+		}
+		if got := DetectSpecFormat(data); got != format {
+			t.Fatalf("Expected exported tools to be detected as %q, got %q", format, got)
+		}
+		if report := Lint(data); report.HasErrors() || len(report.Diagnostics) > 0 {
+			t.Errorf("Expected exported %s tools to lint clean, got:\n%s", format, report)
+		}
+
+		functionSpec, err := ParseFunctionSpecFromBytes(data)
+		if err != nil {
+			t.Fatalf("Failed to parse exported %s tools: %v", format, err)
+		}
+		parsed := functionSpec.ToToolDefinitions()
+		want := exportTools[0]
+		want.Name = "mail_sendEmail"
+		if !reflect.DeepEqual(parsed, []ToolDefinition{want}) {
+			t.Errorf("Expected %s round trip to preserve tools\n got: %+v\nwant: %+v", format, parsed, want)
+		}
+	}
+
+	// The Anthropic shape is the one the benchmark LLM client sends
+	var decoded []struct {
+		Name        string                 `json:"name"`
+		InputSchema map[string]interface{} `json:"input_schema"`
+	}
+	data, _ := json.Marshal(ExportAnthropicTools(exportTools))
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded[0].InputSchema["type"] != "object" {
+		t.Errorf("Unexpected Anthropic tools %s", data)
+	}
+}
+
+func TestLintFunctions(t *testing.T) {
+	report := Lint([]byte(`{"tools": [
+		{"type": "function", "function": {"name": "a", "parameters": {"type": "object", "properties": {"x": {"type": "string", "description": "X"}}, "required": ["y"]}}},
+		{"type": "function", "function": {"name": "a", "description": "Duplicate", "parameters": {"type": "object"}}}
+	]}`))
+
+	if report.Format != FormatOpenAI {
+		t.Errorf("Expected openai format, got %q", report.Format)
+	}
+	if !hasDiagnostic(report, SeverityWarning, "missing-description", "/tools/0/function/description") {
+		t.Error("Expected missing-description diagnostic")
+	}
+	if !hasDiagnostic(report, SeverityError, "required-not-in-properties", "/tools/0/function/parameters/required/0") {
+		t.Error("Expected required-not-in-properties diagnostic")
+	}
+	if !hasDiagnostic(report, SeverityError, "duplicate-name", "/tools/1/function/name") {
+		t.Error("Expected duplicate-name diagnostic")
+	}
+
+	report = Lint([]byte(testAnthropicTools))
+	if report.Format != FormatAnthropic || len(report.Diagnostics) > 0 {
+		t.Errorf("Expected clean Anthropic tools, got %q:\n%s", report.Format, report)
+	}
+}
@@ -0,0 +1,353 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// GraphQLSchema is a GraphQL schema read from SDL or an introspection result
+type GraphQLSchema struct {
+	QueryType    string
+	MutationType string
+	Types        map[string]*GraphQLType
+}
+
+// GraphQLType is a named type of a GraphQL schema
+type GraphQLType struct {
+	Kind        string // OBJECT, INTERFACE, UNION, ENUM, INPUT_OBJECT or SCALAR
+	Name        string
+	Description string
+	Fields      []GraphQLField // object and interface fields, or input object fields
+	EnumValues  []string
+}
+
+// GraphQLField is a field, argument or input field
+type GraphQLField struct {
+	Name         string
+	Description  string
+	Type         *GraphQLTypeRef
+	Args         []GraphQLField
+	DefaultValue interface{} // arguments and input fields only
+}
+
+// GraphQLTypeRef is a possibly wrapped reference to a named type, in the
+// shape of an introspection result: LIST and NON_NULL wrap OfType, and any
+// other kind names a type
+type GraphQLTypeRef struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name"`
+	OfType *GraphQLTypeRef `json:"ofType"`
+}
+
+// String renders the reference in GraphQL syntax, e.g. "[String!]!"
+func (t *GraphQLTypeRef) String() string {
+	switch t.Kind {
+	case "NON_NULL":
+		return t.OfType.String() + "!"
+	case "LIST":
+		return "[" + t.OfType.String() + "]"
+	default:
+		return t.Name
+	}
+}
+
+// NamedType returns the name of the type the reference ultimately points to
+func (t *GraphQLTypeRef) NamedType() string {
+	for t.Kind == "NON_NULL" || t.Kind == "LIST" {
+		t = t.OfType
+	}
+	return t.Name
+}
+
+// GraphQLOperation describes how a tool generated from a GraphQL schema
+// builds its operation: the root field it calls, a variable per argument and
+// the fields it selects from the result
+type GraphQLOperation struct {
+	Type        string            // "query" or "mutation"
+	Field       string            // root field name
+	Variables   []GraphQLVariable // one per field argument
+	Selection   string            // default selection set, empty for scalar results
+	FieldsParam string            // parameter overriding the selection, if the result has fields
+}
+
+// GraphQLVariable is an operation variable and its GraphQL type
+type GraphQLVariable struct {
+	Name string
+	Type string // e.g. "ID!" or "[String!]"
+}
+
+// graphQLSDLPattern matches the root definitions that identify a schema file
+var graphQLSDLPattern = regexp.MustCompile(`(?m)^\s*(extend\s+)?(type\s+(Query|Mutation)\b|schema\s*(@\w+\s*)*\{)`)
+
+// IsGraphQLSpec reports whether data is a GraphQL SDL schema or introspection result
+func IsGraphQLSpec(data []byte) bool {
+	var introspection struct {
+		Schema json.RawMessage `json:"__schema"`
+		Data   struct {
+			Schema json.RawMessage `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &introspection); err == nil {
+		return introspection.Schema != nil || introspection.Data.Schema != nil
+	}
+	return graphQLSDLPattern.Match(data)
+}
+
+// ParseGraphQLSpec parses a GraphQL schema from an SDL or introspection file
+func ParseGraphQLSpec(filePath string) (*GraphQLSchema, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GraphQL schema file: %w", err)
+	}
+	return ParseGraphQLSpecFromBytes(data)
+}
+
+// ParseGraphQLSpecFromBytes parses a GraphQL schema from SDL or an
+// introspection result
+func ParseGraphQLSpecFromBytes(data []byte) (*GraphQLSchema, error) {
+	if json.Valid(data) {
+		return ParseGraphQLIntrospection(data)
+	}
+	return ParseGraphQLSDL(data)
+}
+
+// maxInputDepth limits how deeply nested input objects are expanded
+const maxInputDepth = 4
+
+// maxSelectionDepth limits how deeply the default selection follows object fields
+const maxSelectionDepth = 2
+
+// ToToolDefinitions converts the fields of the query and mutation root types
+// to tool definitions. Arguments become parameters, with input objects
+// expanded into nested Properties. Tools returning objects get a "fields"
+// parameter (or "returnFields" if an argument is already called fields)
+// taking dotted paths such as "author.name" to override the default
+// selection of scalar fields.
+func (s *GraphQLSchema) ToToolDefinitions() []ToolDefinition {
+	var tools []ToolDefinition
+	seen := make(map[string]bool)
+
+	for _, root := range []struct{ operation, typeName string }{
+		{"query", s.QueryType},
+		{"mutation", s.MutationType},
+	} {
+		rootType := s.Types[root.typeName]
+		if root.typeName == "" || rootType == nil {
+			continue
+		}
+
+		for _, field := range rootType.Fields {
+			name := field.Name
+			if seen[name] {
+				name = root.operation + strings.ToUpper(name[:1]) + name[1:]
+			}
+			seen[name] = true
+
+			tools = append(tools, s.fieldToTool(root.operation, name, field))
+		}
+	}
+
+	return tools
+}
+
+// fieldToTool converts one root field to a tool definition
+func (s *GraphQLSchema) fieldToTool(operation, name string, field GraphQLField) ToolDefinition {
+	description := field.Description
+	if description == "" {
+		description = fmt.Sprintf("GraphQL %s %s returning %s", operation, field.Name, field.Type)
+	}
+
+	op := &GraphQLOperation{Type: operation, Field: field.Name}
+	var params []Parameter
+	for _, arg := range field.Args {
+		op.Variables = append(op.Variables, GraphQLVariable{Name: arg.Name, Type: arg.Type.String()})
+		p := s.inputParameter(arg, map[string]bool{})
+		p.Required = arg.Type.Kind == "NON_NULL" && arg.DefaultValue == nil
+		params = append(params, p)
+	}
+
+	returnType := s.Types[field.Type.NamedType()]
+	if returnType != nil && (len(returnType.Fields) > 0 || returnType.Kind == "UNION") {
+		op.Selection = s.defaultSelection(returnType, 1, map[string]bool{})
+
+		op.FieldsParam = "fields"
+		for _, arg := range field.Args {
+			if arg.Name == op.FieldsParam {
+				op.FieldsParam = "returnFields"
+			}
+		}
+		params = append(params, Parameter{
+			Name:        op.FieldsParam,
+			Type:        "[]string",
+			Description: fmt.Sprintf("%s fields to return as dotted paths, e.g. %q (default: %s)", returnType.Name, s.exampleFieldPath(returnType), selectionPaths(op.Selection)),
+		})
+	}
+
+	sortParameters(params)
+	tool := ToolDefinition{
+		Name:        name,
+		Description: description,
+		Parameters:  params,
+		GraphQL:     op,
+	}
+	if op.Type == "query" {
+		tool.Annotations = &ToolAnnotations{ReadOnlyHint: hint(true)}
+	}
+	return tool
+}
+
+// inputParameter converts an argument or input field to a parameter,
+// expanding input objects into nested properties
+func (s *GraphQLSchema) inputParameter(field GraphQLField, expanding map[string]bool) Parameter {
+	p := Parameter{
+		Name:        field.Name,
+		Type:        s.goType(field.Type),
+		Description: field.Description,
+		Default:     field.DefaultValue,
+	}
+
+	named := s.Types[field.Type.NamedType()]
+	if named == nil {
+		return p
+	}
+
+	switch named.Kind {
+	case "ENUM":
+		if strings.HasPrefix(p.Type, "[]") {
+			break // enums only constrain single values
+		}
+		for _, value := range named.EnumValues {
+			p.Enum = append(p.Enum, value)
+		}
+	case "INPUT_OBJECT":
+		if expanding[named.Name] || len(expanding) >= maxInputDepth {
+			break
+		}
+		expanding[named.Name] = true
+		for _, inner := range named.Fields {
+			prop := s.inputParameter(inner, expanding)
+			prop.Required = inner.Type.Kind == "NON_NULL" && inner.DefaultValue == nil
+			p.Properties = append(p.Properties, prop)
+		}
+		delete(expanding, named.Name)
+		sortParameters(p.Properties)
+	}
+
+	return p
+}
+
+// goType maps a GraphQL type reference to the Go type names used by parameters
+func (s *GraphQLSchema) goType(ref *GraphQLTypeRef) string {
+	if ref.Kind == "NON_NULL" {
+		return s.goType(ref.OfType)
+	}
+	if ref.Kind == "LIST" {
+		switch elem := s.goType(ref.OfType); elem {
+		case "string", "int":
+			return "[]" + elem
+		default:
+			return "[]interface{}"
+		}
+	}
+
+	switch ref.Name {
+	case "ID", "String":
+		return "string"
+	case "Int":
+		return "int"
+	case "Float":
+		return "float64"
+	case "Boolean":
+		return "bool"
+	}
+	if named := s.Types[ref.Name]; named != nil {
+		switch named.Kind {
+		case "ENUM":
+			return "string"
+		case "INPUT_OBJECT":
+			return "map[string]interface{}"
+		}
+	}
+	return "interface{}"
+}
+
+// defaultSelection selects the scalar fields of a type, following object
+// fields up to maxSelectionDepth. Fields with required arguments are skipped.
+func (s *GraphQLSchema) defaultSelection(typ *GraphQLType, depth int, visiting map[string]bool) string {
+	if typ.Kind == "UNION" {
+		return "__typename"
+	}
+
+	visiting[typ.Name] = true
+	defer delete(visiting, typ.Name)
+
+	var parts []string
+	for _, field := range typ.Fields {
+		if hasRequiredArgs(field) {
+			continue
+		}
+
+		named := s.Types[field.Type.NamedType()]
+		if named == nil || named.Kind == "SCALAR" || named.Kind == "ENUM" {
+			parts = append(parts, field.Name)
+			continue
+		}
+		if depth >= maxSelectionDepth || visiting[named.Name] {
+			continue
+		}
+		if inner := s.defaultSelection(named, depth+1, visiting); inner != "" {
+			parts = append(parts, field.Name+" { "+inner+" }")
+		}
+	}
+
+	if len(parts) == 0 {
+		return "__typename"
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasRequiredArgs reports whether a field cannot be selected without arguments
+func hasRequiredArgs(field GraphQLField) bool {
+	for _, arg := range field.Args {
+		if arg.Type.Kind == "NON_NULL" && arg.DefaultValue == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// selectionPaths lists a selection set as dotted paths, e.g.
+// "id title author { name }" becomes "id, title, author.name"
+func selectionPaths(selection string) string {
+	var leaves, prefix []string
+	tokens := strings.Fields(selection)
+	for i, tok := range tokens {
+		switch {
+		case tok == "{":
+		case tok == "}":
+			prefix = prefix[:len(prefix)-1]
+		case i+1 < len(tokens) && tokens[i+1] == "{":
+			prefix = append(prefix, tok)
+		default:
+			leaves = append(leaves, strings.Join(append(append([]string{}, prefix...), tok), "."))
+		}
+	}
+	return strings.Join(leaves, ", ")
+}
+
+// exampleFieldPath returns a field path of a type for documentation,
+// preferring a nested path through its first object field
+func (s *GraphQLSchema) exampleFieldPath(typ *GraphQLType) string {
+	for _, field := range typ.Fields {
+		if named := s.Types[field.Type.NamedType()]; named != nil && named.Kind == "OBJECT" && len(named.Fields) > 0 {
+			return field.Name + "." + named.Fields[0].Name
+		}
+	}
+	if len(typ.Fields) > 0 {
+		return typ.Fields[0].Name
+	}
+	return "__typename"
+}
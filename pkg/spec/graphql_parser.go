@@ -0,0 +1,799 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseGraphQLSDL parses a GraphQL schema written in the schema definition
+// language. Type extensions are merged into their types, and Query and
+// Mutation are used as root types when there is no schema definition.
+func ParseGraphQLSDL(data []byte) (*GraphQLSchema, error) {
+	p := &sdlParser{lex: &sdlLexer{src: string(data)}}
+	schema := &GraphQLSchema{Types: make(map[string]*GraphQLType)}
+
+	err := p.next()
+	for err == nil && p.tok.kind != tokEOF {
+		err = p.parseDefinition(schema)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GraphQL schema: line %d: %w", p.lex.line, err)
+	}
+
+	if schema.QueryType == "" && schema.Types["Query"] != nil {
+		schema.QueryType = "Query"
+	}
+	if schema.MutationType == "" && schema.Types["Mutation"] != nil {
+		schema.MutationType = "Mutation"
+	}
+	return schema, nil
+}
+
+// ParseGraphQLIntrospection parses the JSON result of a GraphQL introspection
+// query, either the whole response ({"data": {"__schema": ...}}) or its data
+func ParseGraphQLIntrospection(data []byte) (*GraphQLSchema, error) {
+	var result struct {
+		Data   *introspectionData   `json:"data"`
+		Schema *introspectionSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse GraphQL introspection result: %w", err)
+	}
+
+	raw := result.Schema
+	if raw == nil && result.Data != nil {
+		raw = result.Data.Schema
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("GraphQL introspection result has no __schema")
+	}
+
+	schema := &GraphQLSchema{Types: make(map[string]*GraphQLType, len(raw.Types))}
+	if raw.QueryType != nil {
+		schema.QueryType = raw.QueryType.Name
+	}
+	if raw.MutationType != nil {
+		schema.MutationType = raw.MutationType.Name
+	}
+
+	for _, t := range raw.Types {
+		typ := &GraphQLType{Kind: t.Kind, Name: t.Name, Description: t.Description}
+		for _, f := range t.Fields {
+			field, err := f.toField()
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.Name, f.Name, err)
+			}
+			typ.Fields = append(typ.Fields, field)
+		}
+		for _, f := range t.InputFields {
+			field, err := f.toField()
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.Name, f.Name, err)
+			}
+			typ.Fields = append(typ.Fields, field)
+		}
+		for _, v := range t.EnumValues {
+			typ.EnumValues = append(typ.EnumValues, v.Name)
+		}
+		schema.Types[t.Name] = typ
+	}
+
+	return schema, nil
+}
+
+// introspectionData is the data member of an introspection response
+type introspectionData struct {
+	Schema *introspectionSchema `json:"__schema"`
+}
+
+// introspectionSchema is the __schema object of an introspection result
+type introspectionSchema struct {
+	QueryType    *struct{ Name string } `json:"queryType"`
+	MutationType *struct{ Name string } `json:"mutationType"`
+	Types        []struct {
+		Kind        string                  `json:"kind"`
+		Name        string                  `json:"name"`
+		Description string                  `json:"description"`
+		Fields      []introspectionField    `json:"fields"`
+		InputFields []introspectionField    `json:"inputFields"`
+		EnumValues  []struct{ Name string } `json:"enumValues"`
+	} `json:"types"`
+}
+
+// introspectionField is a field, argument or input field of an introspection result
+type introspectionField struct {
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	Args         []introspectionField `json:"args"`
+	Type         *GraphQLTypeRef      `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+// toField converts an introspection field, parsing its default value literal
+func (f introspectionField) toField() (GraphQLField, error) {
+	field := GraphQLField{Name: f.Name, Description: f.Description, Type: f.Type}
+	if field.Type == nil {
+		return field, fmt.Errorf("missing type")
+	}
+
+	for _, a := range f.Args {
+		arg, err := a.toField()
+		if err != nil {
+			return field, err
+		}
+		field.Args = append(field.Args, arg)
+	}
+
+	if f.DefaultValue != nil {
+		p := &sdlParser{lex: &sdlLexer{src: *f.DefaultValue}}
+		if err := p.next(); err != nil {
+			return field, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return field, fmt.Errorf("invalid default value %q: %w", *f.DefaultValue, err)
+		}
+		field.DefaultValue = value
+	}
+
+	return field, nil
+}
+
+// SDL tokens
+const (
+	tokEOF = iota
+	tokName
+	tokPunct
+	tokString
+	tokNumber
+)
+
+type sdlToken struct {
+	kind  int
+	value string
+	line  int
+}
+
+// sdlLexer splits GraphQL source into tokens, skipping whitespace, commas and
+// comments
+type sdlLexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (l *sdlLexer) next() (sdlToken, error) {
+	if l.line == 0 {
+		l.line = 1
+	}
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return l.token()
+		}
+	}
+	return sdlToken{kind: tokEOF, line: l.line}, nil
+}
+
+// token reads the token starting at the current position
+func (l *sdlLexer) token() (sdlToken, error) {
+	start := l.pos
+	c := l.src[l.pos]
+
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return sdlToken{kind: tokPunct, value: "...", line: l.line}, nil
+	case strings.ContainsRune("!$&()/:=@[]{}|", rune(c)):
+		l.pos++
+		return sdlToken{kind: tokPunct, value: string(c), line: l.line}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return sdlToken{kind: tokName, value: l.src[start:l.pos], line: l.line}, nil
+	case c == '-' || isDigit(c):
+		l.pos++
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || strings.IndexByte(".eE+-", l.src[l.pos]) >= 0) {
+			l.pos++
+		}
+		return sdlToken{kind: tokNumber, value: l.src[start:l.pos], line: l.line}, nil
+	case strings.HasPrefix(l.src[l.pos:], `"""`):
+		return l.blockString()
+	case c == '"':
+		return l.quotedString()
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+		return sdlToken{}, fmt.Errorf("unexpected character %q", r)
+	}
+}
+
+// quotedString reads a single-line string with escapes
+func (l *sdlLexer) quotedString() (sdlToken, error) {
+	line := l.line
+	l.pos++ // opening quote
+
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return sdlToken{kind: tokString, value: sb.String(), line: line}, nil
+		case '\n':
+			return sdlToken{}, fmt.Errorf("unterminated string")
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				return sdlToken{}, fmt.Errorf("unterminated string")
+			}
+			esc := l.src[l.pos+1]
+			l.pos += 2
+			switch esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return sdlToken{}, fmt.Errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return sdlToken{}, fmt.Errorf("invalid unicode escape")
+				}
+				sb.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				sb.WriteByte(esc)
+			}
+		default:
+			sb.WriteByte(c)
+			l.pos++
+		}
+	}
+	return sdlToken{}, fmt.Errorf("unterminated string")
+}
+
+// blockString reads a """block string""", removing common indentation and
+// leading and trailing blank lines as the GraphQL spec requires
+func (l *sdlLexer) blockString() (sdlToken, error) {
+	line := l.line
+	l.pos += 3
+
+	// Find the closing quotes, skipping escaped \"""
+	end := -1
+	for i := l.pos; i+3 <= len(l.src); i++ {
+		if strings.HasPrefix(l.src[i:], `\"""`) {
+			i += 3
+			continue
+		}
+		if strings.HasPrefix(l.src[i:], `"""`) {
+			end = i - l.pos
+			break
+		}
+	}
+	if end < 0 {
+		return sdlToken{}, fmt.Errorf("unterminated block string")
+	}
+
+	raw := strings.ReplaceAll(l.src[l.pos:l.pos+end], `\"""`, `"""`)
+	l.pos += end + 3
+	l.line += strings.Count(raw, "\n")
+
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, ln := range lines[1:] {
+		trimmed := strings.TrimLeft(ln, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(ln) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return sdlToken{kind: tokString, value: strings.Join(lines, "\n"), line: line}, nil
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+// sdlParser is a recursive descent parser over sdlLexer tokens
+type sdlParser struct {
+	lex *sdlLexer
+	tok sdlToken
+}
+
+func (p *sdlParser) next() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// is reports whether the current token is the given punctuator
+func (p *sdlParser) is(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.value == punct
+}
+
+// isKeyword reports whether the current token is the given name
+func (p *sdlParser) isKeyword(name string) bool {
+	return p.tok.kind == tokName && p.tok.value == name
+}
+
+// skip consumes the current token if it is the given punctuator
+func (p *sdlParser) skip(punct string) (bool, error) {
+	if !p.is(punct) {
+		return false, nil
+	}
+	return true, p.next()
+}
+
+// expect consumes the given punctuator or fails
+func (p *sdlParser) expect(punct string) error {
+	if !p.is(punct) {
+		return fmt.Errorf("expected %q, got %q", punct, p.tok.value)
+	}
+	return p.next()
+}
+
+// name consumes a name token and returns it
+func (p *sdlParser) name() (string, error) {
+	if p.tok.kind != tokName {
+		return "", fmt.Errorf("expected a name, got %q", p.tok.value)
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+// description consumes an optional description string
+func (p *sdlParser) description() (string, error) {
+	if p.tok.kind != tokString {
+		return "", nil
+	}
+	desc := p.tok.value
+	return desc, p.next()
+}
+
+// parseDefinition parses one type system definition or extension
+func (p *sdlParser) parseDefinition(schema *GraphQLSchema) error {
+	desc, err := p.description()
+	if err != nil {
+		return err
+	}
+
+	extend := p.isKeyword("extend")
+	if extend {
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+
+	keyword, err := p.name()
+	if err != nil {
+		return err
+	}
+
+	switch keyword {
+	case "schema":
+		return p.parseSchemaDefinition(schema)
+	case "directive":
+		return p.parseDirectiveDefinition()
+	case "type", "interface", "input", "enum", "scalar", "union":
+	case "query", "mutation", "subscription", "fragment":
+		return fmt.Errorf("executable definition %q is not allowed in a schema", keyword)
+	default:
+		return fmt.Errorf("unexpected %q", keyword)
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+
+	typ := schema.Types[name]
+	if typ == nil {
+		typ = &GraphQLType{Name: name, Kind: graphQLKinds[keyword]}
+		schema.Types[name] = typ
+	}
+	if !extend && desc != "" {
+		typ.Description = desc
+	}
+
+	if keyword == "type" || keyword == "interface" {
+		if p.isKeyword("implements") {
+			if err := p.next(); err != nil {
+				return err
+			}
+			if _, err := p.skip("&"); err != nil {
+				return err
+			}
+			// Interfaces may also be separated by commas, which the lexer skips
+			for p.tok.kind == tokName && graphQLKinds[p.tok.value] == "" && p.tok.value != "extend" && p.tok.value != "schema" && p.tok.value != "directive" {
+				if err := p.next(); err != nil {
+					return err
+				}
+				if _, err := p.skip("&"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := p.skipDirectives(); err != nil {
+		return err
+	}
+
+	switch keyword {
+	case "type", "interface", "input":
+		if !p.is("{") {
+			return nil
+		}
+		fields, err := p.parseFields(keyword != "input")
+		if err != nil {
+			return err
+		}
+		typ.Fields = append(typ.Fields, fields...)
+	case "enum":
+		if ok, err := p.skip("{"); err != nil || !ok {
+			return err
+		}
+		for !p.is("}") {
+			if _, err := p.description(); err != nil {
+				return err
+			}
+			value, err := p.name()
+			if err != nil {
+				return err
+			}
+			typ.EnumValues = append(typ.EnumValues, value)
+			if err := p.skipDirectives(); err != nil {
+				return err
+			}
+		}
+		return p.next()
+	case "union":
+		if ok, err := p.skip("="); err != nil || !ok {
+			return err
+		}
+		if _, err := p.skip("|"); err != nil {
+			return err
+		}
+		for p.tok.kind == tokName {
+			if err := p.next(); err != nil {
+				return err
+			}
+			if ok, err := p.skip("|"); err != nil || !ok {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// graphQLKinds maps definition keywords to introspection type kinds
+var graphQLKinds = map[string]string{
+	"type":      "OBJECT",
+	"interface": "INTERFACE",
+	"input":     "INPUT_OBJECT",
+	"enum":      "ENUM",
+	"scalar":    "SCALAR",
+	"union":     "UNION",
+}
+
+// parseSchemaDefinition parses the operation types of a schema definition
+func (p *sdlParser) parseSchemaDefinition(schema *GraphQLSchema) error {
+	if err := p.skipDirectives(); err != nil {
+		return err
+	}
+	if ok, err := p.skip("{"); err != nil || !ok {
+		return err
+	}
+
+	for !p.is("}") {
+		operation, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		typeName, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		switch operation {
+		case "query":
+			schema.QueryType = typeName
+		case "mutation":
+			schema.MutationType = typeName
+		}
+	}
+	return p.next()
+}
+
+// parseDirectiveDefinition skips a directive definition
+func (p *sdlParser) parseDirectiveDefinition() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.is("(") {
+		if _, err := p.parseArguments(); err != nil {
+			return err
+		}
+	}
+	if p.isKeyword("repeatable") {
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+	if !p.isKeyword("on") {
+		return fmt.Errorf("expected \"on\", got %q", p.tok.value)
+	}
+	if err := p.next(); err != nil {
+		return err
+	}
+	if _, err := p.skip("|"); err != nil {
+		return err
+	}
+	for p.tok.kind == tokName {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if ok, err := p.skip("|"); err != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseFields parses a braced list of fields or input values
+func (p *sdlParser) parseFields(withArgs bool) ([]GraphQLField, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []GraphQLField
+	for !p.is("}") {
+		if p.tok.kind == tokEOF {
+			return nil, fmt.Errorf("unexpected end of schema")
+		}
+
+		field, err := p.parseInputValue(withArgs)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, p.next()
+}
+
+// parseArguments parses a parenthesised argument definition list
+func (p *sdlParser) parseArguments() ([]GraphQLField, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var args []GraphQLField
+	for !p.is(")") {
+		if p.tok.kind == tokEOF {
+			return nil, fmt.Errorf("unexpected end of schema")
+		}
+
+		arg, err := p.parseInputValue(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, p.next()
+}
+
+// parseInputValue parses a field (with arguments when allowed), argument or
+// input field: description? name args? : Type (= default)? directives
+func (p *sdlParser) parseInputValue(withArgs bool) (GraphQLField, error) {
+	var field GraphQLField
+	var err error
+
+	if field.Description, err = p.description(); err != nil {
+		return field, err
+	}
+	if field.Name, err = p.name(); err != nil {
+		return field, err
+	}
+	if withArgs && p.is("(") {
+		if field.Args, err = p.parseArguments(); err != nil {
+			return field, err
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return field, err
+	}
+	if field.Type, err = p.parseType(); err != nil {
+		return field, err
+	}
+	if ok, err := p.skip("="); err != nil {
+		return field, err
+	} else if ok {
+		if field.DefaultValue, err = p.parseValue(); err != nil {
+			return field, err
+		}
+	}
+	return field, p.skipDirectives()
+}
+
+// parseType parses a type reference such as [String!]!
+func (p *sdlParser) parseType() (*GraphQLTypeRef, error) {
+	var ref *GraphQLTypeRef
+
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		ref = &GraphQLTypeRef{Kind: "LIST", OfType: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		ref = &GraphQLTypeRef{Name: name}
+	}
+
+	if ok, err := p.skip("!"); err != nil {
+		return nil, err
+	} else if ok {
+		ref = &GraphQLTypeRef{Kind: "NON_NULL", OfType: ref}
+	}
+	return ref, nil
+}
+
+// skipDirectives skips any directives applied at the current position
+func (p *sdlParser) skipDirectives() error {
+	for p.is("@") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if ok, err := p.skip("("); err != nil {
+			return err
+		} else if ok {
+			for !p.is(")") {
+				if _, err := p.name(); err != nil {
+					return err
+				}
+				if err := p.expect(":"); err != nil {
+					return err
+				}
+				if _, err := p.parseValue(); err != nil {
+					return err
+				}
+			}
+			if err := p.next(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseValue parses a literal value into its Go equivalent: strings, enum
+// values and variables become strings, numbers float64, lists and objects
+// []interface{} and map[string]interface{}
+func (p *sdlParser) parseValue() (interface{}, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokString:
+		return tok.value, p.next()
+	case tok.kind == tokNumber:
+		n, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.value)
+		}
+		return n, p.next()
+	case tok.kind == tokName:
+		var value interface{} = tok.value
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		}
+		return value, p.next()
+	case p.is("$"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return "$" + name, err
+	case p.is("["):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		list := []interface{}{}
+		for !p.is("]") {
+			if p.tok.kind == tokEOF {
+				return nil, fmt.Errorf("unterminated list value")
+			}
+			item, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, p.next()
+	case p.is("{"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		obj := map[string]interface{}{}
+		for !p.is("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if obj[name], err = p.parseValue(); err != nil {
+				return nil, err
+			}
+		}
+		return obj, p.next()
+	default:
+		return nil, fmt.Errorf("unexpected %q in value", tok.value)
+	}
+}
@@ -0,0 +1,216 @@
+package spec
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testGraphQLSDL = `
+# Issue tracker
+schema { query: Query mutation: Mutation }
+
+enum State { OPEN CLOSED }
+
+type User { id: ID! login: String! }
+
+type Issue implements Node @key(fields: "id") {
+  id: ID!
+  title: String!
+  state: State!
+  author: User
+  comments(first: Int!): [String]
+}
+
+input NewIssue {
+  """
+  Issue title
+  """
+  title: String!
+  labels: [String!] = []
+}
+
+type Query {
+  "Fetch an issue"
+  issue(id: ID!): Issue
+  count(state: State = OPEN): Int!
+}
+
+type Mutation {
+  createIssue("Issue to create" input: NewIssue!): Issue!
+}
+
+extend type Query {
+  search(terms: [String!]!, states: [State!]): [Issue!]!
+}
+`
+
+func TestParseGraphQLSDL(t *testing.T) {
+	schema, err := ParseGraphQLSDL([]byte(testGraphQLSDL))
+	if err != nil {
+		t.Fatalf("ParseGraphQLSDL failed: %v", err)
+	}
+
+	if schema.QueryType != "Query" || schema.MutationType != "Mutation" {
+		t.Errorf("Unexpected root types %q and %q", schema.QueryType, schema.MutationType)
+	}
+
+	query := schema.Types["Query"]
+	if query == nil || len(query.Fields) != 3 {
+		t.Fatalf("Expected 3 query fields including the extension, got %+v", query)
+	}
+
+	if got := schema.Types["State"].EnumValues; !reflect.DeepEqual(got, []string{"OPEN", "CLOSED"}) {
+		t.Errorf("Unexpected enum values %v", got)
+	}
+
+	input := schema.Types["NewIssue"]
+	if input == nil || input.Kind != "INPUT_OBJECT" {
+		t.Fatalf("Expected NewIssue input object, got %+v", input)
+	}
+	if input.Fields[0].Description != "Issue title" {
+		t.Errorf("Expected block string description, got %q", input.Fields[0].Description)
+	}
+	if got := input.Fields[1].Type.String(); got != "[String!]" {
+		t.Errorf("Expected type [String!], got %q", got)
+	}
+
+	if _, err := ParseGraphQLSDL([]byte("type Query {\n  broken(: Int): Int\n}")); err == nil {
+		t.Error("Expected error for invalid SDL")
+	}
+}
+
+func TestParseGraphQLIntrospection(t *testing.T) {
+	data := []byte(`{"data": {"__schema": {
+		"queryType": {"name": "Query"},
+		"mutationType": null,
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [
+				{"name": "user", "description": "Look up a user", "args": [
+					{"name": "id", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID"}}, "defaultValue": null}
+				], "type": {"kind": "OBJECT", "name": "User"}}
+			]},
+			{"kind": "OBJECT", "name": "User", "fields": [
+				{"name": "id", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}},
+				{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+			]}
+		]
+	}}}`)
+
+	if !IsGraphQLSpec(data) {
+		t.Error("Expected introspection result to be detected as GraphQL")
+	}
+
+	schema, err := ParseGraphQLSpecFromBytes(data)
+	if err != nil {
+		t.Fatalf("ParseGraphQLSpecFromBytes failed: %v", err)
+	}
+
+	tools := schema.ToToolDefinitions()
+	if len(tools) != 1 || tools[0].Name != "user" {
+		t.Fatalf("Expected a single user tool, got %+v", tools)
+	}
+	if tools[0].GraphQL.Selection != "id name" {
+		t.Errorf("Unexpected selection %q", tools[0].GraphQL.Selection)
+	}
+}
+
+func TestGraphQLToToolDefinitions(t *testing.T) {
+	schema, err := ParseGraphQLSDL([]byte(testGraphQLSDL))
+	if err != nil {
+		t.Fatalf("ParseGraphQLSDL failed: %v", err)
+	}
+
+	tools := make(map[string]ToolDefinition)
+	for _, tool := range schema.ToToolDefinitions() {
+		tools[tool.Name] = tool
+	}
+	if len(tools) != 4 {
+		t.Fatalf("Expected 4 tools, got %d", len(tools))
+	}
+
+	issue := tools["issue"]
+	if issue.Description != "Fetch an issue" || issue.GraphQL.Type != "query" {
+		t.Errorf("Unexpected issue tool %+v", issue)
+	}
+	if issue.Annotations == nil || issue.Annotations.ReadOnlyHint == nil || !*issue.Annotations.ReadOnlyHint {
+		t.Errorf("Expected queries to be read-only, got %+v", issue.Annotations)
+	}
+	// comments is skipped because it has a required argument
+	if got := issue.GraphQL.Selection; got != "id title state author { id login }" {
+		t.Errorf("Unexpected selection %q", got)
+	}
+	if issue.GraphQL.FieldsParam != "fields" || findParam(issue.Parameters, "fields").Type != "[]string" {
+		t.Error("Expected a []string fields parameter")
+	}
+	if id := findParam(issue.Parameters, "id"); !id.Required || id.Type != "string" {
+		t.Errorf("Expected required string id, got %+v", id)
+	}
+
+	count := tools["count"]
+	if count.GraphQL.Selection != "" || count.GraphQL.FieldsParam != "" {
+		t.Error("Scalar results should not have a selection")
+	}
+	state := findParam(count.Parameters, "state")
+	if state.Required || state.Default != "OPEN" || !reflect.DeepEqual(state.Enum, []interface{}{"OPEN", "CLOSED"}) {
+		t.Errorf("Unexpected state parameter %+v", state)
+	}
+
+	search := tools["search"]
+	if got := findParam(search.Parameters, "terms"); !got.Required || got.Type != "[]string" {
+		t.Errorf("Unexpected terms parameter %+v", got)
+	}
+	if got := findParam(search.Parameters, "states"); got.Enum != nil {
+		t.Error("List of enums should not carry an enum constraint")
+	}
+
+	create := tools["createIssue"]
+	if create.GraphQL.Type != "mutation" || create.Annotations != nil {
+		t.Errorf("Expected a mutation without hints, got %+v", create)
+	}
+	if !reflect.DeepEqual(create.GraphQL.Variables, []GraphQLVariable{{Name: "input", Type: "NewIssue!"}}) {
+		t.Errorf("Unexpected variables %+v", create.GraphQL.Variables)
+	}
+	input := findParam(create.Parameters, "input")
+	if input.Type != "map[string]interface{}" || len(input.Properties) != 2 {
+		t.Fatalf("Expected input object with 2 properties, got %+v", input)
+	}
+	if title := findParam(input.Properties, "title"); !title.Required || title.Description != "Issue title" {
+		t.Errorf("Unexpected title property %+v", title)
+	}
+}
+
+func TestDetectGraphQLFormat(t *testing.T) {
+	if got := DetectSpecFormat([]byte(testGraphQLSDL)); got != FormatGraphQL {
+		t.Errorf("Expected %q, got %q", FormatGraphQL, got)
+	}
+
+	if IsGraphQLSpec([]byte("type User { id: ID! }")) {
+		t.Error("Schema without root types should not be detected as GraphQL")
+	}
+}
+
+func TestLintGraphQL(t *testing.T) {
+	report := Lint([]byte(`type Query {
+  user(id: ID!): Account
+  bad_name(x: Int): Int
+}`))
+
+	if report.Format != FormatGraphQL {
+		t.Errorf("Expected graphql format, got %q", report.Format)
+	}
+	if !hasDiagnostic(report, SeverityError, "unresolved-type", "/Query/user") {
+		t.Error("Expected unresolved-type diagnostic")
+	}
+	if !hasDiagnostic(report, SeverityWarning, "missing-description", "/Query/user/id") {
+		t.Error("Expected missing-description diagnostic")
+	}
+}
+
+func findParam(params []Parameter, name string) Parameter {
+	for _, p := range params {
+		if p.Name == name {
+			return p
+		}
+	}
+	return Parameter{}
+}
@@ -0,0 +1,110 @@
+package spec
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// validationKeywords are the JSON Schema keywords without a field of their
+// own that are carried from OpenAPI schemas into Parameter.Schema. Other
+// keywords of OpenAPI schemas, such as $ref or nullable, have no meaning
+// outside the document.
+var validationKeywords = []string{
+	"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+	"minLength", "maxLength", "pattern",
+	"minItems", "maxItems", "uniqueItems",
+	"minProperties", "maxProperties", "additionalProperties",
+	"const", "examples", "title", "deprecated",
+}
+
+// unmarshalKeywords decodes data into v, a pointer to a schema struct, and
+// returns the keywords v has no field for, or nil when there are none
+func unmarshalKeywords(data []byte, v interface{}) (map[string]interface{}, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	var extra map[string]interface{}
+	for key, value := range raw {
+		if known[key] {
+			continue
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(value, &decoded); err != nil {
+			return nil, err
+		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[key] = decoded
+	}
+	return extra, nil
+}
+
+// marshalKeywords encodes v, a schema struct, together with extra keywords.
+// Fields take precedence over extra keywords of the same name.
+func marshalKeywords(v interface{}, extra map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		if _, set := merged[key]; !set {
+			merged[key] = value
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// jsonFieldNames returns the JSON names of a struct's fields
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// pickKeywords returns the given keywords of a schema, or nil when it has none
+func pickKeywords(keywords map[string]interface{}, names []string) map[string]interface{} {
+	var picked map[string]interface{}
+	for _, name := range names {
+		if value, ok := keywords[name]; ok {
+			if picked == nil {
+				picked = make(map[string]interface{})
+			}
+			picked[name] = value
+		}
+	}
+	return picked
+}
+
+// copyKeywords returns a copy of a schema's keywords, or nil when it has none
+func copyKeywords(keywords map[string]interface{}) map[string]interface{} {
+	if len(keywords) == 0 {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(keywords))
+	for name, value := range keywords {
+		copied[name] = value
+	}
+	return copied
+}
@@ -0,0 +1,652 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Severity classifies a lint diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single problem found while linting a spec
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Path     string   `json:"path"` // JSON pointer to the offending node
+	Message  string   `json:"message"`
+}
+
+// LintReport is the result of linting a spec
+type LintReport struct {
+	Format      SpecFormat   `json:"format"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Counts returns the number of errors and warnings in the report
+func (r LintReport) Counts() (errors, warnings int) {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// HasErrors reports whether the report contains any errors
+func (r LintReport) HasErrors() bool {
+	errors, _ := r.Counts()
+	return errors > 0
+}
+
+// String renders the report for humans, one diagnostic per line
+func (r LintReport) String() string {
+	var sb strings.Builder
+	for _, d := range r.Diagnostics {
+		sb.WriteString(fmt.Sprintf("%-7s %s: %s [%s]\n", d.Severity, d.Path, d.Message, d.Code))
+	}
+	errors, warnings := r.Counts()
+	sb.WriteString(fmt.Sprintf("%d error(s), %d warning(s)\n", errors, warnings))
+	return sb.String()
+}
+
+// Lint checks an MCP, OpenAPI, OpenRPC, GraphQL or protobuf spec, or a list of
+// OpenAI functions or Anthropic tools, for problems that make
+// generated code wrong or unusable: missing descriptions, untyped properties,
+// required fields missing from properties, duplicate or non-identifier names,
+// unresolved $refs and types, and request bodies without a supported content
+// type. Diagnostics are sorted by path.
+func Lint(data []byte) LintReport {
+	report := LintReport{Format: DetectSpecFormat(data)}
+
+	l := &linter{}
+	switch report.Format {
+	case FormatGraphQL:
+		l.lintGraphQL(data)
+	case FormatProtobuf:
+		l.lintProtobuf(data)
+	case FormatOpenAI, FormatAnthropic:
+		l.lintFunctions(data)
+	default:
+		var doc map[string]interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			report.Diagnostics = []Diagnostic{{Severity: SeverityError, Code: "invalid-json", Path: "/", Message: err.Error()}}
+			return report
+		}
+
+		l.root = doc
+		switch report.Format {
+		case FormatMCP:
+			l.lintMCP()
+		case FormatOpenAPI:
+			l.lintOpenAPI()
+		case FormatOpenRPC:
+			l.lintOpenRPC()
+		default:
+			l.errorf("/", "unknown-format", "spec is not MCP (a tools list), OpenAPI (an openapi or swagger version), OpenRPC, OpenAI functions, Anthropic tools, GraphQL or protobuf")
+		}
+		l.lintRefs("", doc)
+	}
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		return l.diagnostics[i].Path < l.diagnostics[j].Path
+	})
+	report.Diagnostics = l.diagnostics
+	return report
+}
+
+// linter collects diagnostics while walking a decoded spec
+type linter struct {
+	root        map[string]interface{}
+	diagnostics []Diagnostic
+}
+
+func (l *linter) errorf(path, code, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{Severity: SeverityError, Code: code, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) warnf(path, code, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{Severity: SeverityWarning, Code: code, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// lintMCP checks the tools, resources and prompts of an MCP spec
+func (l *linter) lintMCP() {
+	if name, _ := l.root["name"].(string); name == "" {
+		l.errorf("/name", "missing-name", "spec has no name")
+	}
+
+	seen := make(map[string]string)
+	tools, _ := l.root["tools"].([]interface{})
+	for i, item := range tools {
+		path := fmt.Sprintf("/tools/%d", i)
+		tool, ok := item.(map[string]interface{})
+		if !ok {
+			l.errorf(path, "invalid-tool", "tool is not an object")
+			continue
+		}
+
+		name, _ := tool["name"].(string)
+		if name == "" {
+			l.errorf(path+"/name", "missing-name", "tool has no name")
+		} else {
+			if first, dup := seen[name]; dup {
+				l.errorf(path+"/name", "duplicate-name", "tool %q is already defined at %s", name, first)
+			} else {
+				seen[name] = path
+			}
+			l.checkIdentifier(path+"/name", "tool", name)
+		}
+
+		if desc, _ := tool["description"].(string); strings.TrimSpace(desc) == "" {
+			l.errorf(path+"/description", "missing-description", "tool %q has no description", name)
+		}
+
+		if schema, ok := tool["inputSchema"].(map[string]interface{}); ok {
+			l.lintObjectSchema(path+"/inputSchema", schema)
+		} else if _, hasParams := tool["parameters"]; hasParams {
+			l.errorf(path+"/parameters", "unsupported-field", "tool parameters must be declared in inputSchema")
+		}
+		if schema, ok := tool["outputSchema"].(map[string]interface{}); ok {
+			l.lintObjectSchema(path+"/outputSchema", schema)
+		}
+	}
+
+	resources, _ := l.root["resources"].([]interface{})
+	for i, item := range resources {
+		resource, _ := item.(map[string]interface{})
+		path := fmt.Sprintf("/resources/%d", i)
+		uri, _ := resource["uri"].(string)
+		template, _ := resource["uriTemplate"].(string)
+		if (uri == "") == (template == "") {
+			l.errorf(path, "invalid-resource", "resource must have exactly one of uri or uriTemplate")
+		}
+		if desc, _ := resource["description"].(string); desc == "" {
+			l.warnf(path+"/description", "missing-description", "resource %q has no description", resource["name"])
+		}
+	}
+
+	prompts, _ := l.root["prompts"].([]interface{})
+	for i, item := range prompts {
+		prompt, _ := item.(map[string]interface{})
+		path := fmt.Sprintf("/prompts/%d", i)
+		if name, _ := prompt["name"].(string); name == "" {
+			l.errorf(path+"/name", "missing-name", "prompt has no name")
+		}
+		if desc, _ := prompt["description"].(string); desc == "" {
+			l.warnf(path+"/description", "missing-description", "prompt %q has no description", prompt["name"])
+		}
+	}
+}
+
+// lintOpenAPI checks the operations of an OpenAPI spec
+func (l *linter) lintOpenAPI() {
+	if info, _ := l.root["info"].(map[string]interface{}); info == nil || info["title"] == nil {
+		l.warnf("/info/title", "missing-title", "spec has no info.title")
+	}
+
+	operationIDs := make(map[string]string)
+	paths, _ := l.root["paths"].(map[string]interface{})
+	for _, route := range sortedKeys(paths) {
+		item, _ := paths[route].(map[string]interface{})
+		for _, method := range []string{"get", "post", "put", "delete", "patch"} {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			path := "/paths/" + escapePointer(route) + "/" + method
+
+			if id, _ := op["operationId"].(string); id == "" {
+				l.warnf(path, "missing-operation-id", "%s %s has no operationId; a tool name will be derived from the path", strings.ToUpper(method), route)
+			} else {
+				if first, dup := operationIDs[id]; dup {
+					l.errorf(path+"/operationId", "duplicate-operation-id", "operationId %q is already used at %s", id, first)
+				} else {
+					operationIDs[id] = path
+				}
+				l.checkIdentifier(path+"/operationId", "operationId", id)
+			}
+
+			summary, _ := op["summary"].(string)
+			description, _ := op["description"].(string)
+			if strings.TrimSpace(summary+description) == "" {
+				l.warnf(path, "missing-description", "%s %s has no summary or description", strings.ToUpper(method), route)
+			}
+
+			params, _ := op["parameters"].([]interface{})
+			for i, p := range params {
+				l.lintOpenAPIParameter(fmt.Sprintf("%s/parameters/%d", path, i), p)
+			}
+
+			if body, ok := op["requestBody"].(map[string]interface{}); ok {
+				l.lintRequestBody(path+"/requestBody", body)
+			}
+		}
+	}
+}
+
+// lintGraphQL checks that a GraphQL schema parses and documents its root
+// fields. Paths name schema coordinates such as "/Query/issue/id".
+func (l *linter) lintGraphQL(data []byte) {
+	schema, err := ParseGraphQLSpecFromBytes(data)
+	if err != nil {
+		l.errorf("/", "invalid-schema", "%v", err)
+		return
+	}
+	if schema.QueryType == "" && schema.MutationType == "" {
+		l.errorf("/", "no-operations", "schema has no Query or Mutation type")
+		return
+	}
+
+	for _, rootName := range []string{schema.QueryType, schema.MutationType} {
+		root := schema.Types[rootName]
+		if rootName == "" {
+			continue
+		}
+		if root == nil {
+			l.errorf("/"+rootName, "unresolved-type", "root type %s is not defined", rootName)
+			continue
+		}
+
+		for _, field := range root.Fields {
+			path := "/" + rootName + "/" + field.Name
+			if field.Description == "" {
+				l.warnf(path, "missing-description", "field %s.%s has no description", rootName, field.Name)
+			}
+			l.checkIdentifier(path, "field", field.Name)
+			if schema.Types[field.Type.NamedType()] == nil && !graphQLBuiltinScalars[field.Type.NamedType()] {
+				l.errorf(path, "unresolved-type", "type %s is not defined", field.Type.NamedType())
+			}
+
+			for _, arg := range field.Args {
+				argPath := path + "/" + arg.Name
+				if arg.Description == "" {
+					l.warnf(argPath, "missing-description", "argument %s of %s.%s has no description", arg.Name, rootName, field.Name)
+				}
+				l.checkIdentifier(argPath, "argument", arg.Name)
+				if schema.Types[arg.Type.NamedType()] == nil && !graphQLBuiltinScalars[arg.Type.NamedType()] {
+					l.errorf(argPath, "unresolved-type", "type %s is not defined", arg.Type.NamedType())
+				}
+			}
+		}
+	}
+}
+
+// lintFunctions checks the declarations of OpenAI functions or Anthropic
+// tools. Descriptions are optional in both APIs but are all a model sees of a
+// generated tool, so their absence is a warning.
+func (l *linter) lintFunctions(data []byte) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		l.errorf("/", "invalid-json", "%v", err)
+		return
+	}
+
+	base := ""
+	entries, _ := doc.([]interface{})
+	if root, ok := doc.(map[string]interface{}); ok {
+		l.root = root
+		if functions, ok := root["functions"].([]interface{}); ok && len(functions) > 0 {
+			base, entries = "/functions", functions
+		} else {
+			base = "/tools"
+			entries, _ = root["tools"].([]interface{})
+		}
+	}
+
+	seen := make(map[string]string)
+	for i, item := range entries {
+		path := fmt.Sprintf("%s/%d", base, i)
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			l.errorf(path, "invalid-tool", "tool is not an object")
+			continue
+		}
+		if fn, ok := entry["function"].(map[string]interface{}); ok {
+			path, entry = path+"/function", fn
+		}
+
+		schemaKey := "parameters"
+		if _, ok := entry["input_schema"]; ok {
+			schemaKey = "input_schema"
+		}
+		schema, hasSchema := entry[schemaKey].(map[string]interface{})
+		if kind, _ := entry["type"].(string); !hasSchema && kind != "" && kind != "function" && kind != "custom" {
+			continue // built-in tool such as web search
+		}
+
+		name, _ := entry["name"].(string)
+		if name == "" {
+			l.errorf(path+"/name", "missing-name", "tool has no name")
+		} else {
+			if first, dup := seen[name]; dup {
+				l.errorf(path+"/name", "duplicate-name", "tool %q is already defined at %s", name, first)
+			} else {
+				seen[name] = path
+			}
+			l.checkIdentifier(path+"/name", "tool", name)
+		}
+
+		if desc, _ := entry["description"].(string); strings.TrimSpace(desc) == "" {
+			l.warnf(path+"/description", "missing-description", "tool %q has no description", name)
+		}
+		if hasSchema {
+			l.lintObjectSchema(path+"/"+schemaKey, schema)
+		}
+	}
+	l.lintRefs("", doc)
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		return l.diagnostics[i].Path < l.diagnostics[j].Path
+	})
+}
+
+// lintOpenRPC checks the methods of an OpenRPC document
+func (l *linter) lintOpenRPC() {
+	if info, _ := l.root["info"].(map[string]interface{}); info == nil || info["title"] == nil {
+		l.warnf("/info/title", "missing-title", "spec has no info.title")
+	}
+
+	seen := make(map[string]string)
+	methods, _ := l.root["methods"].([]interface{})
+	if len(methods) == 0 {
+		l.errorf("/methods", "no-operations", "document has no methods")
+	}
+	for i, item := range methods {
+		path := fmt.Sprintf("/methods/%d", i)
+		method, ok := l.resolve(item).(map[string]interface{})
+		if !ok {
+			l.errorf(path, "invalid-method", "method is not an object")
+			continue
+		}
+
+		name, _ := method["name"].(string)
+		if name == "" {
+			l.errorf(path+"/name", "missing-name", "method has no name")
+		} else {
+			if first, dup := seen[name]; dup {
+				l.errorf(path+"/name", "duplicate-name", "method %q is already defined at %s", name, first)
+			} else {
+				seen[name] = path
+			}
+			l.checkIdentifier(path+"/name", "method", name)
+		}
+
+		summary, _ := method["summary"].(string)
+		description, _ := method["description"].(string)
+		if strings.TrimSpace(summary+description) == "" {
+			l.warnf(path, "missing-description", "method %q has no summary or description", name)
+		}
+		if structure, _ := method["paramStructure"].(string); structure == "by-position" {
+			l.errorf(path+"/paramStructure", "unsupported-param-structure", "method %q takes positional params; generated tools pass params by name", name)
+		}
+
+		params, _ := method["params"].([]interface{})
+		for j, p := range params {
+			paramPath := fmt.Sprintf("%s/params/%d", path, j)
+			param, _ := l.resolve(p).(map[string]interface{})
+			if param == nil {
+				continue
+			}
+			paramName, _ := param["name"].(string)
+			if paramName == "" {
+				l.errorf(paramPath+"/name", "missing-name", "param has no name")
+				continue
+			}
+			l.checkIdentifier(paramPath+"/name", "param", paramName)
+			paramSummary, _ := param["summary"].(string)
+			paramDescription, _ := param["description"].(string)
+			if strings.TrimSpace(paramSummary+paramDescription) == "" {
+				l.warnf(paramPath, "missing-description", "param %q of %q has no description", paramName, name)
+			}
+			if schema, ok := param["schema"].(map[string]interface{}); ok {
+				if !hasType(schema) {
+					l.warnf(paramPath+"/schema", "untyped-property", "param %q has no type and will be generated as interface{}", paramName)
+				}
+			}
+		}
+	}
+}
+
+// graphQLBuiltinScalars are the scalar types every GraphQL schema provides
+var graphQLBuiltinScalars = map[string]bool{
+	"ID": true, "String": true, "Int": true, "Float": true, "Boolean": true,
+}
+
+// lintProtobuf checks that a .proto file parses, or that a FileDescriptorSet
+// resolves, and that its RPCs are documented and callable as tools. Imports
+// of a .proto file are not followed, so unresolved types are reported at
+// generation time instead.
+func (l *linter) lintProtobuf(data []byte) {
+	var files []*descriptorpb.FileDescriptorProto
+	if set, err := unmarshalDescriptorSet(data); err == nil && !protoSourcePattern.Match(data) {
+		if _, err := protobufSpecFromSet(set); err != nil {
+			l.errorf("/", "invalid-schema", "%v", err)
+			return
+		}
+		files = set.File
+	} else {
+		file, err := parseProtoSource("spec.proto", data)
+		if err != nil {
+			l.errorf("/", "invalid-schema", "%v", err)
+			return
+		}
+		files = append(files, file)
+	}
+
+	methods := 0
+	for _, file := range files {
+		if strings.HasPrefix(file.GetPackage(), "google.") {
+			continue
+		}
+
+		// Sets built without --include_source_info carry no comments to check
+		documented := make(map[string]bool)
+		hasComments := file.SourceCodeInfo != nil
+		for _, loc := range file.GetSourceCodeInfo().GetLocation() {
+			if strings.TrimSpace(loc.GetLeadingComments()+loc.GetTrailingComments()) != "" {
+				documented[fmt.Sprint(loc.Path)] = true
+			}
+		}
+
+		for si, svc := range file.Service {
+			for mi, method := range svc.Method {
+				methods++
+				path := "/" + svc.GetName() + "/" + method.GetName()
+				if method.GetClientStreaming() {
+					l.warnf(path, "unsupported-streaming", "client-streaming RPC %s.%s is skipped", svc.GetName(), method.GetName())
+					continue
+				}
+				if hasComments && !documented[fmt.Sprint([]int32{fileServicePath, int32(si), serviceMethodPath, int32(mi)})] {
+					l.warnf(path, "missing-description", "RPC %s.%s has no comment", svc.GetName(), method.GetName())
+				}
+			}
+		}
+	}
+
+	if methods == 0 {
+		l.errorf("/", "no-operations", "no services with RPCs found")
+	}
+}
+
+// lintOpenAPIParameter checks a single operation parameter
+func (l *linter) lintOpenAPIParameter(path string, p interface{}) {
+	param, _ := l.resolve(p).(map[string]interface{})
+	if param == nil {
+		return
+	}
+
+	name, _ := param["name"].(string)
+	if name == "" {
+		l.errorf(path+"/name", "missing-name", "parameter has no name")
+	}
+	if in, _ := param["in"].(string); in == "path" && param["required"] != true {
+		l.errorf(path+"/required", "path-param-not-required", "path parameter %q must be required", name)
+	}
+	if desc, _ := param["description"].(string); desc == "" {
+		l.warnf(path+"/description", "missing-description", "parameter %q has no description", name)
+	}
+
+	schema, _ := l.resolve(param["schema"]).(map[string]interface{})
+	if schema == nil || !hasType(schema) {
+		l.warnf(path+"/schema", "untyped-property", "parameter %q has no type and will be generated as interface{}", name)
+	}
+}
+
+// lintRequestBody checks the content types and schema of a request body
+func (l *linter) lintRequestBody(path string, b interface{}) {
+	body, _ := l.resolve(b).(map[string]interface{})
+	content, _ := body["content"].(map[string]interface{})
+
+	jsonFound := false
+	for _, mediaType := range sortedKeys(content) {
+		mediaPath := path + "/content/" + escapePointer(mediaType)
+		if mediaType != "application/json" {
+			l.warnf(mediaPath, "unsupported-content-type", "content type %s is not supported; only application/json bodies become parameters", mediaType)
+			continue
+		}
+		jsonFound = true
+
+		media, _ := content[mediaType].(map[string]interface{})
+		if schema, ok := media["schema"].(map[string]interface{}); ok {
+			if _, isRef := schema["$ref"]; isRef {
+				l.warnf(mediaPath+"/schema", "ref-not-expanded", "$ref request body schemas are not expanded; inline the schema so its properties become parameters")
+			} else {
+				l.lintObjectSchema(mediaPath+"/schema", schema)
+			}
+		}
+	}
+
+	if len(content) > 0 && !jsonFound {
+		l.errorf(path+"/content", "unsupported-content-type", "request body has no application/json content, so its fields cannot be passed to the tool")
+	}
+}
+
+// lintObjectSchema checks the properties and required list of an object schema
+func (l *linter) lintObjectSchema(path string, schema map[string]interface{}) {
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	for _, name := range sortedKeys(properties) {
+		propPath := path + "/properties/" + escapePointer(name)
+		prop, _ := properties[name].(map[string]interface{})
+		if prop == nil {
+			l.errorf(propPath, "invalid-property", "property %q is not an object", name)
+			continue
+		}
+		if _, isRef := prop["$ref"]; !isRef && !hasType(prop) {
+			l.warnf(propPath, "untyped-property", "property %q has no type and will be generated as interface{}", name)
+		}
+		if desc, _ := prop["description"].(string); desc == "" {
+			l.warnf(propPath+"/description", "missing-description", "property %q has no description", name)
+		}
+		l.checkIdentifier(propPath, "property", name)
+	}
+
+	required, _ := schema["required"].([]interface{})
+	for i, r := range required {
+		name, _ := r.(string)
+		if _, ok := properties[name]; !ok {
+			l.errorf(fmt.Sprintf("%s/required/%d", path, i), "required-not-in-properties", "required field %q is not declared in properties", name)
+		}
+	}
+}
+
+// lintRefs reports every $ref that does not resolve within the document
+func (l *linter) lintRefs(path string, node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			switch {
+			case !strings.HasPrefix(ref, "#"):
+				l.errorf(path+"/$ref", "unresolved-ref", "external reference %q is not supported", ref)
+			case l.lookup(ref) == nil:
+				l.errorf(path+"/$ref", "unresolved-ref", "reference %q does not resolve", ref)
+			}
+		}
+		for _, key := range sortedKeys(v) {
+			l.lintRefs(path+"/"+escapePointer(key), v[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			l.lintRefs(path+"/"+strconv.Itoa(i), item)
+		}
+	}
+}
+
+// resolve follows a local $ref, returning the node itself if it has none
+func (l *linter) resolve(node interface{}) interface{} {
+	if m, ok := node.(map[string]interface{}); ok {
+		if ref, ok := m["$ref"].(string); ok {
+			return l.lookup(ref)
+		}
+	}
+	return node
+}
+
+// lookup resolves a local JSON pointer reference such as "#/components/schemas/User"
+func (l *linter) lookup(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+
+	var node interface{} = l.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		switch v := node.(type) {
+		case map[string]interface{}:
+			node = v[part]
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			node = v[i]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// checkIdentifier warns about names that generated code has to rename
+func (l *linter) checkIdentifier(path, kind, name string) {
+	if !token.IsIdentifier(name) {
+		l.warnf(path, "invalid-identifier", "%s name %q is not a valid Go identifier and will be renamed in generated code", kind, name)
+	}
+}
+
+// hasType reports whether a schema declares a type or a composition of types
+func hasType(schema map[string]interface{}) bool {
+	for _, key := range []string{"type", "$ref", "enum", "oneOf", "anyOf", "allOf", "const"} {
+		if _, ok := schema[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// escapePointer escapes a key for use in a JSON pointer
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,188 @@
+package spec
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func hasDiagnostic(report LintReport, severity Severity, code, path string) bool {
+	for _, d := range report.Diagnostics {
+		if d.Severity == severity && d.Code == code && d.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLintMCP(t *testing.T) {
+	data := []byte(`{
+		"name": "files",
+		"tools": [
+			{
+				"name": "read-file",
+				"description": "Read a file",
+				"inputSchema": {
+					"type": "object",
+					"properties": {
+						"path": {"type": "string", "description": "File path"},
+						"mode": {"description": "Open mode"},
+						"opts": {"$ref": "#/definitions/Options"}
+					},
+					"required": ["path", "encoding"]
+				}
+			},
+			{"name": "read-file", "description": ""}
+		]
+	}`)
+
+	report := Lint(data)
+	if report.Format != FormatMCP {
+		t.Fatalf("Expected MCP format, got %s", report.Format)
+	}
+
+	checks := []struct {
+		severity Severity
+		code     string
+		path     string
+	}{
+		{SeverityWarning, "invalid-identifier", "/tools/0/name"},
+		{SeverityWarning, "untyped-property", "/tools/0/inputSchema/properties/mode"},
+		{SeverityWarning, "missing-description", "/tools/0/inputSchema/properties/opts/description"},
+		{SeverityError, "required-not-in-properties", "/tools/0/inputSchema/required/1"},
+		{SeverityError, "unresolved-ref", "/tools/0/inputSchema/properties/opts/$ref"},
+		{SeverityError, "duplicate-name", "/tools/1/name"},
+		{SeverityError, "missing-description", "/tools/1/description"},
+	}
+	for _, c := range checks {
+		if !hasDiagnostic(report, c.severity, c.code, c.path) {
+			t.Errorf("Expected %s %s at %s, got:\n%s", c.severity, c.code, c.path, report)
+		}
+	}
+
+	if hasDiagnostic(report, SeverityWarning, "untyped-property", "/tools/0/inputSchema/properties/opts") {
+		t.Error("$ref properties should not be reported as untyped")
+	}
+	if !report.HasErrors() {
+		t.Error("Expected report to have errors")
+	}
+}
+
+func TestLintOpenAPI(t *testing.T) {
+	data := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Users", "version": "1.0.0"},
+		"paths": {
+			"/users/{id}": {
+				"get": {
+					"operationId": "getUser",
+					"summary": "Get a user",
+					"parameters": [
+						{"name": "id", "in": "path", "description": "User ID", "schema": {"type": "string"}},
+						{"$ref": "#/components/parameters/Verbose"}
+					]
+				},
+				"put": {
+					"operationId": "getUser",
+					"summary": "Replace a user",
+					"requestBody": {"content": {"application/xml": {"schema": {"type": "object"}}}}
+				}
+			},
+			"/users": {
+				"post": {
+					"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}
+				}
+			}
+		},
+		"components": {
+			"parameters": {
+				"Verbose": {"name": "verbose", "in": "query", "description": "Verbose output"}
+			},
+			"schemas": {"User": {"type": "object"}}
+		}
+	}`)
+
+	report := Lint(data)
+	if report.Format != FormatOpenAPI {
+		t.Fatalf("Expected OpenAPI format, got %s", report.Format)
+	}
+
+	checks := []struct {
+		severity Severity
+		code     string
+		path     string
+	}{
+		{SeverityError, "path-param-not-required", "/paths/~1users~1{id}/get/parameters/0/required"},
+		{SeverityWarning, "untyped-property", "/paths/~1users~1{id}/get/parameters/1/schema"},
+		{SeverityError, "duplicate-operation-id", "/paths/~1users~1{id}/put/operationId"},
+		{SeverityWarning, "unsupported-content-type", "/paths/~1users~1{id}/put/requestBody/content/application~1xml"},
+		{SeverityError, "unsupported-content-type", "/paths/~1users~1{id}/put/requestBody/content"},
+		{SeverityWarning, "missing-operation-id", "/paths/~1users/post"},
+		{SeverityWarning, "missing-description", "/paths/~1users/post"},
+		{SeverityWarning, "ref-not-expanded", "/paths/~1users/post/requestBody/content/application~1json/schema"},
+	}
+	for _, c := range checks {
+		if !hasDiagnostic(report, c.severity, c.code, c.path) {
+			t.Errorf("Expected %s %s at %s, got:\n%s", c.severity, c.code, c.path, report)
+		}
+	}
+
+	for _, d := range report.Diagnostics {
+		if d.Code == "unresolved-ref" {
+			t.Errorf("Expected local refs to resolve, got %s", d.Message)
+		}
+	}
+}
+
+func TestLintCleanSpec(t *testing.T) {
+	data := []byte(`{
+		"name": "mail",
+		"tools": [{
+			"name": "sendEmail",
+			"description": "Send an email",
+			"inputSchema": {
+				"type": "object",
+				"properties": {"to": {"type": "string", "description": "Recipient"}},
+				"required": ["to"]
+			}
+		}]
+	}`)
+
+	report := Lint(data)
+	if len(report.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got:\n%s", report)
+	}
+	if !strings.Contains(report.String(), "0 error(s), 0 warning(s)") {
+		t.Errorf("Expected summary line, got %q", report.String())
+	}
+}
+
+func TestLintInvalidJSON(t *testing.T) {
+	report := Lint([]byte(`{"tools": [`))
+	if !report.HasErrors() || report.Diagnostics[0].Code != "invalid-json" {
+		t.Errorf("Expected invalid-json error, got %+v", report.Diagnostics)
+	}
+}
+
+func TestLintSortsDiagnosticsOfEveryFormat(t *testing.T) {
+	specs := map[SpecFormat]string{
+		FormatGraphQL:  "type Query {\n  zeta(id: ID!): Account\n  alpha(x: Int): Int\n}",
+		FormatProtobuf: testProtoSource,
+		FormatOpenAI: `{"tools": [
+			{"type": "function", "function": {"name": "b", "parameters": {"type": "object", "properties": {"x": {"type": "string"}}}}},
+			{"type": "function", "function": {"name": "b", "parameters": {"type": "object"}}}
+		]}`,
+	}
+	for format, data := range specs {
+		report := Lint([]byte(data))
+		if report.Format != format || len(report.Diagnostics) < 2 {
+			t.Errorf("Expected several %s diagnostics, got %q:\n%s", format, report.Format, report)
+			continue
+		}
+		if !sort.SliceIsSorted(report.Diagnostics, func(i, j int) bool {
+			return report.Diagnostics[i].Path < report.Diagnostics[j].Path
+		}) {
+			t.Errorf("Expected %s diagnostics sorted by path, got:\n%s", format, report)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 )
 
 // MCPSpec represents an MCP (Model Context Protocol) specification
@@ -19,15 +20,19 @@ type MCPSpec struct {
 
 // MCPTool represents a tool in the MCP spec
 type MCPTool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema MCPSchema              `json:"inputSchema"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  MCPSchema              `json:"inputSchema"`
+	OutputSchema *MCPSchema             `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations       `json:"annotations,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
 
-// MCPResource represents a resource in the MCP spec
+// MCPResource represents a resource in the MCP spec. Templated resources set
+// URITemplate (RFC 6570 level 1, e.g. "users://{id}/profile") instead of URI.
 type MCPResource struct {
-	URI         string                 `json:"uri"`
+	URI         string                 `json:"uri,omitempty"`
+	URITemplate string                 `json:"uriTemplate,omitempty"`
 	Name        string                 `json:"name"`
 	Description string                 `json:"description,omitempty"`
 	MimeType    string                 `json:"mimeType,omitempty"`
@@ -59,12 +64,38 @@ type MCPSchema struct {
 
 // MCPProperty represents a property in a JSON schema
 type MCPProperty struct {
-	Type        string                 `json:"type"`
+	Ref         string                 `json:"$ref,omitempty"`
+	Type        string                 `json:"type,omitempty"`
 	Description string                 `json:"description,omitempty"`
+	Format      string                 `json:"format,omitempty"`
 	Enum        []interface{}          `json:"enum,omitempty"`
 	Default     interface{}            `json:"default,omitempty"`
 	Items       *MCPSchema             `json:"items,omitempty"`
 	Properties  map[string]MCPProperty `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+
+	// Extra holds the other JSON Schema keywords of the property, such as
+	// "minimum" or "pattern", so they survive decoding and encoding
+	Extra map[string]interface{} `json:"-"`
+}
+
+// UnmarshalJSON decodes a property, keeping keywords without a field in Extra
+func (p *MCPProperty) UnmarshalJSON(data []byte) error {
+	type plain MCPProperty
+	var decoded plain
+	extra, err := unmarshalKeywords(data, &decoded)
+	if err != nil {
+		return err
+	}
+	*p = MCPProperty(decoded)
+	p.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes a property together with its Extra keywords
+func (p MCPProperty) MarshalJSON() ([]byte, error) {
+	type plain MCPProperty
+	return marshalKeywords(plain(p), p.Extra)
 }
 
 // ParseMCPSpec parses an MCP specification from a file
@@ -111,6 +142,21 @@ func (s *MCPSpec) Validate() error {
 		}
 	}
 
+	for i, resource := range s.Resources {
+		if resource.Name == "" {
+			return fmt.Errorf("resource at index %d must have a name", i)
+		}
+		if (resource.URI == "") == (resource.URITemplate == "") {
+			return fmt.Errorf("resource %s must have exactly one of uri or uriTemplate", resource.Name)
+		}
+	}
+
+	for i, prompt := range s.Prompts {
+		if prompt.Name == "" {
+			return fmt.Errorf("prompt at index %d must have a name", i)
+		}
+	}
+
 	return nil
 }
 
@@ -123,12 +169,36 @@ func (s *MCPSpec) ToToolDefinitions() []ToolDefinition {
 			Name:        mcpTool.Name,
 			Description: mcpTool.Description,
 			Parameters:  extractParameters(mcpTool.InputSchema),
+			Annotations: mcpTool.Annotations,
+		}
+		if mcpTool.OutputSchema != nil {
+			tools[i].Output = extractParameters(*mcpTool.OutputSchema)
 		}
 	}
 
 	return tools
 }
 
+// ToolDefinitionFromSchema builds a tool definition from a decoded JSON Schema
+// input schema, as returned by an MCP server's tools/list
+func ToolDefinitionFromSchema(name, description string, inputSchema map[string]interface{}) (ToolDefinition, error) {
+	data, err := json.Marshal(inputSchema)
+	if err != nil {
+		return ToolDefinition{}, fmt.Errorf("failed to encode input schema of %s: %w", name, err)
+	}
+
+	var schema MCPSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return ToolDefinition{}, fmt.Errorf("invalid input schema for %s: %w", name, err)
+	}
+
+	return ToolDefinition{
+		Name:        name,
+		Description: description,
+		Parameters:  extractParameters(schema),
+	}, nil
+}
+
 // extractParameters converts MCP schema properties to parameters
 func extractParameters(schema MCPSchema) []Parameter {
 	if schema.Properties == nil {
@@ -136,7 +206,8 @@ func extractParameters(schema MCPSchema) []Parameter {
 	}
 
 	params := make([]Parameter, 0, len(schema.Properties))
-	for name, prop := range schema.Properties {
+	for _, name := range sortedPropertyNames(schema.Properties) {
+		prop := schema.Properties[name]
 		required := false
 		for _, req := range schema.Required {
 			if req == name {
@@ -145,17 +216,45 @@ func extractParameters(schema MCPSchema) []Parameter {
 			}
 		}
 
-		params = append(params, Parameter{
-			Name:        name,
-			Type:        mapMCPTypeToGo(prop.Type),
-			Description: prop.Description,
-			Required:    required,
-		})
+		params = append(params, propertyParameter(name, prop, required))
 	}
 
+	sortParameters(params)
 	return params
 }
 
+// propertyParameter converts one JSON schema property to a parameter,
+// including the properties of objects and the item type of arrays
+func propertyParameter(name string, prop MCPProperty, required bool) Parameter {
+	param := Parameter{
+		Name:        name,
+		Type:        mapMCPTypeToGo(prop.Type),
+		Description: prop.Description,
+		Required:    required,
+		Default:     prop.Default,
+		Enum:        prop.Enum,
+		Format:      prop.Format,
+		Properties:  extractParameters(MCPSchema{Properties: prop.Properties, Required: prop.Required}),
+		Schema:      copyKeywords(prop.Extra),
+	}
+	if prop.Type == "array" && prop.Items != nil {
+		if itemType := mapMCPTypeToGo(prop.Items.Type); itemType != "interface{}" {
+			param.Type = "[]" + itemType
+		}
+	}
+	return param
+}
+
+// sortedPropertyNames returns the property names of a schema in alphabetical order
+func sortedPropertyNames(properties map[string]MCPProperty) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // mapMCPTypeToGo maps MCP/JSON Schema types to Go types
 func mapMCPTypeToGo(mcpType string) string {
 	switch mcpType {
@@ -1,8 +1,10 @@
 package spec
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -232,6 +234,34 @@ func TestMCPToToolDefinitions(t *testing.T) {
 	}
 }
 
+func TestMCPToToolDefinitionsCarriesSchemaHints(t *testing.T) {
+	spec := MCPSpec{
+		Name: "test-server",
+		Tools: []MCPTool{
+			{
+				Name:        "notify",
+				Description: "Send a notification",
+				InputSchema: MCPSchema{
+					Type: "object",
+					Properties: map[string]MCPProperty{
+						"channel": {Type: "string", Enum: []interface{}{"email", "sms"}, Default: "email"},
+					},
+				},
+			},
+		},
+	}
+
+	param := spec.ToToolDefinitions()[0].Parameters[0]
+
+	if len(param.Enum) != 2 || param.Enum[0] != "email" {
+		t.Errorf("Expected enum to be carried over, got %v", param.Enum)
+	}
+
+	if param.Default != "email" {
+		t.Errorf("Expected default 'email', got %v", param.Default)
+	}
+}
+
 func TestMapMCPTypeToGo(t *testing.T) {
 	tests := []struct {
 		mcpType  string
@@ -297,3 +327,99 @@ func TestParseMCPSpecFromFile(t *testing.T) {
 		t.Error("Expected error for non-existent file, got nil")
 	}
 }
+
+func TestMCPToToolDefinitionsOrdersParameters(t *testing.T) {
+	s := &MCPSpec{
+		Tools: []MCPTool{
+			{
+				Name: "search",
+				InputSchema: MCPSchema{
+					Type: "object",
+					Properties: map[string]MCPProperty{
+						"zeta":  {Type: "string"},
+						"query": {Type: "string"},
+						"alpha": {Type: "string"},
+						"limit": {Type: "integer"},
+					},
+					Required: []string{"query", "limit"},
+				},
+			},
+		},
+	}
+
+	want := []string{"limit", "query", "alpha", "zeta"}
+	for run := 0; run < 10; run++ {
+		params := s.ToToolDefinitions()[0].Parameters
+		for i, name := range want {
+			if params[i].Name != name {
+				t.Fatalf("Expected parameter %d to be %s, got %s", i, name, params[i].Name)
+			}
+		}
+	}
+}
+
+func TestToolDefinitionFromSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path":  map[string]interface{}{"type": "string", "description": "File path"},
+			"lines": map[string]interface{}{"type": "integer", "default": 10.0},
+		},
+		"required": []interface{}{"path"},
+	}
+
+	tool, err := ToolDefinitionFromSchema("read_file", "Read a file", schema)
+	if err != nil {
+		t.Fatalf("Failed to build tool definition: %v", err)
+	}
+
+	if len(tool.Parameters) != 2 {
+		t.Fatalf("Expected 2 parameters, got %d", len(tool.Parameters))
+	}
+	if tool.Parameters[0].Name != "path" || !tool.Parameters[0].Required {
+		t.Errorf("Expected required path parameter first, got %+v", tool.Parameters[0])
+	}
+	if tool.Parameters[1].Default != 10.0 {
+		t.Errorf("Expected lines default of 10, got %v", tool.Parameters[1].Default)
+	}
+}
+
+func TestMCPSpecRoundTripKeepsKeywords(t *testing.T) {
+	tool := `{
+		"name": "resize",
+		"description": "Resize an image",
+		"inputSchema": {
+			"type": "object",
+			"properties": {
+				"size": {
+					"type": "object",
+					"properties": {
+						"width": {"type": "integer", "minimum": 1, "maximum": 4096},
+						"unit": {"type": "string", "pattern": "^(px|pt)$", "x-label": "Unit"}
+					},
+					"required": ["width"]
+				},
+				"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true}
+			},
+			"required": ["size"]
+		},
+		"annotations": {"title": "Resize", "readOnlyHint": false, "idempotentHint": true}
+	}`
+	mcpSpec, err := ParseMCPSpecFromBytes([]byte(`{"name": "images", "tools": [` + tool + `]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	exported := ExportMCPSpec(ExportInfo{Name: "images"}, mcpSpec.ToToolDefinitions())
+	data, err := json.Marshal(exported.Tools[0])
+	if err != nil {
+		t.Fatalf("Failed to marshal tool: %v", err)
+	}
+
+	var want, got interface{}
+	json.Unmarshal([]byte(tool), &want)
+	json.Unmarshal(data, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the tool to survive a round trip\n got: %s\nwant: %s", data, tool)
+	}
+}
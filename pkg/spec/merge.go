@@ -0,0 +1,98 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ToolSet holds the tools parsed from one spec file, to be merged with
+// others under a namespace
+type ToolSet struct {
+	Namespace string
+	Source    string // spec file the tools were read from
+	Format    SpecFormat
+	Tools     []ToolDefinition
+}
+
+// NameConflict records a tool name defined more than once
+type NameConflict struct {
+	Name    string
+	Sources []string
+}
+
+// ConflictError is returned when merged tool sets define the same tool name
+type ConflictError struct {
+	Conflicts []NameConflict
+}
+
+func (e *ConflictError) Error() string {
+	lines := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		lines[i] = fmt.Sprintf("%s defined in %s", conflict.Name, strings.Join(conflict.Sources, ", "))
+	}
+	return fmt.Sprintf("%d tool name conflict(s): %s", len(e.Conflicts), strings.Join(lines, "; "))
+}
+
+// MergeToolSets combines tool sets into one list of namespaced tools, e.g.
+// listIssues from the "github" set becomes github.listIssues. Every name
+// defined more than once is reported in a single *ConflictError.
+func MergeToolSets(sets []ToolSet) ([]ToolDefinition, error) {
+	var merged []ToolDefinition
+	sources := make(map[string][]string)
+
+	for _, set := range sets {
+		if err := validateNamespace(set.Namespace); err != nil {
+			return nil, fmt.Errorf("%s: %w", set.Source, err)
+		}
+
+		for _, tool := range set.Tools {
+			tool.Name = set.Namespace + "." + tool.Name
+			tool.Namespace = set.Namespace
+
+			if _, seen := sources[tool.Name]; !seen {
+				merged = append(merged, tool)
+			}
+			sources[tool.Name] = append(sources[tool.Name], set.Source)
+		}
+	}
+
+	var conflicts []NameConflict
+	for name, from := range sources {
+		if len(from) > 1 {
+			conflicts = append(conflicts, NameConflict{Name: name, Sources: from})
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Slice(conflicts, func(i, j int) bool {
+			return conflicts[i].Name < conflicts[j].Name
+		})
+		return nil, &ConflictError{Conflicts: conflicts}
+	}
+
+	return merged, nil
+}
+
+// MergedFormat returns the format shared by all tool sets, or FormatMixed
+func MergedFormat(sets []ToolSet) SpecFormat {
+	if len(sets) == 0 {
+		return FormatUnknown
+	}
+	for _, set := range sets[1:] {
+		if set.Format != sets[0].Format {
+			return FormatMixed
+		}
+	}
+	return sets[0].Format
+}
+
+// validateNamespace checks that a namespace can prefix tool names unambiguously
+func validateNamespace(namespace string) error {
+	if namespace == "" {
+		return fmt.Errorf("namespace is required")
+	}
+	if strings.ContainsAny(namespace, ". \t\n") {
+		return fmt.Errorf("invalid namespace %q: must not contain dots or whitespace", namespace)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package spec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMergeToolSetsNamespacesTools(t *testing.T) {
+	sets := []ToolSet{
+		{Namespace: "github", Source: "github.json", Format: FormatMCP, Tools: []ToolDefinition{{Name: "listIssues"}}},
+		{Namespace: "sqlite", Source: "sqlite.json", Format: FormatOpenAPI, Tools: []ToolDefinition{{Name: "query"}}},
+	}
+
+	tools, err := MergeToolSets(sets)
+	if err != nil {
+		t.Fatalf("Failed to merge tool sets: %v", err)
+	}
+
+	if len(tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(tools))
+	}
+	if tools[0].Name != "github.listIssues" || tools[0].Namespace != "github" {
+		t.Errorf("Expected github.listIssues in namespace github, got %s in %s", tools[0].Name, tools[0].Namespace)
+	}
+	if tools[1].Name != "sqlite.query" {
+		t.Errorf("Expected sqlite.query, got %s", tools[1].Name)
+	}
+
+	if format := MergedFormat(sets); format != FormatMixed {
+		t.Errorf("Expected mixed format, got %s", format)
+	}
+}
+
+func TestMergeToolSetsReportsConflicts(t *testing.T) {
+	sets := []ToolSet{
+		{Namespace: "db", Source: "a.json", Tools: []ToolDefinition{{Name: "query"}, {Name: "insert"}}},
+		{Namespace: "db", Source: "b.json", Tools: []ToolDefinition{{Name: "query"}}},
+	}
+
+	_, err := MergeToolSets(sets)
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected a ConflictError, got %v", err)
+	}
+	if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Name != "db.query" {
+		t.Errorf("Expected a single conflict on db.query, got %v", conflictErr.Conflicts)
+	}
+	if !strings.Contains(err.Error(), "a.json, b.json") {
+		t.Errorf("Error should name both sources, got: %v", err)
+	}
+}
+
+func TestMergeToolSetsRejectsInvalidNamespace(t *testing.T) {
+	sets := []ToolSet{{Namespace: "git.hub", Source: "github.json", Tools: []ToolDefinition{{Name: "x"}}}}
+
+	if _, err := MergeToolSets(sets); err == nil {
+		t.Error("Expected an error for a namespace containing a dot")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -76,13 +77,37 @@ type OpenAPIResponse struct {
 
 // OpenAPISchema represents a schema object
 type OpenAPISchema struct {
-	Type       string                    `json:"type,omitempty"`
-	Format     string                    `json:"format,omitempty"`
-	Properties map[string]*OpenAPISchema `json:"properties,omitempty"`
-	Items      *OpenAPISchema            `json:"items,omitempty"`
-	Required   []string                  `json:"required,omitempty"`
-	Enum       []interface{}             `json:"enum,omitempty"`
-	Default    interface{}               `json:"default,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	Format      string                    `json:"format,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Properties  map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Items       *OpenAPISchema            `json:"items,omitempty"`
+	Required    []string                  `json:"required,omitempty"`
+	Enum        []interface{}             `json:"enum,omitempty"`
+	Default     interface{}               `json:"default,omitempty"`
+
+	// Extra holds the other keywords of the schema, such as "minimum" or
+	// "pattern", so they survive decoding and encoding
+	Extra map[string]interface{} `json:"-"`
+}
+
+// UnmarshalJSON decodes a schema, keeping keywords without a field in Extra
+func (s *OpenAPISchema) UnmarshalJSON(data []byte) error {
+	type plain OpenAPISchema
+	var decoded plain
+	extra, err := unmarshalKeywords(data, &decoded)
+	if err != nil {
+		return err
+	}
+	*s = OpenAPISchema(decoded)
+	s.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes a schema together with its Extra keywords
+func (s OpenAPISchema) MarshalJSON() ([]byte, error) {
+	type plain OpenAPISchema
+	return marshalKeywords(plain(s), s.Extra)
 }
 
 // ParseOpenAPISpec parses an OpenAPI specification from a file
@@ -114,7 +139,15 @@ func ParseOpenAPISpecFromBytes(data []byte) (*OpenAPISpec, error) {
 func (s *OpenAPISpec) ToToolDefinitions() []ToolDefinition {
 	var tools []ToolDefinition
 
-	for path, pathItem := range s.Paths {
+	// Sort paths so tools are generated in the same order on every run
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := s.Paths[path]
 		// Process each HTTP method
 		if pathItem.Get != nil {
 			tools = append(tools, s.operationToTool("GET", path, pathItem.Get))
@@ -158,12 +191,9 @@ func (s *OpenAPISpec) operationToTool(method, path string, op *OpenAPIOperation)
 
 	// Add path/query/header parameters
 	for _, param := range op.Parameters {
-		params = append(params, Parameter{
-			Name:        param.Name,
-			Type:        mapOpenAPITypeToGo(param.Schema),
-			Description: param.Description,
-			Required:    param.Required || param.In == "path",
-		})
+		p := openAPIParameter(param.Name, param.Schema, param.Required || param.In == "path")
+		p.Description = param.Description
+		params = append(params, p)
 	}
 
 	// Add request body parameters if present
@@ -171,13 +201,29 @@ func (s *OpenAPISpec) operationToTool(method, path string, op *OpenAPIOperation)
 		params = append(params, extractRequestBodyParams(op.RequestBody)...)
 	}
 
+	sortParameters(params)
+
 	return ToolDefinition{
 		Name:        name,
 		Description: description,
 		Parameters:  params,
+		Annotations: methodAnnotations(method),
 	}
 }
 
+// methodAnnotations derives tool hints from the semantics of an HTTP method
+func methodAnnotations(method string) *ToolAnnotations {
+	switch method {
+	case "GET", "HEAD":
+		return &ToolAnnotations{ReadOnlyHint: hint(true)}
+	case "PUT":
+		return &ToolAnnotations{IdempotentHint: hint(true)}
+	case "DELETE":
+		return &ToolAnnotations{DestructiveHint: hint(true), IdempotentHint: hint(true)}
+	}
+	return nil
+}
+
 // generateOperationName generates an operation name from method and path
 func generateOperationName(method, path string) string {
 	// Clean up path and convert to camelCase
@@ -202,31 +248,51 @@ func generateOperationName(method, path string) string {
 
 // extractRequestBodyParams extracts parameters from request body
 func extractRequestBodyParams(body *OpenAPIRequestBody) []Parameter {
-	var params []Parameter
+	jsonContent, ok := body.Content["application/json"]
+	if !ok || jsonContent.Schema == nil {
+		return nil
+	}
+	return openAPIParameter("", jsonContent.Schema, false).Properties
+}
+
+// openAPIParameter converts a schema to a parameter, including the
+// properties of objects and the validation keywords of the schema
+func openAPIParameter(name string, schema *OpenAPISchema, required bool) Parameter {
+	p := Parameter{
+		Name:     name,
+		Type:     mapOpenAPITypeToGo(schema),
+		Required: required,
+	}
+	if schema == nil {
+		return p
+	}
 
-	// Look for JSON content
-	if jsonContent, ok := body.Content["application/json"]; ok && jsonContent.Schema != nil {
-		if jsonContent.Schema.Properties != nil {
-			for propName, propSchema := range jsonContent.Schema.Properties {
-				required := false
-				for _, req := range jsonContent.Schema.Required {
-					if req == propName {
-						required = true
-						break
-					}
-				}
-
-				params = append(params, Parameter{
-					Name:        propName,
-					Type:        mapOpenAPITypeToGo(propSchema),
-					Description: "", // OpenAPI schemas don't have description at property level
-					Required:    required,
-				})
+	p.Description = schema.Description
+	p.Default = schema.Default
+	p.Enum = schema.Enum
+	p.Format = schema.Format
+	p.Schema = pickKeywords(schema.Extra, validationKeywords)
+	for _, propName := range sortedSchemaNames(schema.Properties) {
+		propRequired := false
+		for _, req := range schema.Required {
+			if req == propName {
+				propRequired = true
+				break
 			}
 		}
+		p.Properties = append(p.Properties, openAPIParameter(propName, schema.Properties[propName], propRequired))
 	}
+	return p
+}
 
-	return params
+// sortedSchemaNames returns the property names of a schema in alphabetical order
+func sortedSchemaNames(properties map[string]*OpenAPISchema) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // mapOpenAPITypeToGo maps OpenAPI types to Go types
@@ -3,6 +3,7 @@ package spec
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -428,3 +429,66 @@ func TestExtractRequestBodyParams(t *testing.T) {
 		t.Error("Expected 'age' to not be required")
 	}
 }
+
+func TestOpenAPIToToolDefinitionsKeepsSchemas(t *testing.T) {
+	spec, err := ParseOpenAPISpecFromBytes([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Items", "version": "1.0.0"},
+		"paths": {
+			"/items/{id}": {
+				"get": {
+					"operationId": "getItem",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "minimum": 1, "nullable": true}}]
+				},
+				"put": {
+					"operationId": "putItem",
+					"requestBody": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string", "pattern": "^[a-z]+$", "maxLength": 10},
+							"size": {"type": "object", "required": ["w"], "properties": {"w": {"type": "number", "maximum": 5}}}
+						}
+					}}}}
+				},
+				"delete": {"operationId": "deleteItem"}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	tools := make(map[string]ToolDefinition)
+	for _, tool := range spec.ToToolDefinitions() {
+		tools[tool.Name] = tool
+	}
+
+	get := tools["getItem"]
+	if id := get.Parameters[0]; !reflect.DeepEqual(id.Schema, map[string]interface{}{"minimum": float64(1)}) {
+		t.Errorf("Expected minimum without nullable, got %v", id.Schema)
+	}
+	if get.Annotations == nil || get.Annotations.ReadOnlyHint == nil || !*get.Annotations.ReadOnlyHint {
+		t.Errorf("Expected GET to be read-only, got %+v", get.Annotations)
+	}
+
+	put := tools["putItem"]
+	params := make(map[string]Parameter)
+	for _, param := range put.Parameters {
+		params[param.Name] = param
+	}
+	if name := params["name"]; name.Schema["pattern"] != "^[a-z]+$" || name.Schema["maxLength"] != float64(10) {
+		t.Errorf("Expected pattern and maxLength, got %v", name.Schema)
+	}
+	size := params["size"]
+	if len(size.Properties) != 1 || !size.Properties[0].Required || size.Properties[0].Schema["maximum"] != float64(5) {
+		t.Errorf("Expected the nested object's properties, got %+v", size.Properties)
+	}
+	if put.Annotations == nil || put.Annotations.IdempotentHint == nil || put.Annotations.ReadOnlyHint != nil {
+		t.Errorf("Expected PUT to be idempotent, got %+v", put.Annotations)
+	}
+
+	del := tools["deleteItem"].Annotations
+	if del == nil || del.DestructiveHint == nil || !*del.DestructiveHint {
+		t.Errorf("Expected DELETE to be destructive, got %+v", del)
+	}
+}
@@ -0,0 +1,185 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// OpenRPCSpec represents an OpenRPC document describing a JSON-RPC API
+type OpenRPCSpec struct {
+	OpenRPC    string             `json:"openrpc"`
+	Info       OpenRPCInfo        `json:"info"`
+	Servers    []OpenRPCServer    `json:"servers,omitempty"`
+	Methods    []OpenRPCMethod    `json:"methods"`
+	Components *OpenRPCComponents `json:"components,omitempty"`
+}
+
+// OpenRPCInfo describes the API
+type OpenRPCInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenRPCServer is an endpoint serving the API
+type OpenRPCServer struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+}
+
+// OpenRPCMethod is a JSON-RPC method
+type OpenRPCMethod struct {
+	Name           string                     `json:"name"`
+	Summary        string                     `json:"summary,omitempty"`
+	Description    string                     `json:"description,omitempty"`
+	Params         []OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor  `json:"result,omitempty"`
+	ParamStructure string                     `json:"paramStructure,omitempty"`
+	Deprecated     bool                       `json:"deprecated,omitempty"`
+}
+
+// OpenRPCContentDescriptor describes a method parameter or result. Ref is
+// set instead of the other fields when the descriptor is a
+// "#/components/contentDescriptors/..." reference.
+type OpenRPCContentDescriptor struct {
+	Ref         string      `json:"$ref,omitempty"`
+	Name        string      `json:"name,omitempty"`
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      MCPProperty `json:"schema"`
+}
+
+// OpenRPCComponents holds the reusable definitions of a document
+type OpenRPCComponents struct {
+	ContentDescriptors map[string]OpenRPCContentDescriptor `json:"contentDescriptors,omitempty"`
+	Schemas            map[string]MCPProperty              `json:"schemas,omitempty"`
+}
+
+// ParseOpenRPCSpec parses an OpenRPC document from a file
+func ParseOpenRPCSpec(filePath string) (*OpenRPCSpec, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenRPC spec file: %w", err)
+	}
+	return ParseOpenRPCSpecFromBytes(data)
+}
+
+// ParseOpenRPCSpecFromBytes parses an OpenRPC document from bytes
+func ParseOpenRPCSpecFromBytes(data []byte) (*OpenRPCSpec, error) {
+	var spec OpenRPCSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenRPC spec: %w", err)
+	}
+	if spec.OpenRPC == "" {
+		return nil, fmt.Errorf("failed to parse OpenRPC spec: missing openrpc version")
+	}
+	return &spec, nil
+}
+
+// ToToolDefinitions converts each method to a tool taking its params by name.
+// Parameter and schema references to the document's components are resolved;
+// a reference to a schema that is not a component leaves the parameter untyped.
+func (s *OpenRPCSpec) ToToolDefinitions() []ToolDefinition {
+	tools := make([]ToolDefinition, 0, len(s.Methods))
+	for _, method := range s.Methods {
+		description := method.Description
+		if description == "" {
+			description = method.Summary
+		}
+		if description == "" {
+			description = fmt.Sprintf("Calls the %s JSON-RPC method", method.Name)
+		}
+
+		params := make([]Parameter, 0, len(method.Params))
+		for _, cd := range method.Params {
+			cd = s.resolveContentDescriptor(cd)
+			if cd.Name == "" {
+				continue
+			}
+			param := propertyParameter(cd.Name, s.resolveSchema(cd.Schema), cd.Required)
+			if cd.Description != "" {
+				param.Description = cd.Description
+			} else if param.Description == "" {
+				param.Description = cd.Summary
+			}
+			params = append(params, param)
+		}
+		sortParameters(params)
+
+		tools = append(tools, ToolDefinition{
+			Name:        method.Name,
+			Description: description,
+			Parameters:  params,
+		})
+	}
+	return tools
+}
+
+// resolveContentDescriptor follows a content descriptor reference
+func (s *OpenRPCSpec) resolveContentDescriptor(cd OpenRPCContentDescriptor) OpenRPCContentDescriptor {
+	const prefix = "#/components/contentDescriptors/"
+	if cd.Ref == "" || s.Components == nil || !strings.HasPrefix(cd.Ref, prefix) {
+		return cd
+	}
+	return s.Components.ContentDescriptors[strings.TrimPrefix(cd.Ref, prefix)]
+}
+
+// resolveSchema follows a schema reference, keeping the description of the
+// referencing schema when it has one
+func (s *OpenRPCSpec) resolveSchema(schema MCPProperty) MCPProperty {
+	const prefix = "#/components/schemas/"
+	if schema.Ref == "" || s.Components == nil || !strings.HasPrefix(schema.Ref, prefix) {
+		return schema
+	}
+	resolved, ok := s.Components.Schemas[strings.TrimPrefix(schema.Ref, prefix)]
+	if !ok {
+		return schema
+	}
+	if schema.Description != "" {
+		resolved.Description = schema.Description
+	}
+	return resolved
+}
+
+// ExportOpenRPC describes tools as an OpenRPC 1.2 document with one
+// by-name JSON-RPC method per tool
+func ExportOpenRPC(info ExportInfo, tools []ToolDefinition) *OpenRPCSpec {
+	doc := &OpenRPCSpec{
+		OpenRPC: "1.2.6",
+		Info: OpenRPCInfo{
+			Title:       info.Name,
+			Description: info.Description,
+			Version:     info.Version,
+		},
+		Methods: make([]OpenRPCMethod, 0, len(tools)),
+	}
+	if info.ServerURL != "" {
+		doc.Servers = []OpenRPCServer{{URL: info.ServerURL}}
+	}
+
+	for _, tool := range tools {
+		method := OpenRPCMethod{
+			Name:           tool.Name,
+			Description:    tool.Description,
+			Params:         make([]OpenRPCContentDescriptor, 0, len(tool.Parameters)),
+			Result:         &OpenRPCContentDescriptor{Name: "result"},
+			ParamStructure: "by-name",
+		}
+		for _, param := range tool.Parameters {
+			schema := mcpProperty(param)
+			schema.Description = ""
+			method.Params = append(method.Params, OpenRPCContentDescriptor{
+				Name:        param.Name,
+				Description: param.Description,
+				Required:    param.Required,
+				Schema:      schema,
+			})
+		}
+		doc.Methods = append(doc.Methods, method)
+	}
+
+	return doc
+}
@@ -0,0 +1,130 @@
+package spec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testOpenRPCSpec = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "Petstore", "version": "1.0.0"},
+	"methods": [
+		{
+			"name": "list_pets",
+			"summary": "List all pets",
+			"params": [
+				{"name": "limit", "description": "How many items to return", "schema": {"type": "integer", "minimum": 1}},
+				{"$ref": "#/components/contentDescriptors/Species"}
+			],
+			"result": {"name": "pets", "schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}
+		},
+		{
+			"name": "get_pet",
+			"params": [
+				{"name": "id", "required": true, "schema": {"$ref": "#/components/schemas/PetID"}}
+			],
+			"result": {"name": "pet", "schema": {"$ref": "#/components/schemas/Pet"}}
+		}
+	],
+	"components": {
+		"contentDescriptors": {
+			"Species": {"name": "species", "summary": "Species to filter by", "schema": {"type": "string", "enum": ["cat", "dog"]}}
+		},
+		"schemas": {
+			"PetID": {"type": "integer", "description": "ID of a pet"},
+			"Pet": {"type": "object", "properties": {"id": {"$ref": "#/components/schemas/PetID"}}}
+		}
+	}
+}`
+
+func TestOpenRPCToToolDefinitions(t *testing.T) {
+	doc, err := ParseOpenRPCSpecFromBytes([]byte(testOpenRPCSpec))
+	if err != nil {
+		t.Fatalf("ParseOpenRPCSpecFromBytes failed: %v", err)
+	}
+
+	tools := doc.ToToolDefinitions()
+	if len(tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(tools))
+	}
+
+	list := tools[0]
+	if list.Name != "list_pets" || list.Description != "List all pets" {
+		t.Errorf("Unexpected list tool %+v", list)
+	}
+	if limit := findParam(list.Parameters, "limit"); limit.Type != "int" || limit.Required {
+		t.Errorf("Unexpected limit parameter %+v", limit)
+	}
+	species := findParam(list.Parameters, "species")
+	if species.Description != "Species to filter by" || !reflect.DeepEqual(species.Enum, []interface{}{"cat", "dog"}) {
+		t.Errorf("Expected species from the referenced content descriptor, got %+v", species)
+	}
+
+	get := tools[1]
+	if get.Description != "Calls the get_pet JSON-RPC method" {
+		t.Errorf("Expected a default description, got %q", get.Description)
+	}
+	want := []Parameter{{Name: "id", Type: "int", Description: "ID of a pet", Required: true}}
+	if !reflect.DeepEqual(get.Parameters, want) {
+		t.Errorf("Expected id from the referenced schema\n got: %+v\nwant: %+v", get.Parameters, want)
+	}
+
+	if _, err := ParseOpenRPCSpecFromBytes([]byte(`{"methods": []}`)); err == nil {
+		t.Error("Expected error for a document without an openrpc version")
+	}
+}
+
+func TestExportOpenRPCRoundTrip(t *testing.T) {
+	data, err := json.Marshal(ExportOpenRPC(ExportInfo{Name: "mail", Version: "1.0.0", ServerURL: "http://localhost:8080/rpc"}, exportTools))
+	if err != nil {
+		t.Fatalf("Failed to marshal document: %v", err)
+	}
+
+	if DetectSpecFormat(data) != FormatOpenRPC {
+		t.Fatal("Expected exported document to be detected as OpenRPC")
+	}
+	if report := Lint(data); report.HasErrors() || len(report.Diagnostics) > 0 {
+		t.Errorf("Expected exported document to lint clean, got:\n%s", report)
+	}
+
+	doc, err := ParseOpenRPCSpecFromBytes(data)
+	if err != nil {
+		t.Fatalf("Failed to parse exported document: %v", err)
+	}
+	if doc.Servers[0].URL != "http://localhost:8080/rpc" || doc.Methods[0].ParamStructure != "by-name" {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+	if tools := doc.ToToolDefinitions(); !reflect.DeepEqual(tools, exportTools) {
+		t.Errorf("Expected round trip to preserve tools\n got: %+v\nwant: %+v", tools, exportTools)
+	}
+}
+
+func TestLintOpenRPC(t *testing.T) {
+	report := Lint([]byte(`{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [
+			{"name": "add", "paramStructure": "by-position", "params": [{"name": "a", "schema": {}}]},
+			{"name": "sub", "summary": "Subtract", "params": [{"$ref": "#/components/contentDescriptors/Missing"}]}
+		]
+	}`))
+
+	if report.Format != FormatOpenRPC {
+		t.Errorf("Expected openrpc format, got %q", report.Format)
+	}
+	for _, want := range []struct {
+		severity Severity
+		code     string
+		path     string
+	}{
+		{SeverityWarning, "missing-description", "/methods/0"},
+		{SeverityError, "unsupported-param-structure", "/methods/0/paramStructure"},
+		{SeverityWarning, "untyped-property", "/methods/0/params/0/schema"},
+		{SeverityError, "unresolved-ref", "/methods/1/params/0/$ref"},
+	} {
+		if !hasDiagnostic(report, want.severity, want.code, want.path) {
+			t.Errorf("Expected %s diagnostic at %s, got:\n%s", want.code, want.path, report)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	// Register the well-known types so that imports of them resolve
+	_ "google.golang.org/protobuf/types/known/anypb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ProtobufSpec is a set of resolved protobuf files whose services become tools
+type ProtobufSpec struct {
+	Files []protoreflect.FileDescriptor
+}
+
+// GRPCMethod identifies the RPC a tool generated from a protobuf service invokes
+type GRPCMethod struct {
+	Service         string // fully qualified service name, e.g. "users.v1.UserService"
+	Method          string
+	ServerStreaming bool // responses are collected into a list
+}
+
+// FullMethod returns the method path used on the wire, e.g. "/users.v1.UserService/GetUser"
+func (m *GRPCMethod) FullMethod() string {
+	return "/" + m.Service + "/" + m.Method
+}
+
+// protoSourcePattern matches the declarations that identify a .proto file
+var protoSourcePattern = regexp.MustCompile(`(?m)^\s*(syntax\s*=\s*["']proto[23]["']|service\s+\w+\s*\{)`)
+
+// IsProtobufSpec reports whether data is a .proto file or a binary
+// FileDescriptorSet, as written by protoc --descriptor_set_out or buf build
+func IsProtobufSpec(data []byte) bool {
+	if protoSourcePattern.Match(data) {
+		return true
+	}
+	_, err := unmarshalDescriptorSet(data)
+	return err == nil
+}
+
+// unmarshalDescriptorSet decodes a binary FileDescriptorSet, rejecting data
+// that happens to decode but holds no named files
+func unmarshalDescriptorSet(data []byte) (*descriptorpb.FileDescriptorSet, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("failed to decode FileDescriptorSet: %w", err)
+	}
+	if len(set.File) == 0 {
+		return nil, fmt.Errorf("FileDescriptorSet contains no files")
+	}
+	for _, file := range set.File {
+		if file.GetName() == "" {
+			return nil, fmt.Errorf("FileDescriptorSet contains a file without a name")
+		}
+	}
+	return set, nil
+}
+
+// ParseProtobufSpec parses a FileDescriptorSet or a .proto file. Imports of a
+// .proto file are looked up relative to its directory and each of its
+// parents; the well-known types are built in.
+func ParseProtobufSpec(filePath string) (*ProtobufSpec, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read protobuf file: %w", err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return nil, err
+	}
+	return parseProtobuf(filepath.Base(filePath), data, protoFileLoader(dir))
+}
+
+// ParseProtobufSpecFromBytes parses a FileDescriptorSet or a .proto file that
+// imports nothing but the well-known types
+func ParseProtobufSpecFromBytes(data []byte) (*ProtobufSpec, error) {
+	return parseProtobuf("spec.proto", data, func(importPath string) ([]byte, error) {
+		return nil, fmt.Errorf("only the well-known types can be imported; use ParseProtobufSpec to resolve other imports")
+	})
+}
+
+// protoLoader reads an imported .proto file by its import path
+type protoLoader func(importPath string) ([]byte, error)
+
+// protoFileLoader resolves imports relative to dir or any of its parents,
+// which covers both sibling imports and imports rooted at the repository
+func protoFileLoader(dir string) protoLoader {
+	return func(importPath string) ([]byte, error) {
+		for d := dir; ; d = filepath.Dir(d) {
+			data, err := os.ReadFile(filepath.Join(d, filepath.FromSlash(importPath)))
+			if err == nil {
+				return data, nil
+			}
+			if filepath.Dir(d) == d {
+				return nil, fmt.Errorf("not found in %s or its parent directories", dir)
+			}
+		}
+	}
+}
+
+func parseProtobuf(name string, data []byte, load protoLoader) (*ProtobufSpec, error) {
+	if !protoSourcePattern.Match(data) {
+		if set, err := unmarshalDescriptorSet(data); err == nil {
+			return protobufSpecFromSet(set)
+		}
+	}
+	return parseProtoFiles(name, data, load)
+}
+
+// protobufSpecFromSet resolves the files of a descriptor set. Well-known
+// types missing from the set, as when protoc ran without --include_imports,
+// are taken from the linked-in registry.
+func protobufSpecFromSet(set *descriptorpb.FileDescriptorSet) (*ProtobufSpec, error) {
+	byName := make(map[string]*descriptorpb.FileDescriptorProto, len(set.File))
+	for _, file := range set.File {
+		byName[file.GetName()] = file
+	}
+
+	files := new(protoregistry.Files)
+	building := make(map[string]bool)
+	var build func(name string) (protoreflect.FileDescriptor, error)
+	build = func(name string) (protoreflect.FileDescriptor, error) {
+		if fd, err := files.FindFileByPath(name); err == nil {
+			return fd, nil
+		}
+
+		fdp, ok := byName[name]
+		if !ok {
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(name)
+			if err != nil {
+				return nil, fmt.Errorf("import %q is not in the FileDescriptorSet", name)
+			}
+			return fd, files.RegisterFile(fd)
+		}
+
+		if building[name] {
+			return nil, fmt.Errorf("import cycle through %q", name)
+		}
+		building[name] = true
+		for _, dep := range fdp.Dependency {
+			if _, err := build(dep); err != nil {
+				return nil, err
+			}
+		}
+
+		fd, err := protodesc.NewFile(fdp, files)
+		if err != nil {
+			return nil, fmt.Errorf("invalid protobuf file %s: %w", name, err)
+		}
+		return fd, files.RegisterFile(fd)
+	}
+
+	spec := &ProtobufSpec{}
+	for _, file := range set.File {
+		fd, err := build(file.GetName())
+		if err != nil {
+			return nil, err
+		}
+		spec.Files = append(spec.Files, fd)
+	}
+	return spec, nil
+}
+
+// parseProtoFiles parses a .proto file and, recursively, the files it imports
+func parseProtoFiles(name string, data []byte, load protoLoader) (*ProtobufSpec, error) {
+	files := new(protoregistry.Files)
+	building := make(map[string]bool)
+	var build func(name string, data []byte) (protoreflect.FileDescriptor, error)
+	build = func(name string, data []byte) (protoreflect.FileDescriptor, error) {
+		fdp, err := parseProtoSource(name, data)
+		if err != nil {
+			return nil, err
+		}
+
+		building[name] = true
+		for _, dep := range fdp.Dependency {
+			if _, err := files.FindFileByPath(dep); err == nil {
+				continue
+			}
+			if fd, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil && strings.HasPrefix(dep, "google/protobuf/") {
+				if err := files.RegisterFile(fd); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if building[dep] {
+				return nil, fmt.Errorf("import cycle through %q", dep)
+			}
+
+			depData, err := load(dep)
+			if err != nil {
+				return nil, fmt.Errorf("%s: cannot resolve import %q: %w", name, dep, err)
+			}
+			if _, err := build(dep, depData); err != nil {
+				return nil, err
+			}
+		}
+
+		fd, err := protodesc.NewFile(fdp, files)
+		if err != nil {
+			return nil, fmt.Errorf("invalid protobuf file %s: %w", name, err)
+		}
+		return fd, files.RegisterFile(fd)
+	}
+
+	fd, err := build(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return &ProtobufSpec{Files: []protoreflect.FileDescriptor{fd}}, nil
+}
+
+// ToToolDefinitions converts every unary and server-streaming RPC to a tool
+// definition whose parameters are the fields of the request message, named
+// as in the message's JSON form. Client-streaming RPCs have no single request
+// to build from arguments and are skipped, as are the services of google.*
+// packages included as dependencies.
+func (s *ProtobufSpec) ToToolDefinitions() []ToolDefinition {
+	var tools []ToolDefinition
+	seen := make(map[string]bool)
+
+	for _, file := range s.Files {
+		if strings.HasPrefix(string(file.Package()), "google.") {
+			continue
+		}
+
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			svc := services.Get(i)
+			methods := svc.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				if method.IsStreamingClient() {
+					continue
+				}
+
+				name := string(method.Name())
+				if seen[name] {
+					name = string(svc.Name()) + name
+				}
+				seen[name] = true
+
+				tools = append(tools, protoMethodTool(name, method))
+			}
+		}
+	}
+
+	return tools
+}
+
+// protoMethodTool converts one RPC to a tool definition
+func protoMethodTool(name string, method protoreflect.MethodDescriptor) ToolDefinition {
+	description := protoComments(method)
+	if description == "" {
+		description = fmt.Sprintf("Calls the %s RPC, returning %s", method.FullName(), method.Output().FullName())
+	}
+	if method.IsStreamingServer() {
+		description += " (streamed responses are returned as a list)"
+	}
+
+	return ToolDefinition{
+		Name:        name,
+		Description: description,
+		Parameters:  protoMessageParameters(method.Input(), map[protoreflect.FullName]bool{}),
+		GRPC: &GRPCMethod{
+			Service:         string(method.Parent().FullName()),
+			Method:          string(method.Name()),
+			ServerStreaming: method.IsStreamingServer(),
+		},
+	}
+}
+
+// protoComments returns the leading comment of a declaration, or else its
+// trailing comment, on a single line
+func protoComments(d protoreflect.Descriptor) string {
+	loc := d.ParentFile().SourceLocations().ByDescriptor(d)
+	text := loc.LeadingComments
+	if strings.TrimSpace(text) == "" {
+		text = loc.TrailingComments
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// protoMessageParameters converts the fields of a message to parameters
+func protoMessageParameters(msg protoreflect.MessageDescriptor, expanding map[protoreflect.FullName]bool) []Parameter {
+	fields := msg.Fields()
+	params := make([]Parameter, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		params = append(params, protoFieldParameter(fields.Get(i), expanding))
+	}
+	sortParameters(params)
+	return params
+}
+
+// protoFieldParameter converts a field to a parameter, expanding message
+// fields into nested properties
+func protoFieldParameter(field protoreflect.FieldDescriptor, expanding map[protoreflect.FullName]bool) Parameter {
+	p := Parameter{
+		Name:        field.JSONName(),
+		Description: protoComments(field),
+		Required:    field.Cardinality() == protoreflect.Required,
+	}
+
+	if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+		var names []string
+		for i := 0; i < oneof.Fields().Len(); i++ {
+			names = append(names, oneof.Fields().Get(i).JSONName())
+		}
+		p.Description = strings.TrimSpace(fmt.Sprintf("%s (set at most one of %s)", p.Description, strings.Join(names, ", ")))
+	}
+
+	if field.IsMap() {
+		p.Type = "map[string]interface{}"
+		return p
+	}
+
+	typ, format := protoValueType(field)
+	p.Format = format
+	if field.IsList() {
+		switch typ {
+		case "string", "int":
+			typ = "[]" + typ
+		default:
+			typ = "[]interface{}"
+		}
+	}
+	p.Type = typ
+
+	if field.HasDefault() {
+		p.Default = protoDefault(field)
+	}
+
+	switch {
+	case field.Kind() == protoreflect.EnumKind && !field.IsList():
+		values := field.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			p.Enum = append(p.Enum, string(values.Get(i).Name()))
+		}
+	case field.Message() != nil && typ != "interface{}":
+		msg := field.Message()
+		if _, wellKnown := protoWellKnownTypes[msg.FullName()]; wellKnown {
+			break
+		}
+		if expanding[msg.FullName()] || len(expanding) >= maxInputDepth {
+			break
+		}
+		expanding[msg.FullName()] = true
+		p.Properties = protoMessageParameters(msg, expanding)
+		delete(expanding, msg.FullName())
+	}
+
+	return p
+}
+
+// protoJSONType is the Go type name and JSON Schema format of a value
+type protoJSONType struct {
+	typ    string
+	format string
+}
+
+// protoWellKnownTypes maps the well-known messages to the types of their
+// special JSON forms
+var protoWellKnownTypes = map[protoreflect.FullName]protoJSONType{
+	"google.protobuf.Timestamp":   {"string", "date-time"},
+	"google.protobuf.Duration":    {"string", ""},
+	"google.protobuf.FieldMask":   {"string", ""},
+	"google.protobuf.Struct":      {"map[string]interface{}", ""},
+	"google.protobuf.Value":       {"interface{}", ""},
+	"google.protobuf.ListValue":   {"[]interface{}", ""},
+	"google.protobuf.Any":         {"map[string]interface{}", ""},
+	"google.protobuf.Empty":       {"map[string]interface{}", ""},
+	"google.protobuf.StringValue": {"string", ""},
+	"google.protobuf.BytesValue":  {"string", "byte"},
+	"google.protobuf.BoolValue":   {"bool", ""},
+	"google.protobuf.Int32Value":  {"int", ""},
+	"google.protobuf.UInt32Value": {"int", ""},
+	"google.protobuf.Int64Value":  {"int64", ""},
+	"google.protobuf.UInt64Value": {"int64", ""},
+	"google.protobuf.FloatValue":  {"float64", ""},
+	"google.protobuf.DoubleValue": {"float64", ""},
+}
+
+// protoValueType maps the type of a single field value to the Go type names
+// used by parameters. Bytes are base64 strings, as in the JSON form.
+func protoValueType(field protoreflect.FieldDescriptor) (typ, format string) {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return "bool", ""
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "int", ""
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "int64", ""
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return "float64", ""
+	case protoreflect.StringKind:
+		return "string", ""
+	case protoreflect.BytesKind:
+		return "string", "byte"
+	case protoreflect.EnumKind:
+		if field.Enum().FullName() == "google.protobuf.NullValue" {
+			return "interface{}", ""
+		}
+		return "string", ""
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if wellKnown, ok := protoWellKnownTypes[field.Message().FullName()]; ok {
+			return wellKnown.typ, wellKnown.format
+		}
+		return "map[string]interface{}", ""
+	}
+	return "interface{}", ""
+}
+
+// protoDefault returns a proto2 default value as a plain Go value
+func protoDefault(field protoreflect.FieldDescriptor) interface{} {
+	switch field.Kind() {
+	case protoreflect.EnumKind:
+		return string(field.DefaultEnumValue().Name())
+	case protoreflect.BytesKind:
+		return nil
+	}
+
+	switch v := field.Default().Interface().(type) {
+	case int32:
+		return int(v)
+	case uint32:
+		return int(v)
+	case int64:
+		return int(v)
+	case uint64:
+		return int(v)
+	case float32:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+// DescriptorSet serializes the files and everything they import, dependencies
+// first, as a FileDescriptorSet for generated code to embed. Comments are
+// left out since they are only needed for tool descriptions.
+func (s *ProtobufSpec) DescriptorSet() ([]byte, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+
+		file := protodesc.ToFileDescriptorProto(fd)
+		file.SourceCodeInfo = nil
+		set.File = append(set.File, file)
+	}
+	for _, fd := range s.Files {
+		add(fd)
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FileDescriptorSet: %w", err)
+	}
+	return data, nil
+}
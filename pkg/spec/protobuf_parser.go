@@ -0,0 +1,1002 @@
+package spec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// parseProtoSource parses a .proto file into an unresolved file descriptor.
+// Type references are left as written for protodesc to resolve against the
+// file's imports, and comments are recorded as source locations so they can
+// become tool descriptions. Options other than default and json_name,
+// extensions and reserved ranges are skipped.
+func parseProtoSource(name string, data []byte) (*descriptorpb.FileDescriptorProto, error) {
+	tokens, err := tokenizeProto(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	p := &protoParser{
+		tokens: tokens,
+		file:   &descriptorpb.FileDescriptorProto{Name: proto.String(name)},
+		info:   &descriptorpb.SourceCodeInfo{},
+	}
+	if err := p.parseFile(); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: line %d: %w", name, p.tok().line, err)
+	}
+
+	p.file.SourceCodeInfo = p.info
+	return p.file, nil
+}
+
+// protoToken is a token of a .proto file with the comments attached to it
+type protoToken struct {
+	kind     int // tokEOF, tokName, tokPunct, tokString or tokNumber
+	value    string
+	line     int // 1-based
+	col      int // 0-based
+	leading  string
+	trailing string
+}
+
+// protoComment is a // or /* */ comment and the lines it spans
+type protoComment struct {
+	text      string
+	startLine int
+	endLine   int
+}
+
+// tokenizeProto splits a .proto file into tokens, ending with tokEOF. A
+// comment on the same line after a token is that token's trailing comment,
+// and a block of comments directly above a token is its leading comment.
+func tokenizeProto(src string) ([]protoToken, error) {
+	var tokens []protoToken
+	var pending []protoComment
+	pos, line, lineStart := 0, 1, 0
+
+	emit := func(t protoToken) {
+		if len(pending) > 0 && len(tokens) > 0 && pending[0].startLine == tokens[len(tokens)-1].line {
+			tokens[len(tokens)-1].trailing = pending[0].text
+			pending = pending[1:]
+		}
+
+		// Only comments ending on consecutive lines directly above lead
+		first := len(pending)
+		for next := t.line - 1; first > 0 && pending[first-1].endLine == next; first-- {
+			next = pending[first-1].startLine - 1
+		}
+		var leading []string
+		for _, c := range pending[first:] {
+			leading = append(leading, c.text)
+		}
+		t.leading = strings.Join(leading, "\n")
+
+		pending = nil
+		tokens = append(tokens, t)
+	}
+
+	for pos < len(src) {
+		c := src[pos]
+		col := pos - lineStart
+
+		switch {
+		case c == '\n':
+			pos++
+			line++
+			lineStart = pos
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			pos++
+		case strings.HasPrefix(src[pos:], "\ufeff"):
+			pos += len("\ufeff")
+		case strings.HasPrefix(src[pos:], "//"):
+			end := strings.IndexByte(src[pos:], '\n')
+			if end < 0 {
+				end = len(src) - pos
+			}
+			text := strings.TrimPrefix(src[pos+2:pos+end], " ")
+			pending = append(pending, protoComment{text: strings.TrimRight(text, " \t\r"), startLine: line, endLine: line})
+			pos += end
+		case strings.HasPrefix(src[pos:], "/*"):
+			end := strings.Index(src[pos+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			raw := src[pos+2 : pos+2+end]
+			var lines []string
+			for _, ln := range strings.Split(raw, "\n") {
+				ln = strings.TrimSpace(ln)
+				ln = strings.TrimSpace(strings.TrimPrefix(ln, "*"))
+				lines = append(lines, ln)
+			}
+			comment := protoComment{text: strings.TrimSpace(strings.Join(lines, "\n")), startLine: line}
+			for _, ch := range raw {
+				if ch == '\n' {
+					line++
+				}
+			}
+			pos += end + 4
+			if nl := strings.LastIndexByte(src[:pos], '\n'); nl >= lineStart {
+				lineStart = nl + 1
+			}
+			comment.endLine = line
+			pending = append(pending, comment)
+		case c == '_' || isLetter(c):
+			start := pos
+			for pos < len(src) && (src[pos] == '_' || isLetter(src[pos]) || isDigit(src[pos])) {
+				pos++
+			}
+			emit(protoToken{kind: tokName, value: src[start:pos], line: line, col: col})
+		case isDigit(c) || (c == '.' && pos+1 < len(src) && isDigit(src[pos+1])):
+			start := pos
+			hex := strings.HasPrefix(src[pos:], "0x") || strings.HasPrefix(src[pos:], "0X")
+			for pos < len(src) {
+				ch := src[pos]
+				exponentSign := (ch == '+' || ch == '-') && !hex && (src[pos-1] == 'e' || src[pos-1] == 'E')
+				if !isLetter(ch) && !isDigit(ch) && ch != '.' && !exponentSign {
+					break
+				}
+				pos++
+			}
+			emit(protoToken{kind: tokNumber, value: src[start:pos], line: line, col: col})
+		case c == '"' || c == '\'':
+			value, n, err := unquoteProtoString(src[pos:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			emit(protoToken{kind: tokString, value: value, line: line, col: col})
+			pos += n
+		case strings.IndexByte("{}[]()<>;,=.-+:/", c) >= 0:
+			emit(protoToken{kind: tokPunct, value: string(c), line: line, col: col})
+			pos++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+
+	emit(protoToken{kind: tokEOF, line: line, col: pos - lineStart})
+	return tokens, nil
+}
+
+// unquoteProtoString decodes the quoted string at the start of s, returning
+// its value and the number of bytes it spans
+func unquoteProtoString(s string) (string, int, error) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return sb.String(), i + 1, nil
+		case c == '\n':
+			return "", 0, fmt.Errorf("unterminated string")
+		case c != '\\':
+			sb.WriteByte(c)
+		case i+1 >= len(s):
+			return "", 0, fmt.Errorf("unterminated string")
+		default:
+			i++
+			switch esc := s[i]; esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'a':
+				sb.WriteByte('\a')
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'v':
+				sb.WriteByte('\v')
+			case 'x', 'X':
+				end := i + 1
+				for end < len(s) && end < i+3 && strings.IndexByte("0123456789abcdefABCDEF", s[end]) >= 0 {
+					end++
+				}
+				n, err := strconv.ParseUint(s[i+1:end], 16, 8)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid hex escape")
+				}
+				sb.WriteByte(byte(n))
+				i = end - 1
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				end := i
+				for end < len(s) && end < i+3 && s[end] >= '0' && s[end] <= '7' {
+					end++
+				}
+				n, err := strconv.ParseUint(s[i:end], 8, 8)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid octal escape")
+				}
+				sb.WriteByte(byte(n))
+				i = end - 1
+			default:
+				sb.WriteByte(esc)
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// protoScalarTypes maps scalar type keywords to field types
+var protoScalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"double":   descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"float":    descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+	"int64":    descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint64":   descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"int32":    descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"fixed64":  descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+	"fixed32":  descriptorpb.FieldDescriptorProto_TYPE_FIXED32,
+	"bool":     descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"string":   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bytes":    descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+	"uint32":   descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"sfixed32": descriptorpb.FieldDescriptorProto_TYPE_SFIXED32,
+	"sfixed64": descriptorpb.FieldDescriptorProto_TYPE_SFIXED64,
+	"sint32":   descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+	"sint64":   descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+}
+
+// Field numbers of declarations in descriptor.proto, used in source paths
+const (
+	fileMessagePath   = 4
+	fileEnumPath      = 5
+	fileServicePath   = 6
+	messageFieldPath  = 2
+	messageNestedPath = 3
+	messageEnumPath   = 4
+	messageOneofPath  = 8
+	enumValuePath     = 2
+	serviceMethodPath = 2
+)
+
+// maxProtoNesting limits how deeply messages may be nested
+const maxProtoNesting = 64
+
+// protoParser builds a file descriptor from .proto tokens
+type protoParser struct {
+	tokens []protoToken
+	pos    int
+	file   *descriptorpb.FileDescriptorProto
+	info   *descriptorpb.SourceCodeInfo
+	proto3 bool
+	depth  int
+}
+
+func (p *protoParser) tok() protoToken {
+	return p.tokens[p.pos]
+}
+
+// peek returns the token after the current one
+func (p *protoParser) peek() protoToken {
+	if p.pos+1 < len(p.tokens) {
+		return p.tokens[p.pos+1]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *protoParser) advance() protoToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the current token is the given keyword or punctuator
+func (p *protoParser) is(value string) bool {
+	t := p.tok()
+	return (t.kind == tokName || t.kind == tokPunct) && t.value == value
+}
+
+func (p *protoParser) expect(value string) (protoToken, error) {
+	if !p.is(value) {
+		return protoToken{}, p.unexpected(fmt.Sprintf("%q", value))
+	}
+	return p.advance(), nil
+}
+
+func (p *protoParser) unexpected(want string) error {
+	t := p.tok()
+	if t.kind == tokEOF {
+		return fmt.Errorf("unexpected end of file, expected %s", want)
+	}
+	return fmt.Errorf("unexpected %q, expected %s", t.value, want)
+}
+
+// ident reads a single identifier
+func (p *protoParser) ident() (string, error) {
+	if p.tok().kind != tokName {
+		return "", p.unexpected("identifier")
+	}
+	return p.advance().value, nil
+}
+
+// fullIdent reads a dotted name, optionally fully qualified with a leading dot
+func (p *protoParser) fullIdent() (string, error) {
+	var sb strings.Builder
+	if p.is(".") {
+		sb.WriteString(p.advance().value)
+	}
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(name)
+		if !p.is(".") {
+			return sb.String(), nil
+		}
+		sb.WriteString(p.advance().value)
+	}
+}
+
+// stringValue reads a string literal, joining adjacent literals
+func (p *protoParser) stringValue() (string, error) {
+	if p.tok().kind != tokString {
+		return "", p.unexpected("string")
+	}
+	var sb strings.Builder
+	for p.tok().kind == tokString {
+		sb.WriteString(p.advance().value)
+	}
+	return sb.String(), nil
+}
+
+// intValue reads a possibly negative decimal, hex or octal integer
+func (p *protoParser) intValue() (int32, error) {
+	sign := ""
+	if p.is("-") {
+		sign = p.advance().value
+	}
+	if p.tok().kind != tokNumber {
+		return 0, p.unexpected("integer")
+	}
+	t := p.advance()
+	n, err := strconv.ParseInt(sign+t.value, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q", sign+t.value)
+	}
+	return int32(n), nil
+}
+
+// skipStatement skips a statement up to its semicolon, or a block up to its
+// closing brace
+func (p *protoParser) skipStatement() error {
+	depth := 0
+	for {
+		t := p.advance()
+		switch {
+		case t.kind == tokEOF:
+			return fmt.Errorf("unexpected end of file")
+		case t.kind != tokPunct:
+		case t.value == "{":
+			depth++
+		case t.value == "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+			if depth < 0 {
+				return fmt.Errorf("unexpected \"}\"")
+			}
+		case t.value == ";" && depth == 0:
+			return nil
+		}
+	}
+}
+
+// skipBracketed skips a [...] option list, including nested brackets and braces
+func (p *protoParser) skipBracketed() error {
+	depth := 0
+	for {
+		t := p.advance()
+		switch {
+		case t.kind == tokEOF:
+			return fmt.Errorf("unexpected end of file")
+		case t.kind != tokPunct:
+		case t.value == "[" || t.value == "{":
+			depth++
+		case t.value == "]" || t.value == "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// locate records the source location of a declaration, with the comments
+// leading its first token and trailing its last
+func (p *protoParser) locate(path []int32, start, end protoToken) {
+	loc := &descriptorpb.SourceCodeInfo_Location{
+		Path: append([]int32(nil), path...),
+		Span: []int32{int32(start.line - 1), int32(start.col), int32(end.col + len(end.value))},
+	}
+	if end.line != start.line {
+		loc.Span = []int32{int32(start.line - 1), int32(start.col), int32(end.line - 1), int32(end.col + len(end.value))}
+	}
+	if start.leading != "" {
+		loc.LeadingComments = proto.String(start.leading)
+	}
+	if end.trailing != "" {
+		loc.TrailingComments = proto.String(end.trailing)
+	}
+	p.info.Location = append(p.info.Location, loc)
+}
+
+// path appends elements to a source path without sharing its backing array
+func sourcePath(base []int32, elems ...int) []int32 {
+	out := append([]int32(nil), base...)
+	for _, e := range elems {
+		out = append(out, int32(e))
+	}
+	return out
+}
+
+func (p *protoParser) parseFile() error {
+	for p.tok().kind != tokEOF {
+		switch {
+		case p.is("syntax"):
+			p.advance()
+			if _, err := p.expect("="); err != nil {
+				return err
+			}
+			syntax, err := p.stringValue()
+			if err != nil {
+				return err
+			}
+			if syntax != "proto2" && syntax != "proto3" {
+				return fmt.Errorf("unsupported syntax %q", syntax)
+			}
+			p.proto3 = syntax == "proto3"
+			p.file.Syntax = proto.String(syntax)
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+
+		case p.is("edition"):
+			return fmt.Errorf("editions are not supported")
+
+		case p.is("package"):
+			p.advance()
+			pkg, err := p.fullIdent()
+			if err != nil {
+				return err
+			}
+			p.file.Package = proto.String(pkg)
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+
+		case p.is("import"):
+			p.advance()
+			index := int32(len(p.file.Dependency))
+			switch {
+			case p.is("public"):
+				p.advance()
+				p.file.PublicDependency = append(p.file.PublicDependency, index)
+			case p.is("weak"):
+				p.advance()
+				p.file.WeakDependency = append(p.file.WeakDependency, index)
+			}
+			dep, err := p.stringValue()
+			if err != nil {
+				return err
+			}
+			p.file.Dependency = append(p.file.Dependency, dep)
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+
+		case p.is("message"):
+			msg, err := p.parseMessage(sourcePath(nil, fileMessagePath, len(p.file.MessageType)))
+			if err != nil {
+				return err
+			}
+			p.file.MessageType = append(p.file.MessageType, msg)
+
+		case p.is("enum"):
+			enum, err := p.parseEnum(sourcePath(nil, fileEnumPath, len(p.file.EnumType)))
+			if err != nil {
+				return err
+			}
+			p.file.EnumType = append(p.file.EnumType, enum)
+
+		case p.is("service"):
+			svc, err := p.parseService(sourcePath(nil, fileServicePath, len(p.file.Service)))
+			if err != nil {
+				return err
+			}
+			p.file.Service = append(p.file.Service, svc)
+
+		case p.is("option"), p.is("extend"):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+
+		case p.is(";"):
+			p.advance()
+
+		default:
+			return p.unexpected("a top-level declaration")
+		}
+	}
+	return nil
+}
+
+func (p *protoParser) parseMessage(msgPath []int32) (*descriptorpb.DescriptorProto, error) {
+	if p.depth++; p.depth > maxProtoNesting {
+		return nil, fmt.Errorf("messages nested too deeply")
+	}
+	defer func() { p.depth-- }()
+
+	start := p.advance() // message
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	open, err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.is("}") {
+		switch {
+		case p.tok().kind == tokEOF:
+			return nil, p.unexpected(`"}"`)
+
+		case p.is("message"):
+			nested, err := p.parseMessage(sourcePath(msgPath, messageNestedPath, len(msg.NestedType)))
+			if err != nil {
+				return nil, err
+			}
+			msg.NestedType = append(msg.NestedType, nested)
+
+		case p.is("enum"):
+			enum, err := p.parseEnum(sourcePath(msgPath, messageEnumPath, len(msg.EnumType)))
+			if err != nil {
+				return nil, err
+			}
+			msg.EnumType = append(msg.EnumType, enum)
+
+		case p.is("oneof"):
+			if err := p.parseOneof(msg, msgPath); err != nil {
+				return nil, err
+			}
+
+		case p.is("map") && p.peek().value == "<":
+			if err := p.parseMapField(msg, msgPath); err != nil {
+				return nil, err
+			}
+
+		case p.is("option"), p.is("reserved"), p.is("extensions"), p.is("extend"):
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+
+		case p.is(";"):
+			p.advance()
+
+		default:
+			field, err := p.parseField(sourcePath(msgPath, messageFieldPath, len(msg.Field)))
+			if err != nil {
+				return nil, err
+			}
+			msg.Field = append(msg.Field, field)
+		}
+	}
+	p.advance() // }
+
+	// proto3 optional fields each get a synthetic oneof after the real ones
+	for _, field := range msg.Field {
+		if field.GetProto3Optional() {
+			field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+			msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + field.GetName())})
+		}
+	}
+
+	p.locate(msgPath, start, open)
+	return msg, nil
+}
+
+// parseField reads a field declaration: [label] type name = number [options];
+func (p *protoParser) parseField(fieldPath []int32) (*descriptorpb.FieldDescriptorProto, error) {
+	start := p.tok()
+	field := &descriptorpb.FieldDescriptorProto{Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()}
+
+	switch {
+	case p.is("repeated"):
+		p.advance()
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	case p.is("required"):
+		p.advance()
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum()
+	case p.is("optional"):
+		p.advance()
+		if p.proto3 {
+			field.Proto3Optional = proto.Bool(true)
+		}
+	}
+	if p.is("group") {
+		return nil, fmt.Errorf("groups are not supported")
+	}
+
+	typeName, err := p.fullIdent()
+	if err != nil {
+		return nil, err
+	}
+	setProtoFieldType(field, typeName)
+
+	if err := p.parseFieldTail(field); err != nil {
+		return nil, err
+	}
+	end, err := p.expect(";")
+	if err != nil {
+		return nil, err
+	}
+
+	p.locate(fieldPath, start, end)
+	return field, nil
+}
+
+// parseFieldTail reads the "name = number [options]" part of a field
+func (p *protoParser) parseFieldTail(field *descriptorpb.FieldDescriptorProto) error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	field.Name = proto.String(name)
+
+	if _, err := p.expect("="); err != nil {
+		return err
+	}
+	number, err := p.intValue()
+	if err != nil {
+		return err
+	}
+	field.Number = proto.Int32(number)
+
+	if p.is("[") {
+		return p.parseFieldOptions(field)
+	}
+	return nil
+}
+
+// parseFieldOptions reads a [name = value, ...] list, keeping default and
+// json_name
+func (p *protoParser) parseFieldOptions(field *descriptorpb.FieldDescriptorProto) error {
+	p.advance() // [
+	for {
+		var name strings.Builder
+		for !p.is("=") {
+			if t := p.tok(); t.kind == tokEOF || t.value == "]" {
+				return p.unexpected(`"="`)
+			}
+			name.WriteString(p.advance().value)
+		}
+		p.advance() // =
+
+		var value string
+		switch {
+		case p.is("{"):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case p.tok().kind == tokString:
+			value, _ = p.stringValue()
+		case p.is("-"):
+			p.advance()
+			value = "-" + p.advance().value
+		default:
+			value = p.advance().value
+		}
+
+		switch name.String() {
+		case "default":
+			field.DefaultValue = proto.String(value)
+		case "json_name":
+			field.JsonName = proto.String(value)
+		}
+
+		if p.is(",") {
+			p.advance()
+			continue
+		}
+		_, err := p.expect("]")
+		return err
+	}
+}
+
+// setProtoFieldType sets a scalar type, or the name of a message or enum
+// type that protodesc resolves later
+func setProtoFieldType(field *descriptorpb.FieldDescriptorProto, typeName string) {
+	if scalar, ok := protoScalarTypes[typeName]; ok {
+		field.Type = scalar.Enum()
+		return
+	}
+	field.TypeName = proto.String(typeName)
+}
+
+// parseMapField reads map<K, V> name = number; and adds the nested entry
+// message that maps are encoded as
+func (p *protoParser) parseMapField(msg *descriptorpb.DescriptorProto, msgPath []int32) error {
+	start := p.advance() // map
+	if _, err := p.expect("<"); err != nil {
+		return err
+	}
+	keyType, err := p.fullIdent()
+	if err != nil {
+		return err
+	}
+	if _, err := p.expect(","); err != nil {
+		return err
+	}
+	valueType, err := p.fullIdent()
+	if err != nil {
+		return err
+	}
+	if _, err := p.expect(">"); err != nil {
+		return err
+	}
+
+	field := &descriptorpb.FieldDescriptorProto{Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()}
+	if err := p.parseFieldTail(field); err != nil {
+		return err
+	}
+	end, err := p.expect(";")
+	if err != nil {
+		return err
+	}
+
+	key := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("key"),
+		JsonName: proto.String("key"),
+		Number:   proto.Int32(1),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	setProtoFieldType(key, keyType)
+	value := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("value"),
+		JsonName: proto.String("value"),
+		Number:   proto.Int32(2),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	setProtoFieldType(value, valueType)
+
+	entryName := mapEntryName(field.GetName())
+	msg.NestedType = append(msg.NestedType, &descriptorpb.DescriptorProto{
+		Name:    proto.String(entryName),
+		Field:   []*descriptorpb.FieldDescriptorProto{key, value},
+		Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+	})
+	field.TypeName = proto.String(entryName)
+
+	p.locate(sourcePath(msgPath, messageFieldPath, len(msg.Field)), start, end)
+	msg.Field = append(msg.Field, field)
+	return nil
+}
+
+// mapEntryName derives the entry message name protoc uses for a map field,
+// e.g. "user_labels" becomes "UserLabelsEntry"
+func mapEntryName(fieldName string) string {
+	var sb strings.Builder
+	upperNext := true
+	for _, c := range fieldName {
+		switch {
+		case c == '_':
+			upperNext = true
+		case upperNext:
+			sb.WriteRune(unicode.ToUpper(c))
+			upperNext = false
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	sb.WriteString("Entry")
+	return sb.String()
+}
+
+func (p *protoParser) parseOneof(msg *descriptorpb.DescriptorProto, msgPath []int32) error {
+	start := p.advance() // oneof
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	index := int32(len(msg.OneofDecl))
+	msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(name)})
+	open, err := p.expect("{")
+	if err != nil {
+		return err
+	}
+
+	for !p.is("}") {
+		switch {
+		case p.tok().kind == tokEOF:
+			return p.unexpected(`"}"`)
+		case p.is("option"):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case p.is(";"):
+			p.advance()
+		default:
+			field, err := p.parseField(sourcePath(msgPath, messageFieldPath, len(msg.Field)))
+			if err != nil {
+				return err
+			}
+			field.OneofIndex = proto.Int32(index)
+			msg.Field = append(msg.Field, field)
+		}
+	}
+	p.advance() // }
+
+	p.locate(sourcePath(msgPath, messageOneofPath, int(index)), start, open)
+	return nil
+}
+
+func (p *protoParser) parseEnum(enumPath []int32) (*descriptorpb.EnumDescriptorProto, error) {
+	start := p.advance() // enum
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	enum := &descriptorpb.EnumDescriptorProto{Name: proto.String(name)}
+	open, err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.is("}") {
+		switch {
+		case p.tok().kind == tokEOF:
+			return nil, p.unexpected(`"}"`)
+		case p.is("option"), p.is("reserved"):
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		case p.is(";"):
+			p.advance()
+		default:
+			valueStart := p.tok()
+			valueName, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect("="); err != nil {
+				return nil, err
+			}
+			number, err := p.intValue()
+			if err != nil {
+				return nil, err
+			}
+			if p.is("[") {
+				if err := p.skipBracketed(); err != nil {
+					return nil, err
+				}
+			}
+			end, err := p.expect(";")
+			if err != nil {
+				return nil, err
+			}
+
+			p.locate(sourcePath(enumPath, enumValuePath, len(enum.Value)), valueStart, end)
+			enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{
+				Name:   proto.String(valueName),
+				Number: proto.Int32(number),
+			})
+		}
+	}
+	p.advance() // }
+
+	p.locate(enumPath, start, open)
+	return enum, nil
+}
+
+func (p *protoParser) parseService(svcPath []int32) (*descriptorpb.ServiceDescriptorProto, error) {
+	start := p.advance() // service
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	svc := &descriptorpb.ServiceDescriptorProto{Name: proto.String(name)}
+	open, err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.is("}") {
+		switch {
+		case p.tok().kind == tokEOF:
+			return nil, p.unexpected(`"}"`)
+		case p.is("option"):
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		case p.is(";"):
+			p.advance()
+		case p.is("rpc"):
+			method, err := p.parseMethod(sourcePath(svcPath, serviceMethodPath, len(svc.Method)))
+			if err != nil {
+				return nil, err
+			}
+			svc.Method = append(svc.Method, method)
+		default:
+			return nil, p.unexpected(`"rpc"`)
+		}
+	}
+	p.advance() // }
+
+	p.locate(svcPath, start, open)
+	return svc, nil
+}
+
+// parseMethod reads rpc Name (stream? Input) returns (stream? Output) followed
+// by a semicolon or an options block
+func (p *protoParser) parseMethod(methodPath []int32) (*descriptorpb.MethodDescriptorProto, error) {
+	start := p.advance() // rpc
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	method := &descriptorpb.MethodDescriptorProto{Name: proto.String(name)}
+
+	messageType := func() (string, bool, error) {
+		if _, err := p.expect("("); err != nil {
+			return "", false, err
+		}
+		// "stream" is a keyword only when a type name follows it
+		streaming := p.is("stream") && (p.peek().kind == tokName || p.peek().value == ".")
+		if streaming {
+			p.advance()
+		}
+		typeName, err := p.fullIdent()
+		if err != nil {
+			return "", false, err
+		}
+		_, err = p.expect(")")
+		return typeName, streaming, err
+	}
+
+	input, clientStreaming, err := messageType()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	output, serverStreaming, err := messageType()
+	if err != nil {
+		return nil, err
+	}
+	method.InputType = proto.String(input)
+	method.OutputType = proto.String(output)
+	if clientStreaming {
+		method.ClientStreaming = proto.Bool(true)
+	}
+	if serverStreaming {
+		method.ServerStreaming = proto.Bool(true)
+	}
+
+	var end protoToken
+	if p.is("{") {
+		// Options block; the location ends at its opening brace
+		end = p.tok()
+		if err := p.skipStatement(); err != nil {
+			return nil, err
+		}
+	} else if end, err = p.expect(";"); err != nil {
+		return nil, err
+	}
+
+	p.locate(methodPath, start, end)
+	return method, nil
+}
@@ -0,0 +1,270 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const testProtoSource = `
+syntax = "proto3";
+
+package users.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/users/v1;usersv1";
+
+enum Role {
+  ROLE_UNSPECIFIED = 0;
+  ROLE_ADMIN = 1 [deprecated = true];
+}
+
+message User {
+  string id = 1;
+  string display_name = 2; // Name shown in the UI
+  Role role = 3;
+  google.protobuf.Timestamp created_at = 4;
+  map<string, string> labels = 5;
+  optional int64 quota = 6;
+  oneof contact {
+    string phone = 7;
+    string email = 8;
+  }
+  bytes avatar = 9;
+  reserved 10, 12 to 15;
+}
+
+message GetUserRequest {
+  // ID of the user
+  string id = 1;
+}
+
+message ListUsersRequest {
+  message Page {
+    int32 size = 1;
+    string token = 2;
+  }
+  Page page = 1;
+  repeated Role roles = 2;
+  repeated string ids = 3;
+}
+
+message ListUsersResponse { repeated User users = 1; }
+
+service UserService {
+  /*
+   * Fetch a single user
+   * by ID
+   */
+  rpc GetUser(GetUserRequest) returns (User);
+  rpc ListUsers(ListUsersRequest) returns (ListUsersResponse) {
+    option idempotency_level = NO_SIDE_EFFECTS;
+  }
+  // Stream users as they change
+  rpc WatchUsers(ListUsersRequest) returns (stream User);
+  rpc ImportUsers(stream User) returns (ListUsersResponse);
+}
+`
+
+func TestParseProtobufSpecFromBytes(t *testing.T) {
+	protoSpec, err := ParseProtobufSpecFromBytes([]byte(testProtoSource))
+	if err != nil {
+		t.Fatalf("ParseProtobufSpecFromBytes failed: %v", err)
+	}
+
+	tools := make(map[string]ToolDefinition)
+	for _, tool := range protoSpec.ToToolDefinitions() {
+		tools[tool.Name] = tool
+	}
+	if len(tools) != 3 {
+		t.Fatalf("Expected 3 tools without the client-streaming RPC, got %d", len(tools))
+	}
+
+	get := tools["GetUser"]
+	if get.Description != "Fetch a single user by ID" {
+		t.Errorf("Expected description from the block comment, got %q", get.Description)
+	}
+	want := &GRPCMethod{Service: "users.v1.UserService", Method: "GetUser"}
+	if !reflect.DeepEqual(get.GRPC, want) {
+		t.Errorf("Expected %+v, got %+v", want, get.GRPC)
+	}
+	if get.GRPC.FullMethod() != "/users.v1.UserService/GetUser" {
+		t.Errorf("Unexpected full method %q", get.GRPC.FullMethod())
+	}
+	if id := findParam(get.Parameters, "id"); id.Type != "string" || id.Description != "ID of the user" {
+		t.Errorf("Unexpected id parameter %+v", id)
+	}
+
+	list := tools["ListUsers"]
+	if !strings.Contains(list.Description, "users.v1.UserService.ListUsers") {
+		t.Errorf("Expected a default description naming the RPC, got %q", list.Description)
+	}
+	page := findParam(list.Parameters, "page")
+	if page.Type != "map[string]interface{}" || len(page.Properties) != 2 {
+		t.Fatalf("Expected page message with 2 properties, got %+v", page)
+	}
+	if size := findParam(page.Properties, "size"); size.Type != "int" {
+		t.Errorf("Expected int size, got %+v", size)
+	}
+	if roles := findParam(list.Parameters, "roles"); roles.Type != "[]string" || roles.Enum != nil {
+		t.Errorf("Expected []string roles without enum constraint, got %+v", roles)
+	}
+
+	if watch := tools["WatchUsers"]; !watch.GRPC.ServerStreaming {
+		t.Error("Expected WatchUsers to be server streaming")
+	}
+}
+
+func TestProtobufFieldParameters(t *testing.T) {
+	protoSpec, err := ParseProtobufSpecFromBytes([]byte(testProtoSource))
+	if err != nil {
+		t.Fatalf("ParseProtobufSpecFromBytes failed: %v", err)
+	}
+
+	user := protoSpec.Files[0].Messages().ByName("User")
+	params := protoMessageParameters(user, map[protoreflect.FullName]bool{})
+
+	tests := []struct {
+		name   string
+		typ    string
+		format string
+	}{
+		{"displayName", "string", ""},
+		{"role", "string", ""},
+		{"createdAt", "string", "date-time"},
+		{"labels", "map[string]interface{}", ""},
+		{"quota", "int64", ""},
+		{"avatar", "string", "byte"},
+	}
+	for _, tt := range tests {
+		p := findParam(params, tt.name)
+		if p.Type != tt.typ || p.Format != tt.format {
+			t.Errorf("%s: expected %s/%q, got %s/%q", tt.name, tt.typ, tt.format, p.Type, p.Format)
+		}
+	}
+
+	if got := findParam(params, "displayName").Description; got != "Name shown in the UI" {
+		t.Errorf("Expected trailing comment as description, got %q", got)
+	}
+	if got := findParam(params, "role").Enum; !reflect.DeepEqual(got, []interface{}{"ROLE_UNSPECIFIED", "ROLE_ADMIN"}) {
+		t.Errorf("Unexpected role enum %v", got)
+	}
+	if got := findParam(params, "phone").Description; !strings.Contains(got, "set at most one of phone, email") {
+		t.Errorf("Expected oneof note, got %q", got)
+	}
+}
+
+func TestProtobufDescriptorSetRoundTrip(t *testing.T) {
+	protoSpec, err := ParseProtobufSpecFromBytes([]byte(testProtoSource))
+	if err != nil {
+		t.Fatalf("ParseProtobufSpecFromBytes failed: %v", err)
+	}
+
+	set, err := protoSpec.DescriptorSet()
+	if err != nil {
+		t.Fatalf("DescriptorSet failed: %v", err)
+	}
+	if got := DetectSpecFormat(set); got != FormatProtobuf {
+		t.Errorf("Expected FileDescriptorSet to be detected as %q, got %q", FormatProtobuf, got)
+	}
+
+	parsed, err := ParseProtobufSpecFromBytes(set)
+	if err != nil {
+		t.Fatalf("Failed to parse FileDescriptorSet: %v", err)
+	}
+	// The set carries the imported timestamp.proto ahead of the file itself
+	if len(parsed.Files) != 2 {
+		t.Errorf("Expected 2 files, got %d", len(parsed.Files))
+	}
+	if got := len(parsed.ToToolDefinitions()); got != 3 {
+		t.Errorf("Expected 3 tools from the FileDescriptorSet, got %d", got)
+	}
+}
+
+func TestParseProtobufSpecImports(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"common/page.proto": `syntax = "proto3";
+package common;
+message Page { int32 size = 1; }`,
+		"api/search.proto": `syntax = "proto3";
+package api;
+import "common/page.proto";
+message SearchRequest { string query = 1; common.Page page = 2; }
+message SearchResponse { repeated string hits = 1; }
+service Search { rpc Search(SearchRequest) returns (SearchResponse); }`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	protoSpec, err := ParseProtobufSpec(filepath.Join(dir, "api", "search.proto"))
+	if err != nil {
+		t.Fatalf("ParseProtobufSpec failed: %v", err)
+	}
+
+	tools := protoSpec.ToToolDefinitions()
+	if len(tools) != 1 || tools[0].Name != "Search" {
+		t.Fatalf("Expected a single Search tool, got %+v", tools)
+	}
+	if page := findParam(tools[0].Parameters, "page"); len(page.Properties) != 1 {
+		t.Errorf("Expected imported Page message to be expanded, got %+v", page)
+	}
+
+	if _, err := ParseProtobufSpecFromBytes([]byte(files["api/search.proto"])); err == nil {
+		t.Error("Expected error for an import that cannot be resolved from bytes")
+	}
+}
+
+func TestParseProtobufSpecErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"syntax error", "syntax = \"proto3\";\nmessage User {\n  string id 1;\n}", "line 3"},
+		{"unknown type", "syntax = \"proto3\";\nmessage User { Missing m = 1; }", "Missing"},
+		{"groups", "syntax = \"proto2\";\nmessage User { optional group G = 1 {} }", "groups are not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseProtobufSpecFromBytes([]byte(tt.source))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLintProtobuf(t *testing.T) {
+	report := Lint([]byte(testProtoSource))
+
+	if report.Format != FormatProtobuf {
+		t.Errorf("Expected protobuf format, got %q", report.Format)
+	}
+	if !hasDiagnostic(report, SeverityWarning, "missing-description", "/UserService/ListUsers") {
+		t.Error("Expected missing-description diagnostic for ListUsers")
+	}
+	if hasDiagnostic(report, SeverityWarning, "missing-description", "/UserService/GetUser") {
+		t.Error("GetUser is documented")
+	}
+	if !hasDiagnostic(report, SeverityWarning, "unsupported-streaming", "/UserService/ImportUsers") {
+		t.Error("Expected unsupported-streaming diagnostic for ImportUsers")
+	}
+
+	report = Lint([]byte("syntax = \"proto3\";\nmessage User {"))
+	if !hasDiagnostic(report, SeverityError, "invalid-schema", "/") {
+		t.Error("Expected invalid-schema diagnostic")
+	}
+}
@@ -0,0 +1,179 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FromRegistry reads the tool definitions of a generated Registry (or any
+// value with a ListTools or List method returning tool structs). Generated
+// packages declare their own ToolInfo and ParamInfo types, so fields are read
+// by name: Name, Description, Namespace, Annotations and Parameters, whose
+// elements provide Name, Type, Required, Enum, Default, Format, Schema and
+// nested Properties. Missing optional fields are skipped.
+//
+// Two other shapes are accepted for hand-written registries: Parameters as a
+// map from parameter name to a type hint such as "number (pounds)", and an
+// Input field holding a zero value of a typed argument struct, which is read
+// with ParametersFromStruct when Parameters is empty. Tools are returned
+// sorted by name.
+func FromRegistry(registry interface{}) ([]ToolDefinition, error) {
+	value := reflect.ValueOf(registry)
+	method := value.MethodByName("ListTools")
+	methodName := "ListTools"
+	if !method.IsValid() {
+		method = value.MethodByName("List")
+		methodName = "List"
+	}
+	if !method.IsValid() {
+		return nil, fmt.Errorf("%T has no ListTools method", registry)
+	}
+	if method.Type().NumIn() != 0 || method.Type().NumOut() != 1 || method.Type().Out(0).Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%T.%s must take no arguments and return a slice", registry, methodName)
+	}
+
+	list := method.Call(nil)[0]
+	tools := make([]ToolDefinition, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		info := reflect.Indirect(list.Index(i))
+		if info.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%T.%s must return structs or pointers to structs", registry, methodName)
+		}
+
+		tool := ToolDefinition{
+			Name:        stringField(info, "Name"),
+			Description: stringField(info, "Description"),
+			Namespace:   stringField(info, "Namespace"),
+		}
+
+		params := info.FieldByName("Parameters")
+		switch {
+		case params.IsValid() && params.Kind() == reflect.Map:
+			tool.Parameters = mapParameters(params)
+		case params.IsValid() && params.Kind() == reflect.Slice:
+			tool.Parameters = sliceParameters(params)
+		}
+
+		if annotations := info.FieldByName("Annotations"); annotations.IsValid() && annotations.CanInterface() && !isNil(annotations) {
+			var err error
+			if tool.Annotations, err = registryAnnotations(annotations.Interface()); err != nil {
+				return nil, fmt.Errorf("tool %s: %w", tool.Name, err)
+			}
+		}
+
+		if input := info.FieldByName("Input"); len(tool.Parameters) == 0 && input.IsValid() && input.CanInterface() && !isNil(input) {
+			structParams, err := ParametersFromStruct(input.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("tool %s: %w", tool.Name, err)
+			}
+			tool.Parameters = structParams
+		}
+
+		tools = append(tools, tool)
+	}
+
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	return tools, nil
+}
+
+// sliceParameters reads parameters declared as ParamInfo structs, and the
+// Properties of object parameters declared the same way
+func sliceParameters(params reflect.Value) []Parameter {
+	var result []Parameter
+	for i := 0; i < params.Len(); i++ {
+		param := reflect.Indirect(params.Index(i))
+		if param.Kind() != reflect.Struct {
+			continue
+		}
+		p := Parameter{
+			Name:        stringField(param, "Name"),
+			Type:        stringField(param, "Type"),
+			Description: stringField(param, "Description"),
+			Format:      stringField(param, "Format"),
+		}
+		if required := param.FieldByName("Required"); required.IsValid() && required.Kind() == reflect.Bool {
+			p.Required = required.Bool()
+		}
+		if enum := param.FieldByName("Enum"); enum.IsValid() && enum.CanInterface() {
+			p.Enum, _ = enum.Interface().([]interface{})
+		}
+		if def := param.FieldByName("Default"); def.IsValid() && def.CanInterface() {
+			p.Default = def.Interface()
+		}
+		if schema := param.FieldByName("Schema"); schema.IsValid() && schema.CanInterface() {
+			keywords, _ := schema.Interface().(map[string]interface{})
+			p.Schema = copyKeywords(keywords)
+		}
+		if props := param.FieldByName("Properties"); props.IsValid() && props.Kind() == reflect.Slice {
+			p.Properties = sliceParameters(props)
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// registryAnnotations reads a tool's Annotations, declared as a map of MCP
+// annotation names or a struct with fields named after them
+func registryAnnotations(value interface{}) (*ToolAnnotations, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid annotations: %w", err)
+	}
+	var annotations ToolAnnotations
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil, fmt.Errorf("invalid annotations: %w", err)
+	}
+	return &annotations, nil
+}
+
+// mapParameters reads parameters declared as a map from name to type hint.
+// The first word of a string hint is the type ("array of {productId}",
+// "number (pounds)") and the whole hint becomes the description.
+func mapParameters(params reflect.Value) []Parameter {
+	var result []Parameter
+	for _, key := range params.MapKeys() {
+		if key.Kind() != reflect.String {
+			continue
+		}
+
+		p := Parameter{Name: key.String(), Type: "interface{}"}
+		if hint, ok := params.MapIndex(key).Interface().(string); ok {
+			p.Description = hint
+			if fields := strings.Fields(hint); len(fields) > 0 {
+				if jsonType, _ := jsonSchemaType(fields[0]); jsonType != "" {
+					p.Type = mapMCPTypeToGo(jsonType)
+				}
+			}
+		}
+		result = append(result, p)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// isNil reports whether a value is a nil interface, pointer, map or slice
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// stringField returns a string field by name, or "" if the struct has none
+func stringField(v reflect.Value, name string) string {
+	field := v.FieldByName(name)
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
@@ -0,0 +1,92 @@
+package spec
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testParamInfo struct {
+	Name       string
+	Type       string
+	Required   bool
+	Enum       []interface{}
+	Default    interface{}
+	Format     string
+	Properties []testParamInfo
+	Schema     map[string]interface{}
+}
+
+type testToolInfo struct {
+	Name        string
+	Description string
+	Parameters  []testParamInfo
+	Namespace   string
+	Annotations map[string]interface{}
+}
+
+type testRegistry struct{ tools []*testToolInfo }
+
+func (r *testRegistry) ListTools() []*testToolInfo { return r.tools }
+
+func TestFromRegistry(t *testing.T) {
+	registry := &testRegistry{tools: []*testToolInfo{
+		{Name: "sqlite.query", Namespace: "sqlite", Parameters: []testParamInfo{{Name: "sql", Type: "string", Required: true}}},
+		{Name: "github.listIssues", Namespace: "github", Description: "List issues", Parameters: []testParamInfo{
+			{Name: "state", Type: "string", Enum: []interface{}{"open", "closed"}, Default: "open"},
+		}},
+	}}
+
+	tools, err := FromRegistry(registry)
+	if err != nil {
+		t.Fatalf("Failed to read registry: %v", err)
+	}
+
+	if len(tools) != 2 || tools[0].Name != "github.listIssues" {
+		t.Fatalf("Expected tools sorted by name, got %v", tools)
+	}
+	state := tools[0].Parameters[0]
+	if state.Default != "open" || len(state.Enum) != 2 || tools[0].Namespace != "github" {
+		t.Errorf("Expected enum, default and namespace to be read, got %+v", tools[0])
+	}
+	if !tools[1].Parameters[0].Required {
+		t.Error("Expected sql to be required")
+	}
+}
+
+func TestFromRegistryRequiresListTools(t *testing.T) {
+	if _, err := FromRegistry(struct{}{}); err == nil {
+		t.Error("Expected an error for a value without ListTools")
+	}
+}
+
+func TestFromRegistryReadsSchemas(t *testing.T) {
+	registry := &testRegistry{tools: []*testToolInfo{{
+		Name: "schedule",
+		Parameters: []testParamInfo{
+			{Name: "at", Type: "string", Format: "date-time", Required: true},
+			{Name: "retry", Type: "map[string]interface{}", Properties: []testParamInfo{
+				{Name: "attempts", Type: "int", Required: true, Schema: map[string]interface{}{"minimum": float64(1)}},
+			}},
+		},
+		Annotations: map[string]interface{}{"title": "Schedule", "idempotentHint": true},
+	}}}
+
+	tools, err := FromRegistry(registry)
+	if err != nil {
+		t.Fatalf("Failed to read registry: %v", err)
+	}
+
+	want := []Parameter{
+		{Name: "at", Type: "string", Format: "date-time", Required: true},
+		{Name: "retry", Type: "map[string]interface{}", Properties: []Parameter{
+			{Name: "attempts", Type: "int", Required: true, Schema: map[string]interface{}{"minimum": float64(1)}},
+		}},
+	}
+	if !reflect.DeepEqual(tools[0].Parameters, want) {
+		t.Errorf("Expected format, schema and properties to be read\n got: %+v\nwant: %+v", tools[0].Parameters, want)
+	}
+	annotations := tools[0].Annotations
+	if annotations == nil || annotations.Title != "Schedule" || annotations.IdempotentHint == nil || !*annotations.IdempotentHint {
+		t.Errorf("Expected annotations to be read, got %+v", annotations)
+	}
+}
@@ -1,12 +1,36 @@
 package spec
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"sort"
+)
 
 // ToolDefinition represents a normalized tool definition from any spec format
 type ToolDefinition struct {
 	Name        string
 	Description string
 	Parameters  []Parameter
+	Output      []Parameter       // fields of the object the tool returns, when known
+	Annotations *ToolAnnotations  // hints about the tool's behaviour, when known
+	Namespace   string            // set when merged from several specs; Name then carries the "namespace." prefix
+	GraphQL     *GraphQLOperation // set for tools generated from a GraphQL schema
+	GRPC        *GRPCMethod       // set for tools generated from a protobuf service
+}
+
+// ToolAnnotations are the MCP hints about a tool's behaviour. Clients must
+// not rely on them for safety. Unset hints take the MCP defaults: not
+// read-only, destructive, not idempotent and open-world.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`    // does not modify its environment
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"` // may delete or overwrite data
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`  // repeating a call has no further effect
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`   // interacts with external entities
+}
+
+// hint returns a pointer to an annotation value
+func hint(v bool) *bool {
+	return &v
 }
 
 // Parameter represents a function parameter
@@ -17,19 +41,45 @@ type Parameter struct {
 	Required    bool
 	Default     interface{}
 	Enum        []interface{}
+	Format      string      // JSON Schema format hint, e.g. "email" or "date-time"
+	Properties  []Parameter // fields of object parameters, when known
+
+	// Schema holds further JSON Schema keywords, such as "minimum" or
+	// "pattern", passed through to exported schemas as given
+	Schema map[string]interface{}
+}
+
+// sortParameters orders parameters deterministically: required parameters
+// first, keeping the existing relative order within each group
+func sortParameters(params []Parameter) {
+	sort.SliceStable(params, func(i, j int) bool {
+		return params[i].Required && !params[j].Required
+	})
 }
 
 // SpecFormat represents the format of a specification file
 type SpecFormat string
 
 const (
-	FormatMCP     SpecFormat = "mcp"
-	FormatOpenAPI SpecFormat = "openapi"
-	FormatUnknown SpecFormat = "unknown"
+	FormatMCP       SpecFormat = "mcp"
+	FormatOpenAPI   SpecFormat = "openapi"
+	FormatGraphQL   SpecFormat = "graphql"
+	FormatProtobuf  SpecFormat = "protobuf"
+	FormatOpenAI    SpecFormat = "openai"    // OpenAI function-calling tools
+	FormatAnthropic SpecFormat = "anthropic" // Anthropic Messages API tools
+	FormatOpenRPC   SpecFormat = "openrpc"
+	FormatUnknown   SpecFormat = "unknown"
+	FormatMixed     SpecFormat = "mixed" // tools merged from specs of different formats
 )
 
 // DetectSpecFormat detects the format of a specification file
 func DetectSpecFormat(data []byte) SpecFormat {
+	// Function-calling tool lists also have a tools field, so they are told
+	// apart from MCP specs by the shape of their entries first
+	if format := detectFunctionFormat(data); format != FormatUnknown {
+		return format
+	}
+
 	// Try to detect MCP format
 	var mcpCheck struct {
 		Tools []interface{} `json:"tools"`
@@ -42,11 +92,25 @@ func DetectSpecFormat(data []byte) SpecFormat {
 	var openAPICheck struct {
 		OpenAPI string `json:"openapi"`
 		Swagger string `json:"swagger"`
+		OpenRPC string `json:"openrpc"`
 	}
 	if err := json.Unmarshal(data, &openAPICheck); err == nil {
 		if openAPICheck.OpenAPI != "" || openAPICheck.Swagger != "" {
 			return FormatOpenAPI
 		}
+		if openAPICheck.OpenRPC != "" {
+			return FormatOpenRPC
+		}
+	}
+
+	// GraphQL schemas are SDL text or introspection JSON
+	if IsGraphQLSpec(data) {
+		return FormatGraphQL
+	}
+
+	// Protobuf services are .proto files or binary FileDescriptorSets
+	if IsProtobufSpec(data) {
+		return FormatProtobuf
 	}
 
 	return FormatUnknown
@@ -0,0 +1,206 @@
+// Package toolsearch ranks tools against task text so prompts can include
+// only the most relevant tool docs instead of every tool in a large spec.
+package toolsearch
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// BM25 parameters: k1 controls term-frequency saturation and b controls how
+// strongly scores are normalised by document length
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// nameWeight is how many times tool-name terms are counted, since a match on
+// the name is a stronger signal than a match in the description
+const nameWeight = 3
+
+// stopWords are ignored when indexing and querying
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+	"it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "with": true,
+}
+
+// Result is a tool and its relevance score for a query
+type Result struct {
+	Tool  spec.ToolDefinition
+	Score float64
+}
+
+// Index is a BM25 index over tool names, descriptions and parameters
+type Index struct {
+	tools     []spec.ToolDefinition
+	termFreqs []map[string]int
+	docLens   []int
+	docFreq   map[string]int
+	avgDocLen float64
+}
+
+// NewIndex builds an index over the given tools. The order of tools is kept
+// and breaks ties between equally relevant tools.
+func NewIndex(tools []spec.ToolDefinition) *Index {
+	idx := &Index{
+		tools:     tools,
+		termFreqs: make([]map[string]int, len(tools)),
+		docLens:   make([]int, len(tools)),
+		docFreq:   make(map[string]int),
+	}
+
+	totalLen := 0
+	for i, tool := range tools {
+		freqs := make(map[string]int)
+		length := 0
+		add := func(text string, weight int) {
+			for _, term := range Tokenize(text) {
+				freqs[term] += weight
+				length += weight
+			}
+		}
+
+		add(tool.Name, nameWeight)
+		add(tool.Description, 1)
+		for _, param := range tool.Parameters {
+			add(param.Name, 1)
+			add(param.Description, 1)
+		}
+
+		for term := range freqs {
+			idx.docFreq[term]++
+		}
+		idx.termFreqs[i] = freqs
+		idx.docLens[i] = length
+		totalLen += length
+	}
+
+	if len(tools) > 0 {
+		idx.avgDocLen = float64(totalLen) / float64(len(tools))
+	}
+
+	return idx
+}
+
+// Len returns the number of indexed tools
+func (idx *Index) Len() int {
+	return len(idx.tools)
+}
+
+// Tools returns every indexed tool in its original order
+func (idx *Index) Tools() []spec.ToolDefinition {
+	return idx.tools
+}
+
+// Get returns an indexed tool by name
+func (idx *Index) Get(name string) (spec.ToolDefinition, bool) {
+	for _, tool := range idx.tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return spec.ToolDefinition{}, false
+}
+
+// Search returns up to k tools matching the query, most relevant first.
+// Tools that share no terms with the query are not returned. A k of zero or
+// less returns every match.
+func (idx *Index) Search(query string, k int) []Result {
+	terms := Tokenize(query)
+	n := float64(len(idx.tools))
+
+	var results []Result
+	for i, tool := range idx.tools {
+		score := 0.0
+		for _, term := range terms {
+			tf := float64(idx.termFreqs[i][term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(idx.docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(idx.docLens[i])/idx.avgDocLen
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+		if score > 0 {
+			results = append(results, Result{Tool: tool, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// Select returns the k tools most relevant to the task text
+func (idx *Index) Select(task string, k int) []spec.ToolDefinition {
+	results := idx.Search(task, k)
+	tools := make([]spec.ToolDefinition, len(results))
+	for i, result := range results {
+		tools[i] = result.Tool
+	}
+	return tools
+}
+
+// Tokenize splits text into lower-case search terms. Identifiers are split on
+// camelCase and separators, so "listIssues" and "list_issues" both yield
+// "list" and "issue"; stop words are dropped and plurals reduced.
+func Tokenize(text string) []string {
+	var terms []string
+	var word []rune
+
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		term := stem(strings.ToLower(string(word)))
+		word = word[:0]
+		if term != "" && !stopWords[term] {
+			terms = append(terms, term)
+		}
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// Split camelCase and acronym boundaries: "readFile", "HTTPServer"
+			if unicode.IsUpper(r) && len(word) > 0 {
+				prev := word[len(word)-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					flush()
+				}
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return terms
+}
+
+// stem reduces simple English plurals so "issues" matches "issue"
+func stem(term string) string {
+	switch {
+	case len(term) > 4 && strings.HasSuffix(term, "ies"):
+		return term[:len(term)-3] + "y"
+	case len(term) > 3 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss"):
+		return term[:len(term)-1]
+	default:
+		return term
+	}
+}
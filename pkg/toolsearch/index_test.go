@@ -0,0 +1,57 @@
+package toolsearch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func githubTools() []spec.ToolDefinition {
+	return []spec.ToolDefinition{
+		{Name: "create_repository", Description: "Create a new GitHub repository"},
+		{Name: "listIssues", Description: "List issues in a repository", Parameters: []spec.Parameter{{Name: "state", Description: "open or closed"}}},
+		{Name: "create_issue", Description: "Open a new issue in a repository"},
+		{Name: "search_code", Description: "Search source code across repositories"},
+		{Name: "get_pull_request", Description: "Get details of a pull request"},
+		{Name: "merge_pull_request", Description: "Merge a pull request"},
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := map[string][]string{
+		"listIssues":            {"list", "issue"},
+		"list_issues":           {"list", "issue"},
+		"HTTPServer":            {"http", "server"},
+		"Read the file, please": {"read", "file", "please"},
+		"github.get_repository": {"github", "get", "repository"},
+		"addresses and queries": {"addresse", "query"},
+	}
+
+	for text, want := range tests {
+		if got := Tokenize(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestSearchRanksRelevantTools(t *testing.T) {
+	idx := NewIndex(githubTools())
+
+	results := idx.Search("list every issue in the repository", 2)
+	if len(results) == 0 || results[0].Tool.Name != "listIssues" {
+		t.Fatalf("Expected listIssues to rank first, got %v", results)
+	}
+	if len(results) > 2 {
+		t.Errorf("Expected at most 2 results, got %d", len(results))
+	}
+
+	merge := idx.Select("merge the pull request", 1)
+	if len(merge) != 1 || merge[0].Name != "merge_pull_request" {
+		t.Errorf("Expected merge_pull_request, got %v", merge)
+	}
+
+	if none := idx.Search("weather forecast", 3); len(none) != 0 {
+		t.Errorf("Expected no matches, got %v", none)
+	}
+}
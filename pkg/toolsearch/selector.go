@@ -0,0 +1,157 @@
+package toolsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imran31415/godemode/pkg/codegen"
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+// ExpandToolName is the name of the meta-tool agents call to see tools that
+// were left out of their prompt
+const ExpandToolName = "expand_tools"
+
+// defaultExpandLimit caps how many tools one expand call documents
+const defaultExpandLimit = 10
+
+// Selector picks the tools to document in a prompt and serves the expand
+// escape hatch for everything else
+type Selector struct {
+	index *Index
+	// Docs controls how selected tools are rendered
+	Docs codegen.DocOptions
+}
+
+// NewSelector creates a selector over the given tools
+func NewSelector(tools []spec.ToolDefinition) *Selector {
+	return &Selector{
+		index: NewIndex(tools),
+		Docs:  codegen.DocOptions{Verbosity: codegen.VerbosityCompact},
+	}
+}
+
+// NewSelectorForRegistry creates a selector over a generated Registry
+func NewSelectorForRegistry(registry interface{}) (*Selector, error) {
+	tools, err := spec.FromRegistry(registry)
+	if err != nil {
+		return nil, err
+	}
+	return NewSelector(tools), nil
+}
+
+// Index returns the underlying search index
+func (s *Selector) Index() *Index {
+	return s.index
+}
+
+// Prompt renders docs for the k tools most relevant to the task, followed by
+// a note telling the agent how to find the tools that were left out
+func (s *Selector) Prompt(task string, k int) string {
+	selected := s.index.Select(task, k)
+
+	var sb strings.Builder
+	sb.WriteString(codegen.RenderAPIDocs(selected, s.Docs))
+
+	if hidden := s.index.Len() - len(selected); hidden > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d more tools are available. Call %s with a \"query\" describing what you need, or \"names\" listing tools, to see their docs.\n",
+			hidden, ExpandToolName))
+	}
+
+	return sb.String()
+}
+
+// ExpandTool describes the expand meta-tool, for registering alongside the
+// selected tools
+func (s *Selector) ExpandTool() spec.ToolDefinition {
+	return spec.ToolDefinition{
+		Name:        ExpandToolName,
+		Description: "Show documentation for tools not listed in the prompt, found by search query or exact names",
+		Parameters: []spec.Parameter{
+			{Name: "query", Type: "string", Description: "What the tools should do"},
+			{Name: "names", Type: "array", Description: "Exact tool names to document"},
+			{Name: "limit", Type: "integer", Description: "Maximum number of tools to return", Default: defaultExpandLimit},
+		},
+	}
+}
+
+// Expand implements the expand meta-tool. Its signature matches a generated
+// registry's ToolFunc, so it can be registered with the parameters of
+// ExpandTool:
+//
+//	registry.Register(&ToolInfo{
+//		Name: toolsearch.ExpandToolName,
+//		Parameters: []ParamInfo{
+//			{Name: "query", Type: "string"},
+//			{Name: "names", Type: "array"},
+//			{Name: "limit", Type: "integer"},
+//		},
+//		Function: selector.Expand,
+//	})
+func (s *Selector) Expand(args map[string]interface{}) (interface{}, error) {
+	limit := defaultExpandLimit
+	switch v := args["limit"].(type) {
+	case int:
+		limit = v
+	case float64:
+		limit = int(v)
+	}
+	if limit <= 0 {
+		limit = defaultExpandLimit
+	}
+
+	var tools []spec.ToolDefinition
+	seen := make(map[string]bool)
+
+	for _, name := range stringList(args["names"]) {
+		tool, ok := s.index.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown tool: %s", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			tools = append(tools, tool)
+		}
+	}
+
+	if query, _ := args["query"].(string); query != "" {
+		for _, tool := range s.index.Select(query, limit) {
+			if !seen[tool.Name] {
+				seen[tool.Name] = true
+				tools = append(tools, tool)
+			}
+		}
+	}
+
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("no tools matched; pass a different query or exact names")
+	}
+	if len(tools) > limit {
+		tools = tools[:limit]
+	}
+
+	return codegen.RenderAPIDocs(tools, codegen.DocOptions{Verbosity: codegen.VerbosityFull}), nil
+}
+
+// stringList converts a names argument to a slice of strings
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	case string:
+		if list == "" {
+			return nil
+		}
+		return strings.Split(list, ",")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package toolsearch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imran31415/godemode/pkg/spec"
+)
+
+func TestSelectorPromptListsTopTools(t *testing.T) {
+	s := NewSelector(githubTools())
+
+	prompt := s.Prompt("merge the pull request", 2)
+
+	if !strings.Contains(prompt, "merge_pull_request()") {
+		t.Errorf("Prompt should document the most relevant tool:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "create_repository") {
+		t.Errorf("Prompt should not document unrelated tools:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "4 more tools are available. Call expand_tools") {
+		t.Errorf("Prompt should explain how to expand:\n%s", prompt)
+	}
+}
+
+func TestSelectorExpand(t *testing.T) {
+	s := NewSelector(githubTools())
+
+	docs, err := s.Expand(map[string]interface{}{"names": []interface{}{"create_issue"}, "query": "search code"})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	text := docs.(string)
+	if !strings.HasPrefix(text, "create_issue()") || !strings.Contains(text, "search_code()") {
+		t.Errorf("Expand should document named tools first, then matches:\n%s", text)
+	}
+
+	if _, err := s.Expand(map[string]interface{}{"names": []interface{}{"missing"}}); err == nil {
+		t.Error("Expected an error for an unknown tool name")
+	}
+	if _, err := s.Expand(map[string]interface{}{}); err == nil {
+		t.Error("Expected an error when nothing matches")
+	}
+}
+
+func TestSelectorDocumentsParameters(t *testing.T) {
+	s := NewSelector([]spec.ToolDefinition{
+		{Name: "sendEmail", Description: "Send an email", Parameters: []spec.Parameter{
+			{Name: "to", Type: "string", Required: true},
+			{Name: "cc", Type: "string"},
+		}},
+		{Name: "readEmail", Description: "Read an email by ID", Parameters: []spec.Parameter{{Name: "id", Type: "string", Required: true}}},
+		{Name: "deleteEmail", Description: "Delete an email by ID"},
+	})
+
+	if got := s.Index().Select("send a message by email", 1); len(got) != 1 || got[0].Name != "sendEmail" {
+		t.Errorf("Expected sendEmail, got %v", got)
+	}
+
+	prompt := s.Prompt("send a message by email", 1)
+	if !strings.Contains(prompt, "sendEmail(to string, cc? string)") {
+		t.Errorf("Prompt should document the selected tool's parameters:\n%s", prompt)
+	}
+
+	docs, err := s.Expand(map[string]interface{}{"query": "email", "limit": float64(2)})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if n := strings.Count(docs.(string), "\n  e.g. "); n != 2 {
+		t.Errorf("Expected limit to cap the expanded tools at 2, got %d:\n%s", n, docs)
+	}
+}
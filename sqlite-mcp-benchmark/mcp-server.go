@@ -1,45 +1,17 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 
+	mcpserver "github.com/imran31415/godemode/pkg/mcp/server"
 	sqlitetools "github.com/imran31415/godemode/sqlite-mcp-benchmark/generated"
 )
 
 // MCP Server - Exposes SQLite tools via JSON-RPC protocol
 
-type JSONRPCRequest struct {
-	JSONRPC string                 `json:"jsonrpc"`
-	Method  string                 `json:"method"`
-	Params  map[string]interface{} `json:"params,omitempty"`
-	ID      interface{}            `json:"id"`
-}
-
-type JSONRPCResponse struct {
-	JSONRPC string      `json:"jsonrpc"`
-	Result  interface{} `json:"result,omitempty"`
-	Error   *RPCError   `json:"error,omitempty"`
-	ID      interface{} `json:"id"`
-}
-
-type RPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
-type ToolsList struct {
-	Tools []MCPTool `json:"tools"`
-}
-
-type MCPTool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
-}
-
 var registry *sqlitetools.Registry
 
 func main() {
@@ -59,7 +31,15 @@ func main() {
 
 	registry = sqlitetools.NewRegistry()
 
-	http.HandleFunc("/mcp", mcpHandler)
+	provider, err := mcpserver.FromRegistry(registry)
+	if err != nil {
+		log.Fatalf("Failed to read tools: %v", err)
+	}
+	http.Handle("/mcp", mcpserver.New(provider, mcpserver.Options{
+		Name:    "sqlite-mcp-server",
+		Version: "1.0.0",
+		Logger:  log.New(os.Stderr, "📨 ", log.LstdFlags),
+	}))
 
 	fmt.Printf("🚀 SQLite MCP Server starting on http://localhost%s/mcp\n", port)
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
@@ -196,135 +176,3 @@ func setupTestData() error {
 
 	return nil
 }
-
-func mcpHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	var req JSONRPCRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendError(w, -32700, "Parse error", req.ID)
-		return
-	}
-
-	log.Printf("📨 RPC Request: %s", req.Method)
-
-	var result interface{}
-	var err error
-
-	switch req.Method {
-	case "tools/list":
-		result = listTools()
-	case "tools/call":
-		result, err = callTool(req.Params)
-	default:
-		sendError(w, -32601, "Method not found", req.ID)
-		return
-	}
-
-	if err != nil {
-		sendError(w, -32000, err.Error(), req.ID)
-		return
-	}
-
-	sendResponse(w, result, req.ID)
-}
-
-func listTools() ToolsList {
-	toolsList := registry.ListTools()
-	mcpTools := make([]MCPTool, len(toolsList))
-
-	for i, tool := range toolsList {
-		properties := make(map[string]interface{})
-		required := []string{}
-
-		for _, param := range tool.Parameters {
-			propType := "string"
-			switch param.Type {
-			case "float64":
-				propType = "number"
-			case "map[string]interface{}":
-				propType = "object"
-			case "[]interface{}":
-				propType = "array"
-			}
-
-			properties[param.Name] = map[string]interface{}{
-				"type":        propType,
-				"description": param.Name,
-			}
-
-			if param.Required {
-				required = append(required, param.Name)
-			}
-		}
-
-		mcpTools[i] = MCPTool{
-			Name:        tool.Name,
-			Description: tool.Description,
-			InputSchema: map[string]interface{}{
-				"type":       "object",
-				"properties": properties,
-				"required":   required,
-			},
-		}
-	}
-
-	return ToolsList{Tools: mcpTools}
-}
-
-func callTool(params map[string]interface{}) (interface{}, error) {
-	toolName, ok := params["name"].(string)
-	if !ok {
-		return nil, fmt.Errorf("tool name required")
-	}
-
-	arguments, ok := params["arguments"].(map[string]interface{})
-	if !ok {
-		arguments = make(map[string]interface{})
-	}
-
-	log.Printf("   🔧 Executing tool: %s", toolName)
-	result, err := registry.Call(toolName, arguments)
-	if err != nil {
-		return nil, err
-	}
-
-	// Format result as MCP content
-	resultJSON, _ := json.Marshal(result)
-	return map[string]interface{}{
-		"content": []map[string]interface{}{
-			{
-				"type": "text",
-				"text": string(resultJSON),
-			},
-		},
-	}, nil
-}
-
-func sendResponse(w http.ResponseWriter, result interface{}, id interface{}) {
-	resp := JSONRPCResponse{
-		JSONRPC: "2.0",
-		Result:  result,
-		ID:      id,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
-}
-
-func sendError(w http.ResponseWriter, code int, message string, id interface{}) {
-	resp := JSONRPCResponse{
-		JSONRPC: "2.0",
-		Error: &RPCError{
-			Code:    code,
-			Message: message,
-		},
-		ID: id,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
-}
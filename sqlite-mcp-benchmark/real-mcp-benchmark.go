@@ -223,6 +223,7 @@ func callMCPTool(name string, args map[string]interface{}, id int) (string, erro
 				Type string `json:"type"`
 				Text string `json:"text"`
 			} `json:"content"`
+			IsError bool `json:"isError"`
 		} `json:"result"`
 		Error *struct {
 			Message string `json:"message"`
@@ -237,6 +238,10 @@ func callMCPTool(name string, args map[string]interface{}, id int) (string, erro
 		return "", fmt.Errorf("MCP error: %s", mcpResp.Error.Message)
 	}
 
+	if mcpResp.Result.IsError && len(mcpResp.Result.Content) > 0 {
+		return "", fmt.Errorf("MCP tool error: %s", mcpResp.Result.Content[0].Text)
+	}
+
 	if len(mcpResp.Result.Content) > 0 {
 		return mcpResp.Result.Content[0].Text, nil
 	}